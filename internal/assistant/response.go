@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -14,47 +15,138 @@ type Response struct {
 }
 
 // Parse decodes the model output into the expected JSON response shape.
-// It accepts either a raw JSON object or a larger string containing
-// the first valid JSON object fragment.
+// It accepts a raw JSON value or a larger string containing one. Besides a
+// top-level {answer, command} object it recognizes the object inside an array
+// (such as a list of message objects), nested one level under another key, or
+// encoded as a JSON string in such a field.
 func Parse(text string) (Response, error) {
 	candidate := strings.TrimSpace(text)
 	if candidate == "" {
 		return Response{}, errors.New("empty model response")
 	}
 
-	var parsed Response
-	if json.Unmarshal([]byte(candidate), &parsed) == nil {
-		parsed.normalize()
-		return parsed, nil
+	var value any
+	if json.Unmarshal([]byte(candidate), &value) == nil {
+		return responseFromValue(value)
 	}
 
+	// Prose around the JSON: try the first array, then the first object.
+	if fragment, ok := firstJSONArray(candidate); ok && json.Unmarshal([]byte(fragment), &value) == nil {
+		if parsed, err := responseFromValue(value); err == nil {
+			return parsed, nil
+		}
+	}
 	fragment, ok := firstJSONObject(candidate)
 	if !ok {
 		return Response{}, fmt.Errorf("model response is not valid JSON")
 	}
-	if err := json.Unmarshal([]byte(fragment), &parsed); err != nil {
+	if err := json.Unmarshal([]byte(fragment), &value); err != nil {
 		return Response{}, fmt.Errorf("decode model JSON response: %w", err)
 	}
+	return responseFromValue(value)
+}
+
+func responseFromValue(value any) (Response, error) {
+	parsed, ok := responseFrom(value, maxNestedDepth)
+	if !ok {
+		return Response{}, fmt.Errorf("model JSON has no answer or command field")
+	}
 	parsed.normalize()
 	return parsed, nil
 }
 
+// maxNestedDepth is how many object or string levels responseFrom descends
+// below the top-level value. Array elements do not count as a level.
+const maxNestedDepth = 1
+
+// responseFrom finds the first value shaped like Response in v. Objects
+// with an answer or command field match directly; otherwise their fields are
+// searched in key order while depth allows.
+func responseFrom(v any, depth int) (Response, bool) {
+	switch value := v.(type) {
+	case map[string]any:
+		_, hasAnswer := value["answer"]
+		_, hasCommand := value["command"]
+		if hasAnswer || hasCommand {
+			answer, _ := value["answer"].(string)
+			command, _ := value["command"].(string)
+			return Response{Answer: answer, Command: command}, true
+		}
+		if depth <= 0 {
+			return Response{}, false
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if r, ok := responseFrom(value[key], depth-1); ok {
+				return r, true
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if r, ok := responseFrom(item, depth); ok {
+				return r, true
+			}
+		}
+	case string:
+		trimmed := strings.TrimSpace(value)
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			return Response{}, false
+		}
+		var inner any
+		if json.Unmarshal([]byte(trimmed), &inner) == nil {
+			return responseFrom(inner, depth)
+		}
+	}
+	return Response{}, false
+}
+
 func (r *Response) normalize() {
 	r.Answer = strings.TrimSpace(r.Answer)
 	r.Command = strings.TrimSpace(r.Command)
 }
 
+// Schema returns the JSON Schema for Response, suitable for providers that
+// enforce structured output. Both keys are required; command may be empty.
+func Schema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"answer":  map[string]any{"type": "string"},
+			"command": map[string]any{"type": "string"},
+		},
+		"required":             []string{"answer", "command"},
+		"additionalProperties": false,
+	}
+}
+
 // HasCommand reports whether the response includes a runnable command.
 func (r Response) HasCommand() bool {
 	return strings.TrimSpace(r.Command) != ""
 }
 
+func firstJSONArray(s string) (string, bool) {
+	start := strings.IndexRune(s, '[')
+	if start == -1 {
+		return "", false
+	}
+	return balancedFragment(s, start, '[', ']')
+}
+
 func firstJSONObject(s string) (string, bool) {
 	start := strings.IndexRune(s, '{')
 	if start == -1 {
 		return "", false
 	}
+	return balancedFragment(s, start, '{', '}')
+}
 
+// balancedFragment returns s from start through the close bracket matching
+// the open bracket at start, ignoring brackets inside JSON strings.
+func balancedFragment(s string, start int, open, close byte) (string, bool) {
 	depth := 0
 	inString := false
 	escaped := false
@@ -78,9 +170,9 @@ func firstJSONObject(s string) (string, bool) {
 		switch ch {
 		case '"':
 			inString = true
-		case '{':
+		case open:
 			depth++
-		case '}':
+		case close:
 			depth--
 			if depth == 0 {
 				return s[start : i+1], true
@@ -93,18 +185,60 @@ func firstJSONObject(s string) (string, bool) {
 
 // BuildPrompt returns the system prompt used for provider calls.
 // It enforces a strict JSON output contract and includes terminal context.
-func BuildPrompt(shell string, cwd string, osName string, allowMarkdown bool) string {
+func BuildPrompt(shell string, cwd string, osName string, allowMarkdown, allowFences bool) string {
+	return fmt.Sprintf("You are a terminal assistant. %s\nEnvironment: os=%s, shell=%s, cwd=%s", responseContract(allowMarkdown, allowFences), osName, shell, cwd)
+}
+
+// BuildCustomPrompt returns system in place of the built-in instructions,
+// followed by the same JSON output contract and terminal context as
+// BuildPrompt so responses still parse.
+func BuildCustomPrompt(system string, shell string, cwd string, osName string, allowMarkdown, allowFences bool) string {
+	return fmt.Sprintf("%s\n\n%s\nEnvironment: os=%s, shell=%s, cwd=%s", strings.TrimSpace(system), responseContract(allowMarkdown, allowFences), osName, shell, cwd)
+}
+
+// BuildRawPrompt returns the system prompt for raw output: system, or the
+// built-in role when it is empty, and the terminal context, without the JSON
+// output contract.
+func BuildRawPrompt(system string, shell string, cwd string, osName string) string {
+	system = strings.TrimSpace(system)
+	if system == "" {
+		system = "You are a terminal assistant."
+	}
+	return fmt.Sprintf("%s\nEnvironment: os=%s, shell=%s, cwd=%s", system, osName, shell, cwd)
+}
+
+// GitContext summarizes the state of the git repository containing the
+// working directory.
+type GitContext struct {
+	Branch    string
+	Staged    int
+	Modified  int
+	Untracked int
+}
+
+// WithGitContext appends a git line after the environment line of prompt.
+func WithGitContext(prompt string, git GitContext) string {
+	return fmt.Sprintf("%s\nGit: branch=%s, staged=%d, modified=%d, untracked=%d", prompt, git.Branch, git.Staged, git.Modified, git.Untracked)
+}
+
+// responseContract describes the {answer, command} JSON response format.
+// allowFences permits fenced code blocks in Markdown answers; it has no
+// effect on plain-text answers.
+func responseContract(allowMarkdown, allowFences bool) string {
 	formatInstruction := "In the answer field, use plain text only (no markdown formatting, headings, bullet markers, or code fences). "
 	if allowMarkdown {
+		fences := "Do not use markdown code fences. "
+		if allowFences {
+			fences = "Put multi-line code in fenced code blocks tagged with the language. "
+		}
 		formatInstruction = "In the answer field, use clean Markdown by default (short headings, concise bullet lists, and inline code where helpful). " +
-			"Keep formatting readable and minimal. Do not use markdown code fences. "
+			"Keep formatting readable and minimal. " + fences
 	}
 
-	instructions := "You are a terminal assistant. Return only strict JSON with exactly these keys: answer, command. " +
+	return "Return only strict JSON with exactly these keys: answer, command. " +
 		"If the user asks for a terminal command, set command to one runnable command and include concise explanation in answer unless specified otherwise. " +
 		"If no command is needed, set command to an empty string. " +
+		"Context snippets and files attached by the user come before the question as fenced blocks labeled with the snippet number or file name, and the question follows under \"Question:\"; use them as context. " +
 		formatInstruction +
 		"Do not include any text outside JSON."
-
-	return fmt.Sprintf("%s\nEnvironment: os=%s, shell=%s, cwd=%s", instructions, osName, shell, cwd)
 }
@@ -41,8 +41,46 @@ func TestParseInvalid(t *testing.T) {
 	}
 }
 
+func TestParseRecoversWrappedResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Response
+	}{
+		{"array of objects", `[{"answer":"List files.","command":"ls"}]`, Response{Answer: "List files.", Command: "ls"}},
+		{
+			"array of messages with JSON content",
+			`[{"role":"user","content":"list files"},{"role":"assistant","content":"{\"answer\":\"List files.\",\"command\":\"ls\"}"}]`,
+			Response{Answer: "List files.", Command: "ls"},
+		},
+		{"nested under a key", `{"result":{"answer":"Disk usage.","command":"df -h"}}`, Response{Answer: "Disk usage.", Command: "df -h"}},
+		{"nested JSON string", `{"output":"{\"answer\":\"hi\",\"command\":\"\"}"}`, Response{Answer: "hi"}},
+		{"array embedded in prose", "Here you go: [{\"answer\":\"ok\",\"command\":\"pwd\"}] done", Response{Answer: "ok", Command: "pwd"}},
+		{"prose bracket before object", "Step [1]: {\"answer\":\"ok\",\"command\":\"\"}", Response{Answer: "ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsJSONWithoutResponseShape(t *testing.T) {
+	for _, in := range []string{`{"data":{"result":{"answer":"too deep"}}}`, `[1, 2, 3]`, `{"text":"hello"}`} {
+		if _, err := Parse(in); err == nil {
+			t.Fatalf("Parse(%s) should fail", in)
+		}
+	}
+}
+
 func TestBuildPromptMarkdownEnabled(t *testing.T) {
-	prompt := BuildPrompt("zsh", "/tmp/project", "darwin", true)
+	prompt := BuildPrompt("zsh", "/tmp/project", "darwin", true, false)
 	if !strings.Contains(prompt, "use clean Markdown by default") {
 		t.Fatalf("prompt missing markdown-default instruction: %q", prompt)
 	}
@@ -51,9 +89,51 @@ func TestBuildPromptMarkdownEnabled(t *testing.T) {
 	}
 }
 
+func TestBuildCustomPromptKeepsContract(t *testing.T) {
+	prompt := BuildCustomPrompt("  You are a SQL expert.  ", "zsh", "/tmp/project", "darwin", false, false)
+	if !strings.HasPrefix(prompt, "You are a SQL expert.\n") {
+		t.Fatalf("prompt should start with the custom text: %q", prompt)
+	}
+	if strings.Contains(prompt, "terminal assistant") {
+		t.Fatalf("custom prompt should replace the built-in instructions: %q", prompt)
+	}
+	for _, want := range []string{"keys: answer, command", "plain text only", "Environment: os=darwin, shell=zsh, cwd=/tmp/project"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("prompt missing %q: %q", want, prompt)
+		}
+	}
+}
+
+func TestBuildPromptDescribesAttachmentOrder(t *testing.T) {
+	prompt := BuildPrompt("zsh", "/tmp/project", "darwin", false, false)
+	if !strings.Contains(prompt, "come before the question") || strings.Contains(prompt, "follow the question") {
+		t.Fatalf("prompt should say attachments precede the question: %q", prompt)
+	}
+}
+
+func TestBuildRawPromptOmitsContract(t *testing.T) {
+	prompt := BuildRawPrompt("", "zsh", "/tmp/project", "darwin")
+	if prompt != "You are a terminal assistant.\nEnvironment: os=darwin, shell=zsh, cwd=/tmp/project" {
+		t.Fatalf("prompt = %q", prompt)
+	}
+	if prompt := BuildRawPrompt("You are a SQL expert.", "zsh", "/tmp", "linux"); strings.Contains(prompt, "JSON") || !strings.HasPrefix(prompt, "You are a SQL expert.\n") {
+		t.Fatalf("custom raw prompt = %q", prompt)
+	}
+}
+
 func TestBuildPromptMarkdownDisabled(t *testing.T) {
-	prompt := BuildPrompt("zsh", "/tmp/project", "darwin", false)
+	prompt := BuildPrompt("zsh", "/tmp/project", "darwin", false, true)
 	if !strings.Contains(prompt, "plain text only") {
 		t.Fatalf("prompt missing plain-text instruction: %q", prompt)
 	}
 }
+
+func TestBuildPromptAllowsCodeFences(t *testing.T) {
+	prompt := BuildPrompt("zsh", "/tmp/project", "darwin", true, true)
+	if strings.Contains(prompt, "Do not use markdown code fences") {
+		t.Fatalf("prompt still forbids code fences: %q", prompt)
+	}
+	if !strings.Contains(prompt, "fenced code blocks tagged with the language") {
+		t.Fatalf("prompt missing code fence instruction: %q", prompt)
+	}
+}
@@ -0,0 +1,9 @@
+// Package buildinfo holds the release version shared by the CLI and the
+// provider clients.
+package buildinfo
+
+// Version is the ask release version.
+const Version = "0.2.2"
+
+// UserAgent is the User-Agent header sent to providers by default.
+const UserAgent = "ask/" + Version
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+)
+
+const (
+	dirName       = "answers"
+	parentDirName = "cache"
+	fileExtension = ".json"
+)
+
+type entry struct {
+	StoredAt time.Time          `json:"stored_at"`
+	Response assistant.Response `json:"response"`
+}
+
+// DirForConfig returns the answer cache directory next to the given config
+// path.
+func DirForConfig(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), parentDirName, dirName)
+}
+
+// Key hashes everything that shapes an answer into a cache key. settings
+// encodes the sampling options, such as temperature, sent with the request.
+// Each part is length-prefixed so shifting text between parts changes the key.
+func Key(provider, model, systemPrompt, question, settings string) string {
+	h := sha256.New()
+	for _, part := range []string{strings.ToLower(strings.TrimSpace(provider)), strings.TrimSpace(model), systemPrompt, question, settings} {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the answer stored under key when it was stored within ttl. The
+// boolean is false when the entry is missing, stale, or unreadable.
+func Get(dir, key string, ttl time.Duration) (assistant.Response, bool) {
+	path, err := entryPath(dir, key)
+	if err != nil || ttl <= 0 {
+		return assistant.Response{}, false
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return assistant.Response{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return assistant.Response{}, false
+	}
+	if e.StoredAt.IsZero() || time.Since(e.StoredAt) > ttl {
+		return assistant.Response{}, false
+	}
+	return e.Response, true
+}
+
+// Put stores resp under key in dir using owner-only permissions.
+func Put(dir, key string, resp assistant.Response) error {
+	path, err := entryPath(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create answer cache directory: %w", err)
+	}
+	encoded, err := json.MarshalIndent(entry{StoredAt: time.Now().UTC(), Response: resp}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cached answer: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
+		return fmt.Errorf("write temp cached answer: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace cached answer: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached answer in dir and reports how many were
+// removed. A missing directory is not an error.
+func Clear(dir string) (int, error) {
+	if strings.TrimSpace(dir) == "" {
+		return 0, fmt.Errorf("answer cache directory is not set")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read answer cache directory: %w", err)
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExtension) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return removed, fmt.Errorf("remove cached answer: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func entryPath(dir, key string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("answer cache directory is not set")
+	}
+	if _, err := hex.DecodeString(key); err != nil || key == "" {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	return filepath.Join(dir, key+fileExtension), nil
+}
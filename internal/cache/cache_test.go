@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+)
+
+func TestPutGetClear(t *testing.T) {
+	dir := DirForConfig(filepath.Join(t.TempDir(), "config.json"))
+	key := Key("openai", "gpt-4o-mini", "system", "list files", "")
+	resp := assistant.Response{Answer: "List files.", Command: "ls -la"}
+
+	if _, ok := Get(dir, key, time.Hour); ok {
+		t.Fatal("Get should miss before Put")
+	}
+	if err := Put(dir, key, resp); err != nil {
+		t.Fatalf("Put error = %v", err)
+	}
+	got, ok := Get(dir, key, time.Hour)
+	if !ok || got != resp {
+		t.Fatalf("Get = %+v, %v; want %+v", got, ok, resp)
+	}
+	if _, ok := Get(dir, key, 0); ok {
+		t.Fatal("a zero TTL should disable the cache")
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, key+".json"))
+		if err != nil {
+			t.Fatalf("stat cache file: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Fatalf("cache file mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+
+	removed, err := Clear(dir)
+	if err != nil || removed != 1 {
+		t.Fatalf("Clear = %d, %v", removed, err)
+	}
+	if _, ok := Get(dir, key, time.Hour); ok {
+		t.Fatal("Get should miss after Clear")
+	}
+	if removed, err := Clear(filepath.Join(t.TempDir(), "missing")); err != nil || removed != 0 {
+		t.Fatalf("Clear missing dir = %d, %v", removed, err)
+	}
+}
+
+func TestGetIgnoresStaleEntries(t *testing.T) {
+	dir := t.TempDir()
+	key := Key("p", "m", "s", "q", "")
+	stale := `{"stored_at":"2001-01-01T00:00:00Z","response":{"answer":"old","command":""}}`
+	if err := os.WriteFile(filepath.Join(dir, key+".json"), []byte(stale), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Get(dir, key, time.Hour); ok {
+		t.Fatal("stale entry should miss")
+	}
+}
+
+func TestKeySeparatesParts(t *testing.T) {
+	if Key("openai", "m", "ab", "c", "") == Key("openai", "m", "a", "bc", "") {
+		t.Fatal("keys should differ when text moves between parts")
+	}
+	if Key("OpenAI", "m", "s", "q", "") != Key("openai", "m", "s", "q", "") {
+		t.Fatal("provider names should be case-insensitive")
+	}
+	if _, err := entryPath(t.TempDir(), "../escape"); err == nil {
+		t.Fatal("expected invalid key error")
+	}
+}
@@ -0,0 +1,3 @@
+// Package cache stores parsed answers under the ask config directory so
+// repeated identical questions can skip the provider call.
+package cache
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sasanktumpati/ask/internal/history"
+)
+
+// againSentinel stands in for the question while validating `ask again`
+// flags, so stray positional arguments can be detected.
+const againSentinel = "\x00again"
+
+// runAgain replays the last recorded question through runAsk. Every ask flag
+// is accepted, so --provider, --model, and --temperature pick a different
+// target or a fresh sample. --refresh is always added: asking again means
+// wanting a new answer, not the cached one.
+func (a *App) runAgain(args []string) error {
+	opts, question, err := parseAskArgs(append(append([]string{}, args...), "--", againSentinel))
+	if err != nil {
+		if errors.Is(err, errShowHelp) {
+			printHelp(a.stdout, "again", a.cfgPath)
+			return nil
+		}
+		return err
+	}
+	if question != againSentinel {
+		return usageError("ask again [ask flags]; the question comes from the last ask")
+	}
+
+	last, err := history.LoadLastQuestion(history.LastCommandPath(a.cfgPath))
+	if err != nil {
+		if errors.Is(err, history.ErrNoLastQuestion) {
+			return fmt.Errorf("%w; ask a question first", err)
+		}
+		return err
+	}
+	if !opts.Quiet {
+		fmt.Fprintf(a.stderr, "again: %s\n", truncateForList(last.Question, 72))
+	}
+	return a.runAsk(append(append([]string{"--refresh"}, args...), "--", last.Question))
+}
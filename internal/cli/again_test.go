@@ -0,0 +1,44 @@
+package cli
+
+import "testing"
+
+func TestAgainReplaysLastQuestion(t *testing.T) {
+	stub := newStubProvider(t, `{"answer":"Use du.","command":""}`)
+	app, _ := stubApp(t, stub.URL)
+
+	if err := app.runAgain([]string{"-p", "stub"}); err == nil {
+		t.Fatal("expected an error before any question is recorded")
+	}
+	if err := app.runAsk([]string{"-q", "-p", "stub", "--no-markdown", "disk", "usage"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if err := app.runAgain([]string{"-q", "-p", "stub", "-t", "1.2"}); err != nil {
+		t.Fatalf("runAgain error = %v", err)
+	}
+	requests := stub.requests()
+	if len(requests) != 2 || lastMessage(requests[1]) != "disk usage" {
+		t.Fatalf("requests = %v", requests)
+	}
+	if requests[0]["temperature"] != nil || requests[1]["temperature"] != 1.2 {
+		t.Fatalf("temperatures = %v, %v", requests[0]["temperature"], requests[1]["temperature"])
+	}
+	if err := app.runAgain([]string{"-p", "stub", "different", "question"}); err == nil {
+		t.Fatal("expected usage error for a positional question")
+	}
+}
+
+func TestAgainSkipsCachedAnswer(t *testing.T) {
+	stub := newStubProvider(t, `{"answer":"Use du.","command":""}`)
+	app, _ := stubApp(t, stub.URL)
+	app.cfg.AnswerCacheTTL = 3600
+
+	if err := app.runAsk([]string{"-q", "-p", "stub", "--no-markdown", "disk", "usage"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if err := app.runAgain([]string{"-q", "-p", "stub", "--no-markdown"}); err != nil {
+		t.Fatalf("runAgain error = %v", err)
+	}
+	if calls := len(stub.requests()); calls != 2 {
+		t.Fatalf("calls = %d; ask again should not return the cached answer", calls)
+	}
+}
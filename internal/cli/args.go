@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -9,17 +11,52 @@ import (
 
 type globalOptions struct {
 	ConfigPath  string
+	Profile     string
+	Provider    string
 	ShowHelp    bool
 	ShowVersion bool
+	Verbose     bool
+	NoSpinner   bool
+	NoColor     bool
+	UserAgent   string
 }
 
 type askOptions struct {
-	Provider   string
-	Model      string
-	NoMarkdown bool
-	NoRun      bool
-	AsJSON     bool
-	Timeout    time.Duration
+	Provider    string
+	Model       string
+	NoMarkdown  bool
+	Theme       string
+	Width       int // markdown wrap width; 0 uses COLUMNS or the terminal
+	NoRun       bool
+	CommandOnly bool // print only the command, never run it; fail when there is none
+	DryRun      bool
+	Copy        bool
+	CopyCommand bool
+	Edit        bool
+	AsJSON      bool
+	Raw         bool // print the provider text verbatim; no parsing, rendering or run prompt
+	StrictJSON  bool
+	GitContext  bool
+	CodeFences  bool
+	NoHistory   bool
+	NoCache     bool // neither read nor write the answer cache
+	Refresh     bool // skip the cached answer but store the new one
+	Yes         bool
+	Verbose     bool
+	Quiet       bool
+	NoSpinner   bool
+	Files       []string
+	Contexts    []string // --context snippets, sent before files and the question
+	NoRedact    bool
+	Images      []string
+	Output      string
+	System      string // --system text, or the contents of --system-file
+	Preset      string // prompt preset that wraps the question
+	Temperature *float64
+	MaxTokens   int
+	Params      map[string]any // --param key=value, merged into the request payload
+	Fallback    []string       // nil = use config fallback_providers
+	Timeout     time.Duration
 }
 
 func parseGlobalArgs(args []string) (globalOptions, []string, error) {
@@ -55,10 +92,55 @@ func parseGlobalArgs(args []string) (globalOptions, []string, error) {
 				return opts, nil, fmt.Errorf("%s requires a non-empty value", formatFlagName(name))
 			}
 			opts.ConfigPath = value
+		case "profile":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return opts, nil, fmt.Errorf("%s requires a value", formatFlagName(name))
+				}
+				i++
+				value = args[i]
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return opts, nil, fmt.Errorf("%s requires a non-empty value", formatFlagName(name))
+			}
+			opts.Profile = value
+		case "provider", "p":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return opts, nil, fmt.Errorf("%s requires a value", formatFlagName(name))
+				}
+				i++
+				value = args[i]
+			}
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value == "" {
+				return opts, nil, fmt.Errorf("%s requires a non-empty value", formatFlagName(name))
+			}
+			opts.Provider = value
 		case "help", "h":
 			opts.ShowHelp = true
 		case "version", "v":
 			opts.ShowVersion = true
+		case "verbose", "V":
+			opts.Verbose = true
+		case "no-spinner":
+			opts.NoSpinner = true
+		case "no-color":
+			opts.NoColor = true
+		case "user-agent":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return opts, nil, fmt.Errorf("%s requires a value", formatFlagName(name))
+				}
+				i++
+				value = args[i]
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return opts, nil, fmt.Errorf("%s requires a non-empty value", formatFlagName(name))
+			}
+			opts.UserAgent = value
 		default:
 			return opts, args[i:], nil
 		}
@@ -69,7 +151,7 @@ func parseGlobalArgs(args []string) (globalOptions, []string, error) {
 }
 
 func parseAskArgs(args []string) (askOptions, string, error) {
-	opts := askOptions{Timeout: 90 * time.Second}
+	opts := askOptions{}
 	showHelp := false
 
 	rest, err := scanOptions(args, []optionSpec{
@@ -85,8 +167,107 @@ func parseAskArgs(args []string) (askOptions, string, error) {
 			return nil
 		}},
 		{Names: []string{"no-markdown"}, TakesValue: false, Set: func(string) error { opts.NoMarkdown = true; return nil }},
+		{Names: []string{"theme"}, TakesValue: true, Set: func(v string) error { opts.Theme = strings.TrimSpace(v); return nil }},
+		{Names: []string{"width"}, TakesValue: true, Set: func(v string) error {
+			n, err := parseWidth(v)
+			if err != nil {
+				return err
+			}
+			opts.Width = n
+			return nil
+		}},
 		{Names: []string{"no-run"}, TakesValue: false, Set: func(string) error { opts.NoRun = true; return nil }},
+		{Names: []string{"command-only"}, TakesValue: false, Set: func(string) error { opts.CommandOnly = true; return nil }},
+		{Names: []string{"dry-run"}, TakesValue: false, Set: func(string) error { opts.DryRun = true; return nil }},
+		{Names: []string{"copy"}, TakesValue: false, Set: func(string) error { opts.Copy = true; return nil }},
+		{Names: []string{"copy-command"}, TakesValue: false, Set: func(string) error { opts.CopyCommand = true; return nil }},
+		{Names: []string{"edit", "e"}, TakesValue: false, Set: func(string) error { opts.Edit = true; return nil }},
 		{Names: []string{"json"}, TakesValue: false, Set: func(string) error { opts.AsJSON = true; return nil }},
+		{Names: []string{"raw"}, TakesValue: false, Set: func(string) error { opts.Raw = true; return nil }},
+		{Names: []string{"strict-json"}, TakesValue: false, Set: func(string) error { opts.StrictJSON = true; return nil }},
+		{Names: []string{"git-context"}, TakesValue: false, Set: func(string) error { opts.GitContext = true; return nil }},
+		{Names: []string{"code-fences"}, TakesValue: false, Set: func(string) error { opts.CodeFences = true; return nil }},
+		{Names: []string{"no-cache"}, TakesValue: false, Set: func(string) error { opts.NoCache = true; return nil }},
+		{Names: []string{"refresh"}, TakesValue: false, Set: func(string) error { opts.Refresh = true; return nil }},
+		{Names: []string{"no-history"}, TakesValue: false, Set: func(string) error { opts.NoHistory = true; return nil }},
+		{Names: []string{"yes", "unsafe"}, TakesValue: false, Set: func(string) error { opts.Yes = true; return nil }},
+		{Names: []string{"verbose", "V"}, TakesValue: false, Set: func(string) error { opts.Verbose = true; return nil }},
+		{Names: []string{"quiet", "q"}, TakesValue: false, Set: func(string) error { opts.Quiet = true; return nil }},
+		{Names: []string{"no-spinner"}, TakesValue: false, Set: func(string) error { opts.NoSpinner = true; return nil }},
+		{Names: []string{"file", "f"}, TakesValue: true, Set: func(v string) error { opts.Files = append(opts.Files, strings.TrimSpace(v)); return nil }},
+		{Names: []string{"context"}, TakesValue: true, Set: func(v string) error {
+			if strings.TrimSpace(v) == "" {
+				return usageError("--context requires non-empty text")
+			}
+			opts.Contexts = append(opts.Contexts, v)
+			return nil
+		}},
+		{Names: []string{"no-redact"}, TakesValue: false, Set: func(string) error { opts.NoRedact = true; return nil }},
+		{Names: []string{"image", "i"}, TakesValue: true, Set: func(v string) error { opts.Images = append(opts.Images, strings.TrimSpace(v)); return nil }},
+		{Names: []string{"output", "o"}, TakesValue: true, Set: func(v string) error {
+			if opts.Output = strings.TrimSpace(v); opts.Output == "" {
+				return usageError("--output requires a non-empty path")
+			}
+			return nil
+		}},
+		{Names: []string{"preset"}, TakesValue: true, Set: func(v string) error {
+			if opts.Preset = strings.TrimSpace(v); opts.Preset == "" {
+				return usageError("--preset requires a non-empty name")
+			}
+			return nil
+		}},
+		{Names: []string{"system"}, TakesValue: true, Set: func(v string) error {
+			if opts.System = strings.TrimSpace(v); opts.System == "" {
+				return usageError("--system requires a non-empty prompt")
+			}
+			return nil
+		}},
+		{Names: []string{"system-file"}, TakesValue: true, Set: func(v string) error {
+			content, err := os.ReadFile(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("--system-file: %w", err)
+			}
+			if opts.System = strings.TrimSpace(string(content)); opts.System == "" {
+				return usageError(fmt.Sprintf("--system-file %s is empty", strings.TrimSpace(v)))
+			}
+			return nil
+		}},
+		{Names: []string{"temperature", "t"}, TakesValue: true, Set: func(v string) error {
+			t, err := parseTemperature(v)
+			if err != nil {
+				return err
+			}
+			opts.Temperature = &t
+			return nil
+		}},
+		{Names: []string{"fallback"}, TakesValue: true, Set: func(v string) error {
+			opts.Fallback = []string{}
+			for _, name := range strings.Split(v, ",") {
+				if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+					opts.Fallback = append(opts.Fallback, name)
+				}
+			}
+			return nil
+		}},
+		{Names: []string{"param"}, TakesValue: true, Set: func(v string) error {
+			key, value, err := parseKV(v)
+			if err != nil {
+				return fmt.Errorf("--param: %w", err)
+			}
+			if opts.Params == nil {
+				opts.Params = map[string]any{}
+			}
+			setParam(opts.Params, key, parseParamValue(value))
+			return nil
+		}},
+		{Names: []string{"max-tokens"}, TakesValue: true, Set: func(v string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || n <= 0 {
+				return usageError("--max-tokens must be a positive integer")
+			}
+			opts.MaxTokens = n
+			return nil
+		}},
 	})
 	if err != nil {
 		return opts, "", err
@@ -94,6 +275,12 @@ func parseAskArgs(args []string) (askOptions, string, error) {
 	if showHelp {
 		return opts, "", errShowHelp
 	}
+	if opts.Raw && opts.AsJSON {
+		return opts, "", usageError("--raw cannot be combined with --json")
+	}
+	if opts.CommandOnly && (opts.AsJSON || opts.Raw || opts.Output != "") {
+		return opts, "", usageError("--command-only cannot be combined with --json, --raw or --output")
+	}
 
 	question := strings.TrimSpace(strings.Join(rest, " "))
 	if question == "" {
@@ -102,6 +289,51 @@ func parseAskArgs(args []string) (askOptions, string, error) {
 	return opts, question, nil
 }
 
+// parseParamValue infers the type of a --param value: numbers, booleans,
+// null, objects and arrays are decoded as JSON, and anything else is kept as a
+// string. Numbers keep their exact text so large seeds survive.
+func parseParamValue(raw string) any {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil || dec.More() {
+		return raw
+	}
+	return value
+}
+
+// setParam stores value under key, treating dots as nesting so that
+// options.num_ctx=8192 becomes {"options":{"num_ctx":8192}}.
+func setParam(params map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := params[part].(map[string]any)
+		if !ok {
+			nested = map[string]any{}
+			params[part] = nested
+		}
+		params = nested
+	}
+	params[parts[len(parts)-1]] = value
+}
+
+func parseTemperature(raw string) (float64, error) {
+	t, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || t < 0 || t > 2 {
+		return 0, usageError("--temperature must be a number between 0 and 2")
+	}
+	return t, nil
+}
+
+// parseWidth parses a --width value, which must be a positive column count.
+func parseWidth(v string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n <= 0 {
+		return 0, usageError("--width must be a positive integer")
+	}
+	return n, nil
+}
+
 func parseDuration(raw string) (time.Duration, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
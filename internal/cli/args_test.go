@@ -1,9 +1,18 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/sasanktumpati/ask/internal/config"
 )
 
 func TestParseAskArgs_OptionsAnywhere(t *testing.T) {
@@ -74,3 +83,274 @@ func TestParseGlobalArgs_HelpBeforeCommand(t *testing.T) {
 		t.Fatalf("rest = %#v", rest)
 	}
 }
+
+func TestParseGlobalArgs_VerboseIsNotVersion(t *testing.T) {
+	global, rest, err := parseGlobalArgs([]string{"-V", "chat"})
+	if err != nil {
+		t.Fatalf("parseGlobalArgs error = %v", err)
+	}
+	if !global.Verbose || global.ShowVersion {
+		t.Fatalf("global = %+v", global)
+	}
+	if len(rest) != 1 || rest[0] != "chat" {
+		t.Fatalf("rest = %#v", rest)
+	}
+}
+
+func TestParseGlobalArgs_UserAgent(t *testing.T) {
+	global, rest, err := parseGlobalArgs([]string{"--user-agent", "corp/1", "models"})
+	if err != nil {
+		t.Fatalf("parseGlobalArgs error = %v", err)
+	}
+	if global.UserAgent != "corp/1" || len(rest) != 1 || rest[0] != "models" {
+		t.Fatalf("global = %+v, rest = %#v", global, rest)
+	}
+	if _, _, err := parseGlobalArgs([]string{"--user-agent="}); err == nil {
+		t.Fatal("expected error for empty --user-agent")
+	}
+}
+
+func TestParseAskArgs_RepeatableFile(t *testing.T) {
+	opts, q, err := parseAskArgs([]string{"explain", "--file", "a.yaml", "-f=b.json", "these"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if q != "explain these" {
+		t.Fatalf("question = %q", q)
+	}
+	if len(opts.Files) != 2 || opts.Files[0] != "a.yaml" || opts.Files[1] != "b.json" {
+		t.Fatalf("files = %#v", opts.Files)
+	}
+}
+
+func TestParseAskArgs_Temperature(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"--temperature", "0.7", "q"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if opts.Temperature == nil || *opts.Temperature != 0.7 {
+		t.Fatalf("temperature = %v", opts.Temperature)
+	}
+
+	opts, _, err = parseAskArgs([]string{"q"})
+	if err != nil || opts.Temperature != nil {
+		t.Fatalf("default temperature = %v, err = %v", opts.Temperature, err)
+	}
+
+	if _, _, err := parseAskArgs([]string{"-t", "2.5", "q"}); err == nil {
+		t.Fatal("expected out-of-range temperature error")
+	}
+}
+
+func TestParseAskArgs_DryRunAndYes(t *testing.T) {
+	opts, q, err := parseAskArgs([]string{"--dry-run", "clean", "build", "--unsafe"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if !opts.DryRun || !opts.Yes || opts.NoRun {
+		t.Fatalf("opts = %+v", opts)
+	}
+	if q != "clean build" {
+		t.Fatalf("question = %q", q)
+	}
+}
+
+func TestParseAskArgs_Copy(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"--copy", "list", "files", "--copy-command"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if !opts.Copy || !opts.CopyCommand {
+		t.Fatalf("opts = %+v", opts)
+	}
+}
+
+func TestParseAskArgs_Edit(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"--copy", "list", "files"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if opts.Edit {
+		t.Fatalf("Edit set without --edit: %+v", opts)
+	}
+	opts, _, err = parseAskArgs([]string{"--edit", "list", "files"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if !opts.Edit {
+		t.Fatalf("opts = %+v", opts)
+	}
+}
+
+func TestParseAskArgs_Output(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"-o", "out/result.json", "--json", "q"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if opts.Output != "out/result.json" || !opts.AsJSON {
+		t.Fatalf("opts = %+v", opts)
+	}
+	if _, _, err := parseAskArgs([]string{"--output=", "q"}); err == nil {
+		t.Fatal("expected empty --output error")
+	}
+}
+
+func TestParseAskArgs_System(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"--system", "You are a SQL expert.", "q"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if opts.System != "You are a SQL expert." {
+		t.Fatalf("System = %q", opts.System)
+	}
+
+	path := filepath.Join(t.TempDir(), "system.txt")
+	if err := os.WriteFile(path, []byte("Answer tersely.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts, _, err = parseAskArgs([]string{"--system-file", path, "q"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if opts.System != "Answer tersely." {
+		t.Fatalf("System = %q", opts.System)
+	}
+
+	if _, _, err := parseAskArgs([]string{"--system-file", filepath.Join(t.TempDir(), "missing"), "q"}); err == nil {
+		t.Fatal("expected missing --system-file error")
+	}
+}
+
+func TestParseNoSpinner(t *testing.T) {
+	global, rest, err := parseGlobalArgs([]string{"--no-spinner", "models", "list"})
+	if err != nil {
+		t.Fatalf("parseGlobalArgs error = %v", err)
+	}
+	if !global.NoSpinner || len(rest) != 2 {
+		t.Fatalf("global = %+v, rest = %v", global, rest)
+	}
+	opts, _, err := parseAskArgs([]string{"hello", "--no-spinner"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	if !opts.NoSpinner {
+		t.Fatalf("opts = %+v", opts)
+	}
+}
+
+func TestParseAskArgs_Params(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"hi",
+		"--param", "top_p=0.9", "--param", "seed=12345678901234567890", "--param", "logprobs=true",
+		"--param", "stop=[\"\\n\"]", "--param", "reasoning_effort=low",
+		"--param", "options.num_ctx=8192", "--param", "options.num_predict=512"})
+	if err != nil {
+		t.Fatalf("parseAskArgs error = %v", err)
+	}
+	got, err := json.Marshal(opts.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"logprobs":true,"options":{"num_ctx":8192,"num_predict":512},"reasoning_effort":"low","seed":12345678901234567890,"stop":["\n"],"top_p":0.9}`
+	if string(got) != want {
+		t.Fatalf("params = %s, want %s", got, want)
+	}
+
+	if _, _, err := parseAskArgs([]string{"hi", "--param", "top_p"}); err == nil {
+		t.Fatal("expected an error for --param without a value")
+	}
+}
+
+func TestRenderWidth(t *testing.T) {
+	opts, _, err := parseAskArgs([]string{"--width", "60", "q"})
+	if err != nil || opts.Width != 60 {
+		t.Fatalf("--width 60 = %d, %v", opts.Width, err)
+	}
+	for _, bad := range []string{"0", "-5", "wide"} {
+		if _, _, err := parseAskArgs([]string{"--width", bad, "q"}); err == nil {
+			t.Fatalf("--width %s should fail", bad)
+		}
+	}
+
+	app := &App{stdout: &bytes.Buffer{}}
+	t.Setenv("COLUMNS", "")
+	if got := app.renderWidth(0); got != 100 {
+		t.Fatalf("renderWidth(0) without a terminal = %d, want 100", got)
+	}
+	t.Setenv("COLUMNS", "72")
+	if got := app.renderWidth(0); got != 72 {
+		t.Fatalf("renderWidth(0) with COLUMNS=72 = %d", got)
+	}
+	if got := app.renderWidth(40); got != 40 {
+		t.Fatalf("renderWidth(40) = %d, want the override", got)
+	}
+}
+
+func TestParseGlobalProvider(t *testing.T) {
+	global, rest, err := parseGlobalArgs([]string{"-p", "OpenAI", "models", "list"})
+	if err != nil {
+		t.Fatalf("parseGlobalArgs error = %v", err)
+	}
+	if global.Provider != "openai" || len(rest) != 2 || rest[0] != "models" {
+		t.Fatalf("global = %+v, rest = %v", global, rest)
+	}
+	if _, _, err := parseGlobalArgs([]string{"--provider"}); err == nil {
+		t.Fatal("--provider without a value should fail")
+	}
+}
+
+func TestExpandShortcut(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"m"}, "models"},
+		{[]string{"m", "select", "--plain"}, "models select --plain"},
+		{[]string{"p", "list"}, "provider list"},
+		{[]string{"k", "--help"}, "key --help"},
+		{[]string{"cfg", "get", "shell"}, "config get shell"},
+		{[]string{"p", "value", "in", "statistics"}, "p value in statistics"},
+		{[]string{"m4a", "to", "mp3"}, "m4a to mp3"},
+	}
+	for _, tc := range tests {
+		if got := strings.Join(expandShortcut(tc.args), " "); got != tc.want {
+			t.Errorf("expandShortcut(%q) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestCommandNamesCoverDispatch(t *testing.T) {
+	for name := range commands {
+		if !config.IsCommandName(name) {
+			t.Errorf("dispatch accepts %q but config.IsCommandName does not reserve it", name)
+		}
+	}
+}
+
+func TestShortcutSubcommandsCoverHelp(t *testing.T) {
+	for command, subs := range shortcutSubcommands {
+		var help bytes.Buffer
+		printHelp(&help, command, "")
+		usage := regexp.MustCompile(`ask ` + command + ` ([a-z][a-z-]*)`)
+		for _, match := range usage.FindAllStringSubmatch(help.String(), -1) {
+			if !slices.Contains(subs, match[1]) {
+				t.Errorf("help documents `ask %s %s` but shortcutSubcommands[%q] lacks %q", command, match[1], command, match[1])
+			}
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Aliases = map[string]string{"ms": "m select --provider openai", "chat": "models list"}
+	app := &App{cfg: cfg}
+
+	if got := strings.Join(app.expandAlias([]string{"ms", "--plain"}), " "); got != "models select --provider openai --plain" {
+		t.Fatalf("expandAlias(ms) = %q", got)
+	}
+	if got := strings.Join(app.expandAlias([]string{"chat"}), " "); got != "chat" {
+		t.Fatalf("alias shadowed a built-in command: %q", got)
+	}
+	if got := strings.Join(app.expandAlias([]string{"what", "is", "ms"}), " "); got != "what is ms" {
+		t.Fatalf("expandAlias expanded a question: %q", got)
+	}
+}
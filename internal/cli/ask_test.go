@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestRunAskQuietPrintsOnlyAnswerAndCommand(t *testing.T) {
+	app, stdout := newStubApp(t, `{"answer":"List files.","command":"ls -la"}`)
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+
+	if err := app.runAsk([]string{"-q", "-p", "stub", "--no-markdown", "--no-history", "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if stdout.String() != "List files.\nls -la\n" {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("stderr = %q, want empty", stderr.String())
+	}
+}
+
+func TestAskTargetEnvOverrides(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, name := range []string{"envp", "flagp"} {
+		if err := cfg.AddCustomProvider(name, config.OpenAICompatibleProvider{BaseURL: "http://127.0.0.1:1", Model: "cfg-model"}); err != nil {
+			t.Fatalf("AddCustomProvider error = %v", err)
+		}
+	}
+	cfg.CurrentProvider = "flagp"
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	t.Setenv("ASK_PROVIDER", "")
+	t.Setenv("ASK_MODEL", "")
+	if provider, model, _, err := app.resolveAskTarget(app.envTarget("", "")); err != nil || provider != "flagp" || model != "cfg-model" {
+		t.Fatalf("config target = %s/%s (%v)", provider, model, err)
+	}
+
+	t.Setenv("ASK_PROVIDER", "envp")
+	t.Setenv("ASK_MODEL", "env-model")
+	if provider, model, _, err := app.resolveAskTarget(app.envTarget("", "")); err != nil || provider != "envp" || model != "env-model" {
+		t.Fatalf("env target = %s/%s (%v)", provider, model, err)
+	}
+	if provider, model, _, err := app.resolveAskTarget(app.envTarget("flagp", "flag-model")); err != nil || provider != "flagp" || model != "flag-model" {
+		t.Fatalf("flag target = %s/%s (%v)", provider, model, err)
+	}
+
+	// A global --provider beats ASK_PROVIDER but not the ask flag.
+	app.provider = "flagp"
+	if provider, _, _, err := app.resolveAskTarget(app.envTarget("", "")); err != nil || provider != "flagp" {
+		t.Fatalf("global target = %s (%v)", provider, err)
+	}
+	if provider, _, _, err := app.resolveAskTarget(app.envTarget("envp", "")); err != nil || provider != "envp" {
+		t.Fatalf("ask flag over global = %s (%v)", provider, err)
+	}
+}
+
+func TestResolveAskTargetRequiresCredentials(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	cfg := config.DefaultConfig()
+	cfg.SetModel("openai", "gpt-4o-mini")
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	_, _, _, err := app.resolveAskTarget("openai", "")
+	if err == nil || err.Error() != "no API key for openai; set OPENAI_API_KEY or run 'ask key set openai'" {
+		t.Fatalf("err = %v", err)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	if _, _, _, err := app.resolveAskTarget("openai", ""); err != nil {
+		t.Fatalf("with key err = %v", err)
+	}
+	if _, _, _, err := app.resolveAskTarget("ollama", "llama3"); err != nil {
+		t.Fatalf("ollama err = %v", err)
+	}
+}
+
+func TestRunAskInterruptCancelsRequest(t *testing.T) {
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Skip(err)
+	}
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := self.Signal(os.Interrupt); err != nil {
+			t.Errorf("signal: %v", err)
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	app, _ := stubApp(t, server.URL)
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+
+	err = app.runAsk([]string{"-p", "stub", "--no-history", "slow", "question"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 130 {
+		t.Fatalf("runAsk error = %v, want exit status 130", err)
+	}
+	if got := stderr.String(); !strings.Contains(got, "cancelled") {
+		t.Fatalf("stderr = %q", got)
+	}
+}
+
+func TestRunAskWarnsOnTruncation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{
+				"message":       map[string]any{"content": `{"answer":"Run find . -name`},
+				"finish_reason": "length",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	app, _ := stubApp(t, server.URL)
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+
+	if err := app.runAsk([]string{"-p", "stub", "--no-run", "--no-history", "find", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if !strings.Contains(stderr.String(), "response truncated at max tokens; raise --max-tokens") {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+}
+
+func TestRunAskRawPrintsProviderText(t *testing.T) {
+	const text = "Sure! ```json\n{\"answer\":\"**List** files.\",\"command\":\"ls\"}\n```"
+	app, stdout := newStubApp(t, text)
+	app.cfg.AnswerCacheTTL = 3600
+
+	if err := app.runAsk([]string{"--raw", "-p", "stub", "--no-history", "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if stdout.String() != text+"\n" {
+		t.Fatalf("stdout = %q, want the provider text verbatim", stdout.String())
+	}
+
+	if _, _, err := parseAskArgs([]string{"--raw", "--json", "q"}); err == nil {
+		t.Fatal("expected --raw with --json to be rejected")
+	}
+}
+
+func TestRunAskRawSkipsJSONContract(t *testing.T) {
+	stub := newStubProvider(t, "plain text")
+	app, _ := stubApp(t, stub.URL)
+
+	if err := app.runAsk([]string{"--raw", "--strict-json", "-p", "stub", "--no-history", "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	body := stub.requests()[0]
+	if _, ok := body["response_format"]; ok {
+		t.Fatalf("raw request asked for JSON output: %v", body["response_format"])
+	}
+	messages, _ := body["messages"].([]any)
+	system, _ := messages[0].(map[string]any)
+	if prompt, _ := system["content"].(string); strings.Contains(prompt, "strict JSON") {
+		t.Fatalf("raw system prompt kept the JSON contract: %q", prompt)
+	}
+}
+
+func TestRunAskCommandOnly(t *testing.T) {
+	stub := newStubProvider(t, `{"answer":"List files.","command":"ls -la"}`)
+	app, stdout := stubApp(t, stub.URL)
+
+	if err := app.runAsk([]string{"--command-only", "-p", "stub", "--no-history", "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if stdout.String() != "ls -la\n" {
+		t.Fatalf("stdout = %q, want only the command", stdout.String())
+	}
+
+	stub.setContent(`{"answer":"Nothing to run.","command":""}`)
+	stdout.Reset()
+	if err := app.runAsk([]string{"--command-only", "-p", "stub", "--no-history", "explain", "ls"}); err == nil {
+		t.Fatal("expected an error when the response has no command")
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("stdout = %q, want empty", stdout.String())
+	}
+}
+
+func TestRunAskOutputRenderedAndRaw(t *testing.T) {
+	const text = "{\"answer\":\"**List** files.\",\"command\":\"ls\"}"
+	app, stdout := newStubApp(t, text)
+	dir := t.TempDir()
+
+	rendered := filepath.Join(dir, "answer.md")
+	if err := app.runAsk([]string{"-p", "stub", "--no-run", "--no-history", "--no-cache", "-o", rendered, "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if got, _ := os.ReadFile(rendered); string(got) != "**List** files.\n" {
+		t.Fatalf("rendered output = %q, want the parsed answer", got)
+	}
+
+	raw := filepath.Join(dir, "reply.txt")
+	if err := app.runAsk([]string{"--raw", "-p", "stub", "--no-history", "-o", raw, "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if got, _ := os.ReadFile(raw); string(got) != text+"\n" {
+		t.Fatalf("raw output = %q, want the provider text verbatim", got)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("stdout = %q, want nothing printed", stdout.String())
+	}
+}
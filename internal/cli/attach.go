@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/providers"
+	"github.com/sasanktumpati/ask/internal/redact"
+)
+
+const (
+	maxAttachmentBytes      = 128 * 1024
+	maxAttachmentTotalBytes = 128 * 1024
+	maxImageBytes           = 20 * 1024 * 1024
+)
+
+// composeQuestion builds the user message from --context blocks, attached
+// files, and the question, in that order. The question is labeled only when
+// something precedes it.
+func composeQuestion(question string, contexts []string, files string) string {
+	var b strings.Builder
+	for i, text := range contexts {
+		text = strings.TrimRight(text, "\n")
+		fence := codeFence(text)
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "Context %d:\n%s\n%s\n%s", i+1, fence, text, fence)
+	}
+	if files != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(files)
+	}
+	if b.Len() == 0 {
+		return question
+	}
+	fmt.Fprintf(&b, "\n\nQuestion:\n%s", question)
+	return b.String()
+}
+
+// redactAttachments masks likely secrets in --context snippets and attached
+// files and reports how many values were masked to warn.
+func redactAttachments(contexts []string, files string, warn io.Writer) ([]string, string) {
+	total := 0
+	redacted := make([]string, len(contexts))
+	for i, text := range contexts {
+		var n int
+		redacted[i], n = redact.Secrets(text)
+		total += n
+	}
+	files, n := redact.Secrets(files)
+	total += n
+	if total > 0 {
+		fmt.Fprintf(warn, "redacted %d likely secret(s) from attached context; pass --no-redact to send it unchanged\n", total)
+	}
+	return redacted, files
+}
+
+// attachFiles renders each file as a fenced block labeled with the file's
+// basename. Binary files are skipped with a warning written to warn.
+func attachFiles(paths []string, warn io.Writer) (string, error) {
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	total := 0
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("--file %s: %w", path, err)
+		}
+		if info.IsDir() {
+			return "", fmt.Errorf("--file %s: is a directory", path)
+		}
+		if info.Size() > maxAttachmentBytes {
+			return "", fmt.Errorf("--file %s: %d bytes exceeds the %d byte per-file limit", path, info.Size(), maxAttachmentBytes)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("--file %s: %w", path, err)
+		}
+		if bytes.IndexByte(content, 0) >= 0 {
+			fmt.Fprintf(warn, "warning: skipping binary file %s\n", path)
+			continue
+		}
+		total += len(content)
+		if total > maxAttachmentTotalBytes {
+			return "", fmt.Errorf("attached files exceed the %d byte total limit", maxAttachmentTotalBytes)
+		}
+
+		name := filepath.Base(path)
+		fence := codeFence(string(content))
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "File: %s\n%s%s\n%s\n%s", name, fence, name, strings.TrimRight(string(content), "\n"), fence)
+	}
+	return b.String(), nil
+}
+
+// loadImages resolves --image values: http(s) URLs are passed through and
+// anything else is read as a local image file.
+func loadImages(refs []string) ([]providers.ImageInput, error) {
+	images := make([]providers.ImageInput, 0, len(refs))
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+			images = append(images, providers.ImageInput{URL: ref})
+			continue
+		}
+		info, err := os.Stat(ref)
+		if err != nil {
+			return nil, fmt.Errorf("--image %s: %w", ref, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("--image %s: is a directory", ref)
+		}
+		if info.Size() > maxImageBytes {
+			return nil, fmt.Errorf("--image %s: %d bytes exceeds the %d byte limit", ref, info.Size(), maxImageBytes)
+		}
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return nil, fmt.Errorf("--image %s: %w", ref, err)
+		}
+		mimeType := http.DetectContentType(data)
+		if !strings.HasPrefix(mimeType, "image/") {
+			return nil, fmt.Errorf("--image %s: not an image (detected %s)", ref, mimeType)
+		}
+		images = append(images, providers.ImageInput{MIMEType: mimeType, Data: data})
+	}
+	return images, nil
+}
+
+// codeFence returns a backtick fence longer than any fence inside content.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+			continue
+		}
+		run = 0
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
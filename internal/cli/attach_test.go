@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAttachFilesWrapsContentAndSkipsBinary(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(text, []byte("key: value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "blob.bin")
+	if err := os.WriteFile(binary, []byte{'a', 0, 'b'}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var warn bytes.Buffer
+	got, err := attachFiles([]string{text, binary}, &warn)
+	if err != nil {
+		t.Fatalf("attachFiles error = %v", err)
+	}
+	want := "File: config.yaml\n```config.yaml\nkey: value\n```"
+	if got != want {
+		t.Fatalf("attachFiles = %q, want %q", got, want)
+	}
+	if !strings.Contains(warn.String(), "blob.bin") {
+		t.Fatalf("expected binary skip warning, got %q", warn.String())
+	}
+}
+
+func TestComposeQuestionOrdersContextFilesQuestion(t *testing.T) {
+	if got := composeQuestion("explain", nil, ""); got != "explain" {
+		t.Fatalf("bare question = %q", got)
+	}
+	got := composeQuestion("why does this fail?", []string{"error: ENOSPC\n", "df shows 100%"}, "File: a.txt\n```a.txt\nx\n```")
+	want := "Context 1:\n```\nerror: ENOSPC\n```\n\nContext 2:\n```\ndf shows 100%\n```\n\n" +
+		"File: a.txt\n```a.txt\nx\n```\n\nQuestion:\nwhy does this fail?"
+	if got != want {
+		t.Fatalf("composeQuestion = %q, want %q", got, want)
+	}
+}
+
+func TestRedactAttachmentsReportsCount(t *testing.T) {
+	var warn bytes.Buffer
+	contexts, files := redactAttachments([]string{"token=abc123"}, "File: .env\n```.env\nDB_PASSWORD=hunter2\n```", &warn)
+	if contexts[0] != "token=[REDACTED]" || strings.Contains(files, "hunter2") {
+		t.Fatalf("contexts = %q, files = %q", contexts, files)
+	}
+	if !strings.Contains(warn.String(), "redacted 2 likely secret(s)") {
+		t.Fatalf("warning = %q", warn.String())
+	}
+
+	warn.Reset()
+	redactAttachments([]string{"nothing secret"}, "", &warn)
+	if warn.Len() != 0 {
+		t.Fatalf("unexpected warning %q", warn.String())
+	}
+}
+
+func TestAttachFilesRejectsOversized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.txt")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), maxAttachmentBytes+1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := attachFiles([]string{path}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected size limit error")
+	}
+}
+
+func TestAttachFilesRejectsOversizedTotal(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), maxAttachmentTotalBytes/2+1), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	if _, err := attachFiles(paths[:1], &bytes.Buffer{}); err != nil {
+		t.Fatalf("attachFiles error = %v", err)
+	}
+	if _, err := attachFiles(paths, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected total size limit error")
+	}
+}
+
+func TestCodeFenceOutgrowsInnerFences(t *testing.T) {
+	if got := codeFence("plain"); got != "```" {
+		t.Fatalf("codeFence = %q", got)
+	}
+	if got := codeFence("```go\nx\n```"); got != "````" {
+		t.Fatalf("codeFence = %q", got)
+	}
+}
+
+func TestLoadImages(t *testing.T) {
+	dir := t.TempDir()
+	png := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(png, []byte("\x89PNG\r\n\x1a\n0000"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("plain text"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	images, err := loadImages([]string{png, "https://example.com/cat.jpg"})
+	if err != nil {
+		t.Fatalf("loadImages error = %v", err)
+	}
+	if len(images) != 2 || images[0].MIMEType != "image/png" || images[1].URL != "https://example.com/cat.jpg" {
+		t.Fatalf("images = %+v", images)
+	}
+	if _, err := loadImages([]string{notes}); err == nil || !strings.Contains(err.Error(), "not an image") {
+		t.Fatalf("text file err = %v", err)
+	}
+}
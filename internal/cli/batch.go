@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+type batchOptions struct {
+	Provider    string
+	Model       string
+	Timeout     time.Duration
+	JSONInput   bool
+	Temperature *float64
+	MaxTokens   int
+}
+
+// batchResult is one line of batch output. Error is recorded instead of the
+// answer so a failing question does not stop the run.
+type batchResult struct {
+	Index     int              `json:"index"`
+	Question  string           `json:"question"`
+	Provider  string           `json:"provider"`
+	Model     string           `json:"model"`
+	Answer    string           `json:"answer,omitempty"`
+	Command   string           `json:"command,omitempty"`
+	Usage     *providers.Usage `json:"usage,omitempty"`
+	Truncated bool             `json:"truncated,omitempty"`
+	ElapsedMS int64            `json:"elapsed_ms"`
+	Error     string           `json:"error,omitempty"`
+}
+
+func parseBatchArgs(args []string) (batchOptions, string, error) {
+	opts := batchOptions{}
+	showHelp := false
+	rest, err := scanOptions(args, []optionSpec{
+		{Names: []string{"help", "h"}, TakesValue: false, Set: func(string) error { showHelp = true; return nil }},
+		{Names: []string{"provider", "p"}, TakesValue: true, Set: func(v string) error { opts.Provider = strings.TrimSpace(v); return nil }},
+		{Names: []string{"model", "m"}, TakesValue: true, Set: func(v string) error { opts.Model = strings.TrimSpace(v); return nil }},
+		{Names: []string{"timeout"}, TakesValue: true, Set: func(v string) error {
+			d, err := parseDuration(v)
+			if err != nil {
+				return fmt.Errorf("--timeout: %w", err)
+			}
+			opts.Timeout = d
+			return nil
+		}},
+		{Names: []string{"json"}, TakesValue: false, Set: func(string) error { opts.JSONInput = true; return nil }},
+		{Names: []string{"temperature", "t"}, TakesValue: true, Set: func(v string) error {
+			t, err := parseTemperature(v)
+			if err != nil {
+				return err
+			}
+			opts.Temperature = &t
+			return nil
+		}},
+		{Names: []string{"max-tokens"}, TakesValue: true, Set: func(v string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || n <= 0 {
+				return usageError("--max-tokens must be a positive integer")
+			}
+			opts.MaxTokens = n
+			return nil
+		}},
+	})
+	if err != nil {
+		return opts, "", err
+	}
+	if showHelp {
+		return opts, "", errShowHelp
+	}
+	if len(rest) != 1 {
+		return opts, "", usageError("ask batch <file|-> [--json] [--provider <name>] [--model <id>]")
+	}
+	return opts, rest[0], nil
+}
+
+// readBatchQuestions returns the questions in data: a JSON array of strings
+// when asJSON is set, otherwise one question per non-blank line.
+func readBatchQuestions(data []byte, asJSON bool) ([]string, error) {
+	var questions []string
+	if asJSON {
+		if err := json.Unmarshal(data, &questions); err != nil {
+			return nil, fmt.Errorf("parse questions: expected a JSON array of strings: %w", err)
+		}
+		out := questions[:0]
+		for _, q := range questions {
+			if q = strings.TrimSpace(q); q != "" {
+				out = append(out, q)
+			}
+		}
+		return out, nil
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if q := strings.TrimSpace(scanner.Text()); q != "" {
+			questions = append(questions, q)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read questions: %w", err)
+	}
+	return questions, nil
+}
+
+func (a *App) runBatch(args []string) error {
+	opts, path, err := parseBatchArgs(args)
+	if err != nil {
+		if errors.Is(err, errShowHelp) {
+			printHelp(a.stdout, "batch", a.cfgPath)
+			return nil
+		}
+		return err
+	}
+
+	var data []byte
+	if path == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("read questions: %w", err)
+	}
+	asJSON := opts.JSONInput || strings.EqualFold(filepath.Ext(path), ".json")
+	questions, err := readBatchQuestions(data, asJSON)
+	if err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("no questions in %s", path)
+	}
+
+	a.timeout = opts.Timeout
+	provider, model, client, err := a.resolveAskTarget(a.envTarget(opts.Provider, opts.Model))
+	if err != nil {
+		return err
+	}
+	settings := askSettings{
+		Prompt:      buildSystemPrompt(a.cfg.SystemPrompt, a.cfg.Shell, false, a.cfg.AllowCodeFences, false),
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	}
+
+	interrupt, stopInterrupt := interruptContext()
+	defer stopInterrupt()
+	enc := json.NewEncoder(a.stdout)
+	failed := 0
+	for i, question := range questions {
+		stopSpinner := a.startSpinner(fmt.Sprintf("Question %d/%d", i+1, len(questions)))
+		start := time.Now()
+		resp, err := a.askOnce(interrupt, provider, model, client, question, settings)
+		stopSpinner()
+		if interrupt.Err() != nil {
+			fmt.Fprintln(a.stderr, "cancelled")
+			return &ExitError{Code: 130}
+		}
+
+		result := batchResult{
+			Index:     i + 1,
+			Question:  question,
+			Provider:  provider,
+			Model:     model,
+			ElapsedMS: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			parsed, parseErr := assistant.Parse(resp.Text)
+			if parseErr != nil {
+				parsed = fallbackAssistantResponse(resp.Text)
+			}
+			result.Answer, result.Truncated = parsed.Answer, resp.Truncated
+			if parsed.HasCommand() {
+				result.Command = parsed.Command
+			}
+			if !resp.Usage.IsZero() {
+				result.Usage = &resp.Usage
+			}
+		}
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d questions failed", failed, len(questions))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestBatchWritesJSONLinesAndContinuesPastFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content any `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		last, _ := body.Messages[len(body.Messages)-1].Content.(string)
+		if strings.Contains(last, "break") {
+			http.Error(w, `{"error":{"message":"bad question"}}`, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"ok","command":"ls"}`}}},
+		})
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("local", config.OpenAICompatibleProvider{BaseURL: server.URL, Model: "m1"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.CurrentProvider = "local"
+	cfg.Retries = -1
+	dir := t.TempDir()
+	path := filepath.Join(dir, "questions.txt")
+	if err := os.WriteFile(path, []byte("list files\n\nbreak please\nshow disk usage\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(dir, "config.json"), cfg: cfg}
+
+	err := app.runBatch([]string{path})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 questions failed") {
+		t.Fatalf("runBatch error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), stdout.String())
+	}
+	var results []batchResult
+	for _, line := range lines {
+		var r batchResult
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		results = append(results, r)
+	}
+	if results[0].Answer != "ok" || results[0].Command != "ls" || results[0].Model != "m1" {
+		t.Fatalf("first result = %+v", results[0])
+	}
+	if results[1].Index != 2 || !strings.Contains(results[1].Error, "bad question") {
+		t.Fatalf("second result = %+v", results[1])
+	}
+	if results[2].Question != "show disk usage" || results[2].Error != "" {
+		t.Fatalf("third result = %+v", results[2])
+	}
+}
+
+func TestReadBatchQuestions(t *testing.T) {
+	got, err := readBatchQuestions([]byte(`["a", " ", "b"]`), true)
+	if err != nil || strings.Join(got, ",") != "a,b" {
+		t.Fatalf("JSON questions = %v, %v", got, err)
+	}
+	if _, err := readBatchQuestions([]byte("not json"), true); err == nil {
+		t.Fatal("expected an error for invalid JSON input")
+	}
+	got, _ = readBatchQuestions([]byte("  one \r\n\ntwo"), false)
+	if strings.Join(got, ",") != "one,two" {
+		t.Fatalf("line questions = %v", got)
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/cache"
+)
+
+func (a *App) runCache(args []string) error {
+	if a.showTopicHelpIfAnyFlagRequested("cache", args, 0) {
+		return nil
+	}
+	if len(args) == 0 {
+		return usageError("ask cache clear")
+	}
+	sub := strings.ToLower(strings.TrimSpace(args[0]))
+	switch sub {
+	case "clear":
+		if len(args) != 1 {
+			return usageError("ask cache clear")
+		}
+		removed, err := cache.Clear(cache.DirForConfig(a.cfgPath))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "removed %d cached answer(s)\n", removed)
+		return nil
+	default:
+		return unknownSubcommand("cache", sub)
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAskAnswerCache(t *testing.T) {
+	stub := newStubProvider(t, `{"answer":"List files.","command":"ls -la"}`)
+	app, stdout := stubApp(t, stub.URL)
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+	calls := func() int { return len(stub.requests()) }
+	ask := func(extra ...string) string {
+		t.Helper()
+		stdout.Reset()
+		stderr.Reset()
+		args := append([]string{"-p", "stub", "--no-run", "--no-history", "--no-markdown"}, extra...)
+		if err := app.runAsk(append(args, "list", "files")); err != nil {
+			t.Fatalf("runAsk error = %v", err)
+		}
+		return stdout.String()
+	}
+
+	ask()
+	ask()
+	if calls() != 2 {
+		t.Fatalf("calls = %d; the cache should be off by default", calls())
+	}
+
+	app.cfg.AnswerCacheTTL = 3600
+	ask()
+	if out := ask(); calls() != 3 || !strings.Contains(out, "ls -la") {
+		t.Fatalf("calls = %d, out = %q; second ask should hit the cache", calls(), out)
+	}
+	if !strings.Contains(stderr.String(), "cached answer") {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+	ask("--refresh")
+	ask("--no-cache")
+	if calls() != 5 {
+		t.Fatalf("calls = %d; --refresh and --no-cache should reach the provider", calls())
+	}
+
+	stdout.Reset()
+	if err := app.runCache([]string{"clear"}); err != nil {
+		t.Fatalf("cache clear error = %v", err)
+	}
+	if stdout.String() != "removed 1 cached answer(s)\n" {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+	ask()
+	if calls() != 6 {
+		t.Fatalf("calls = %d; cleared cache should miss", calls())
+	}
+}
+
+func TestAskAnswerCacheKeyIncludesSettings(t *testing.T) {
+	stub := newStubProvider(t, `{"answer":"List files.","command":"ls -la"}`)
+	app, _ := stubApp(t, stub.URL)
+	app.cfg.AnswerCacheTTL = 3600
+	calls := func() int { return len(stub.requests()) }
+	ask := func(extra ...string) {
+		t.Helper()
+		args := append([]string{"-p", "stub", "--no-run", "--no-history", "--no-markdown"}, extra...)
+		if err := app.runAsk(append(args, "list", "files")); err != nil {
+			t.Fatalf("runAsk error = %v", err)
+		}
+	}
+
+	ask()
+	ask("-t", "1.2")
+	ask("--max-tokens", "50")
+	ask("--param", "top_p=0.5")
+	if calls() != 4 {
+		t.Fatalf("calls = %d; each change of settings should miss the cache", calls())
+	}
+	ask("--param", "top_p=0.5")
+	if calls() != 4 {
+		t.Fatalf("calls = %d; repeating the same settings should hit the cache", calls())
+	}
+}
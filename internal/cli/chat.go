@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+	"github.com/sasanktumpati/ask/internal/history"
+	"github.com/sasanktumpati/ask/internal/providers"
+	"github.com/sasanktumpati/ask/internal/render"
+)
+
+type chatOptions struct {
+	Provider   string
+	Model      string
+	NoMarkdown bool
+	NoHistory  bool
+	Theme      string
+	Width      int
+	Timeout    time.Duration
+}
+
+func parseChatArgs(args []string) (chatOptions, error) {
+	opts := chatOptions{}
+	showHelp := false
+
+	rest, err := scanOptions(args, []optionSpec{
+		{Names: []string{"help", "h"}, TakesValue: false, Set: func(string) error { showHelp = true; return nil }},
+		{Names: []string{"provider", "p"}, TakesValue: true, Set: func(v string) error { opts.Provider = strings.TrimSpace(v); return nil }},
+		{Names: []string{"model", "m"}, TakesValue: true, Set: func(v string) error { opts.Model = strings.TrimSpace(v); return nil }},
+		{Names: []string{"timeout"}, TakesValue: true, Set: func(v string) error {
+			d, err := parseDuration(v)
+			if err != nil {
+				return fmt.Errorf("--timeout: %w", err)
+			}
+			opts.Timeout = d
+			return nil
+		}},
+		{Names: []string{"no-markdown"}, TakesValue: false, Set: func(string) error { opts.NoMarkdown = true; return nil }},
+		{Names: []string{"theme"}, TakesValue: true, Set: func(v string) error { opts.Theme = strings.TrimSpace(v); return nil }},
+		{Names: []string{"width"}, TakesValue: true, Set: func(v string) error {
+			n, err := parseWidth(v)
+			if err != nil {
+				return err
+			}
+			opts.Width = n
+			return nil
+		}},
+		{Names: []string{"no-history"}, TakesValue: false, Set: func(string) error { opts.NoHistory = true; return nil }},
+	})
+	if err != nil {
+		return opts, err
+	}
+	if showHelp {
+		return opts, errShowHelp
+	}
+	if len(rest) > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	return opts, nil
+}
+
+func (a *App) runChat(args []string) error {
+	opts, err := parseChatArgs(args)
+	if err != nil {
+		if errors.Is(err, errShowHelp) {
+			printHelp(a.stdout, "chat", a.cfgPath)
+			return nil
+		}
+		return err
+	}
+	a.timeout = opts.Timeout
+
+	provider, model, client, err := a.resolveAskTarget(a.envTarget(opts.Provider, opts.Model))
+	if err != nil {
+		return err
+	}
+
+	renderMarkdown := a.cfg.RenderMarkdown && !opts.NoMarkdown
+	prompt := buildSystemPrompt(a.cfg.SystemPrompt, a.cfg.Shell, renderMarkdown, a.cfg.AllowCodeFences, a.cfg.GitContext)
+	width := a.renderWidth(opts.Width)
+	theme := a.markdownTheme(opts.Theme)
+
+	fmt.Fprintf(a.stdout, "chat with %s/%s (/reset clears history, Ctrl+D exits)\n", provider, model)
+
+	reader := bufio.NewReader(a.stdin)
+	var messages []providers.Message
+	var transcript *history.Transcript
+	for {
+		fmt.Fprint(a.stdout, "> ")
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return readErr
+		}
+		question := strings.TrimSpace(line)
+		if errors.Is(readErr, io.EOF) && question == "" {
+			fmt.Fprintln(a.stdout)
+			return nil
+		}
+
+		switch question {
+		case "":
+			continue
+		case "/reset":
+			messages = nil
+			transcript = nil
+			fmt.Fprintln(a.stdout, "history cleared")
+			continue
+		case "/exit", "/quit":
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), a.askTimeout(provider))
+		stopSpinner := a.startSpinner("Thinking")
+		resp, err := client.Ask(ctx, providers.AskRequest{
+			Model:      model,
+			Prompt:     prompt,
+			Question:   question,
+			History:    messages,
+			ExpectJSON: true,
+			JSONSchema: a.responseSchema(false),
+			MaxTokens:  a.cfg.GetMaxTokens(provider),
+		})
+		stopSpinner()
+		cancel()
+		if err != nil {
+			fmt.Fprintln(a.stderr, "error:", a.explainError(err))
+			continue
+		}
+
+		messages = append(messages,
+			providers.Message{Role: providers.RoleUser, Content: question},
+			providers.Message{Role: providers.RoleAssistant, Content: resp.Text},
+		)
+		if !opts.NoHistory {
+			transcript = a.recordExchange(transcript, provider, model, question, resp.Text)
+		}
+
+		parsed, parseErr := assistant.Parse(resp.Text)
+		if parseErr != nil {
+			parsed = fallbackAssistantResponse(resp.Text)
+		}
+		if parsed.Answer != "" {
+			fmt.Fprintln(a.stdout, render.Markdown(parsed.Answer, width, renderMarkdown, theme))
+		}
+		if parsed.HasCommand() {
+			fmt.Fprintf(a.stdout, "$ %s\n", parsed.Command)
+		}
+		a.printUsage(resp.Usage)
+		if resp.Truncated {
+			fmt.Fprintf(a.stderr, "warning: response truncated at max tokens; raise max_tokens for %s\n", provider)
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+	}
+}
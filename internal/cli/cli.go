@@ -7,13 +7,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sasanktumpati/ask/internal/assistant"
+	"github.com/sasanktumpati/ask/internal/cache"
+	"github.com/sasanktumpati/ask/internal/clipboard"
 	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/history"
+	"github.com/sasanktumpati/ask/internal/modelcache"
 	"github.com/sasanktumpati/ask/internal/providers"
 	"github.com/sasanktumpati/ask/internal/render"
 	"github.com/sasanktumpati/ask/internal/runner"
@@ -23,6 +31,19 @@ import (
 
 var errShowHelp = errors.New("show help")
 
+// ExitError reports that ask should exit with Code. It is returned when a
+// command run from the prompt fails, after the failure has been reported, so
+// callers should exit without printing it again.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+const defaultAskTimeout = 90 * time.Second
+
 // App encapsulates CLI runtime dependencies and loaded configuration.
 type App struct {
 	stdin   io.Reader
@@ -30,6 +51,17 @@ type App struct {
 	stderr  io.Writer
 	cfgPath string
 	cfg     *config.Config
+	// basePath is the default profile's config file; profile files live
+	// next to it.
+	basePath  string
+	profile   string
+	timeout   time.Duration // --timeout override; 0 uses per-provider config
+	trace     *slog.Logger  // non-nil when --verbose is set
+	noSpinner bool          // --no-spinner
+	noColor   bool          // --no-color; NO_COLOR has the same effect
+	userAgent string        // --user-agent; wins over config user_agent
+
+	provider string // global --provider; wins over ASK_PROVIDER and current_provider
 }
 
 // Run executes the ask CLI with the provided process arguments and streams.
@@ -48,11 +80,17 @@ func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) err
 	if err != nil {
 		return err
 	}
+	rest = expandShortcut(rest)
 
-	cfgPath, err := config.ResolvePath(global.ConfigPath)
+	basePath, err := config.ResolvePath(global.ConfigPath)
+	if err != nil {
+		return err
+	}
+	profile, err := config.ResolveProfile(basePath, global.Profile)
 	if err != nil {
 		return err
 	}
+	cfgPath := config.ProfilePath(basePath, profile)
 	templatePath := config.TemplatePathForConfig(cfgPath)
 	if err := config.EnsureTemplate(templatePath); err != nil {
 		return err
@@ -60,15 +98,30 @@ func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) err
 
 	cfg, loadErr := config.Load(cfgPath)
 	if loadErr != nil && !errors.Is(loadErr, config.ErrConfigNotFound) {
-		return loadErr
+		if !toleratesInvalidConfig(rest) {
+			return loadErr
+		}
+		cfg, loadErr = config.DefaultConfig(), nil
+	}
+	missing := errors.Is(loadErr, config.ErrConfigNotFound)
+	if missing && profile != config.DefaultProfile && !isProfileCommand(rest) {
+		return fmt.Errorf("profile %q does not exist; create it with `ask config profile new %s`", profile, profile)
 	}
-	if errors.Is(loadErr, config.ErrConfigNotFound) {
-		if err := config.Save(cfgPath, cfg); err != nil {
+
+	app := &App{stdin: stdin, stdout: stdout, stderr: stderr, cfgPath: cfgPath, cfg: cfg, basePath: basePath, profile: profile}
+	if missing && profile == config.DefaultProfile {
+		if err := app.updateConfig(func(*config.Config) error { return nil }); err != nil {
 			return err
 		}
 	}
-
-	app := &App{stdin: stdin, stdout: stdout, stderr: stderr, cfgPath: cfgPath, cfg: cfg}
+	if global.Verbose {
+		app.enableTrace()
+	}
+	app.noSpinner = global.NoSpinner
+	app.noColor = global.NoColor
+	app.userAgent = global.UserAgent
+	app.provider = global.Provider
+	rest = app.expandAlias(rest)
 	if global.ShowVersion {
 		fmt.Fprintln(app.stdout, version)
 		return nil
@@ -77,7 +130,50 @@ func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) err
 		helpArgs := append([]string{"help"}, rest...)
 		return app.dispatch(helpArgs)
 	}
-	return app.dispatch(rest)
+	return app.explainError(app.dispatch(rest))
+}
+
+// explainError replaces provider authentication failures with a hint on
+// where the API key comes from; other errors pass through unchanged.
+func (a *App) explainError(err error) error {
+	var httpErr *providers.HTTPError
+	if !errors.As(err, &httpErr) || !httpErr.IsAuth() || httpErr.Provider == "" {
+		return err
+	}
+	source := "config"
+	if envVar := a.apiKeyEnv(httpErr.Provider); envVar != "" {
+		source = "env " + envVar + " or config"
+	}
+	msg := fmt.Sprintf("authentication failed for %s (%s); check your API key (%s)", httpErr.Provider, httpErr.Status, source)
+	if a.trace == nil {
+		msg += "; rerun with --verbose to see the provider response"
+	}
+	return errors.New(msg)
+}
+
+// isProfileCommand reports whether args name `config profile`, which must work
+// even when the selected profile has no config file yet.
+func isProfileCommand(args []string) bool {
+	return len(args) >= 2 && strings.EqualFold(strings.TrimSpace(args[0]), "config") &&
+		strings.EqualFold(strings.TrimSpace(args[1]), "profile")
+}
+
+// toleratesInvalidConfig reports whether args name a command that can run
+// with an unreadable config file, such as `config edit` and `config restore`
+// used to repair it or doctor, which reports it.
+func toleratesInvalidConfig(args []string) bool {
+	if len(args) > 0 && strings.EqualFold(strings.TrimSpace(args[0]), "doctor") {
+		return true
+	}
+	if len(args) < 2 || !strings.EqualFold(strings.TrimSpace(args[0]), "config") {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(args[1])) {
+	case "edit", "path", "validate", "restore":
+		return true
+	default:
+		return false
+	}
 }
 
 func (a *App) dispatch(args []string) error {
@@ -88,32 +184,57 @@ func (a *App) dispatch(args []string) error {
 
 	sub := strings.ToLower(strings.TrimSpace(args[0]))
 	switch sub {
-	case "help":
-		topic := ""
-		if len(args) > 1 {
-			topic = strings.ToLower(strings.TrimSpace(args[1]))
-		}
-		printHelp(a.stdout, topic, a.cfgPath)
-		return nil
 	case "-h", "--help":
 		printHelp(a.stdout, "", a.cfgPath)
 		return nil
-	case "version", "--version", "-v":
-		fmt.Fprintln(a.stdout, version)
-		return nil
-	case "models", "model":
-		return a.runModels(args[1:])
-	case "provider", "providers":
-		return a.runProviders(args[1:])
-	case "key", "keys":
-		return a.runKeys(args[1:])
-	case "config":
-		return a.runConfig(args[1:])
-	case "markdown":
-		return a.runMarkdown(args[1:])
-	default:
-		return a.runAsk(args)
+	case "--version", "-v":
+		return a.runVersion(nil)
+	}
+	if run, ok := commands[sub]; ok {
+		return run(a, args[1:])
+	}
+	return a.runAsk(args)
+}
+
+// commands maps each top-level command word to its handler; anything else is
+// a question. Every name must also be known to config.IsCommandName so that
+// user aliases cannot shadow it.
+var commands = map[string]func(*App, []string) error{
+	"help":      (*App).runHelp,
+	"version":   (*App).runVersion,
+	"models":    (*App).runModels,
+	"model":     (*App).runModels,
+	"provider":  (*App).runProviders,
+	"providers": (*App).runProviders,
+	"key":       (*App).runKeys,
+	"keys":      (*App).runKeys,
+	"config":    (*App).runConfig,
+	"markdown":  (*App).runMarkdown,
+	"chat":      (*App).runChat,
+	"history":   (*App).runHistory,
+	"embed":     (*App).runEmbed,
+	"prompt":    (*App).runPrompt,
+	"prompts":   (*App).runPrompt,
+	"rerun":     (*App).runRerun,
+	"again":     (*App).runAgain,
+	"compare":   (*App).runCompare,
+	"cache":     (*App).runCache,
+	"batch":     (*App).runBatch,
+	"doctor":    (*App).runDoctor,
+}
+
+func (a *App) runHelp(args []string) error {
+	topic := ""
+	if len(args) > 0 {
+		topic = strings.ToLower(strings.TrimSpace(args[0]))
 	}
+	printHelp(a.stdout, topic, a.cfgPath)
+	return nil
+}
+
+func (a *App) runVersion([]string) error {
+	fmt.Fprintln(a.stdout, version)
+	return nil
 }
 
 func (a *App) runAsk(args []string) error {
@@ -126,70 +247,231 @@ func (a *App) runAsk(args []string) error {
 		return err
 	}
 
-	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
-	if provider == "" {
-		provider = strings.ToLower(strings.TrimSpace(a.cfg.CurrentProvider))
+	// notes receives non-essential stderr output, which --quiet discards.
+	notes := a.stderr
+	if opts.Quiet {
+		notes = io.Discard
 	}
-	if provider == "" {
-		return fmt.Errorf("no default provider set; run `ask provider set <name>` or pass --provider")
+
+	if opts.Preset != "" {
+		if question, err = a.applyPreset(opts.Preset, question); err != nil {
+			return err
+		}
 	}
-	if !a.cfg.ProviderExists(provider) {
-		return fmt.Errorf("provider %q is not configured", provider)
+	files, err := attachFiles(opts.Files, notes)
+	if err != nil {
+		return err
 	}
-
-	model := strings.TrimSpace(opts.Model)
-	if model == "" {
-		model = strings.TrimSpace(a.cfg.GetModel(provider))
+	contexts := opts.Contexts
+	if !opts.NoRedact {
+		contexts, files = redactAttachments(contexts, files, notes)
 	}
-
-	client, err := a.newClient(provider)
+	question = composeQuestion(question, contexts, files)
+	images, err := loadImages(opts.Images)
 	if err != nil {
 		return err
 	}
-
-	if model == "" {
-		models, listErr := client.ListModels(context.Background())
-		if listErr != nil {
-			return fmt.Errorf("no model set for provider %q and unable to list models: %w", provider, listErr)
-		}
-		if len(models) == 0 {
-			return fmt.Errorf("no models available for provider %q", provider)
-		}
-		model = selectDefaultModel(models)
-		a.cfg.SetModel(provider, model)
-		if err := a.saveConfig(); err != nil {
-			return err
-		}
+	a.timeout = opts.Timeout
+	if opts.Verbose {
+		a.enableTrace()
 	}
 
-	shell := strings.TrimSpace(os.Getenv("SHELL"))
-	if shell == "" {
-		shell = "sh"
+	provider, model, client, err := a.resolveAskTarget(a.envTarget(opts.Provider, opts.Model))
+	if err != nil {
+		return err
 	}
-	cwd, _ := os.Getwd()
+
 	renderMarkdown := a.cfg.RenderMarkdown && !opts.NoMarkdown
-	prompt := assistant.BuildPrompt(shell, cwd, runtime.GOOS, renderMarkdown)
+	system := opts.System
+	if system == "" {
+		system = a.cfg.SystemPrompt
+	}
+	prompt := buildSystemPrompt(system, a.cfg.Shell, renderMarkdown, opts.CodeFences || a.cfg.AllowCodeFences, opts.GitContext || a.cfg.GitContext)
+	if opts.Raw {
+		prompt = buildRawSystemPrompt(system, a.cfg.Shell, opts.GitContext || a.cfg.GitContext)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
-	defer cancel()
+	fallbacks := opts.Fallback
+	if fallbacks == nil {
+		fallbacks = a.cfg.Fallbacks
+	}
 
-	stopSpinner := startSpinner(!opts.AsJSON && isTerminalWriter(a.stderr), a.stderr, "Thinking")
-	resp, err := client.Ask(ctx, providers.AskRequest{
-		Model:      model,
-		Prompt:     prompt,
-		Question:   question,
-		ExpectJSON: true,
-	})
+	if opts.NoSpinner || opts.AsJSON || opts.Quiet || opts.CommandOnly {
+		a.noSpinner = true
+	}
+	// Raw output needs the provider's own text, which the cache does not keep.
+	cacheDir, cacheKey, cacheTTL := a.answerCache(provider, model, prompt, question, cacheSettings(opts), len(images) > 0 || opts.NoCache || opts.Raw)
+	var parsed assistant.Response
+	cached := false
+	if cacheKey != "" && !opts.Refresh {
+		parsed, cached = cache.Get(cacheDir, cacheKey, cacheTTL)
+	}
+	if cached {
+		fmt.Fprintln(notes, "cached answer; pass --refresh to ask again")
+		if !opts.NoHistory {
+			a.recordLastExchange(provider, model, question, parsed.Command)
+		}
+		return a.printAnswer(opts, notes, provider, model, question, parsed, providers.Usage{}, renderMarkdown, false)
+	}
+
+	settings := askSettings{
+		Prompt:      prompt,
+		Images:      images,
+		Raw:         opts.Raw,
+		StrictJSON:  opts.StrictJSON,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Params:      opts.Params,
+	}
+	interrupt, stopInterrupt := interruptContext()
+	defer stopInterrupt()
+	stopSpinner := a.startSpinner("Thinking")
+	provider, model, resp, err := a.askWithFallback(notes, provider, model, client, fallbacks,
+		func(provider, model string, client providers.Client) (providers.AskResponse, error) {
+			return a.askOnce(interrupt, provider, model, client, question, settings)
+		})
 	stopSpinner()
+	interrupted := interrupt.Err() != nil
+	stopInterrupt()
 	if err != nil {
+		if interrupted && errors.Is(err, context.Canceled) {
+			fmt.Fprintln(a.stderr, "cancelled")
+			return &ExitError{Code: 130}
+		}
 		return err
 	}
 
+	if !opts.NoHistory {
+		if err := history.AppendOneShot(a.historyDir(), provider, model, question, resp.Text); err != nil {
+			fmt.Fprintln(a.stderr, "warning: unable to save history:", err)
+		}
+	}
+
+	if opts.Raw {
+		if !opts.NoHistory {
+			a.recordLastExchange(provider, model, question, "")
+		}
+		text := resp.Text
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if opts.Output != "" {
+			// --raw files get the provider's reply byte for byte, unlike the
+			// parsed answer written without it.
+			if err := writeOutputFile(opts.Output, []byte(text)); err != nil {
+				return err
+			}
+			fmt.Fprintf(notes, "wrote %s\n", opts.Output)
+		} else {
+			fmt.Fprint(a.stdout, text)
+		}
+		if resp.Truncated {
+			fmt.Fprintln(notes, "warning: response truncated at max tokens; raise --max-tokens")
+		}
+		return nil
+	}
+
 	parsed, parseErr := assistant.Parse(resp.Text)
 	if parseErr != nil {
 		parsed = fallbackAssistantResponse(resp.Text)
 	}
+	if !opts.NoHistory {
+		command := ""
+		if parsed.HasCommand() {
+			command = parsed.Command
+		}
+		a.recordLastExchange(provider, model, question, command)
+	}
+	if resp.Truncated {
+		fmt.Fprintln(notes, "warning: response truncated at max tokens; raise --max-tokens")
+	}
+	// Only complete, strictly parsed answers are cached, so a malformed or
+	// cut-off response is retried next time rather than replayed.
+	if cacheKey != "" && parseErr == nil && !resp.Truncated {
+		stored := parsed
+		if !stored.HasCommand() {
+			stored.Command = ""
+		}
+		if err := cache.Put(cacheDir, cacheKey, stored); err != nil {
+			fmt.Fprintln(a.stderr, "warning: unable to cache answer:", err)
+		}
+	}
+	return a.printAnswer(opts, notes, provider, model, question, parsed, resp.Usage, renderMarkdown, parseErr != nil)
+}
+
+// askSettings are the request options askOnce applies to every question of
+// a run.
+type askSettings struct {
+	Prompt      string
+	Images      []providers.ImageInput
+	Raw         bool // free-form text: no JSON mode and no response schema
+	StrictJSON  bool
+	Temperature *float64
+	MaxTokens   int // 0 uses the provider's configured max_tokens
+	Params      map[string]any
+}
+
+// askOnce sends a single question to model under the ask timeout for
+// provider and returns the raw response.
+func (a *App) askOnce(ctx context.Context, provider, model string, client providers.Client, question string, s askSettings) (providers.AskResponse, error) {
+	maxTokens := s.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = a.cfg.GetMaxTokens(provider)
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.askTimeout(provider))
+	defer cancel()
+	a.tracef("ask", "provider", provider, "base_url", a.cfg.ResolveBaseURL(provider), "model", model, "max_tokens", maxTokens)
+	schema := a.responseSchema(s.StrictJSON)
+	if s.Raw {
+		schema = nil
+	}
+	return client.Ask(ctx, providers.AskRequest{
+		Model:       model,
+		Prompt:      s.Prompt,
+		Question:    question,
+		Images:      s.Images,
+		ExpectJSON:  !s.Raw,
+		JSONSchema:  schema,
+		Temperature: s.Temperature,
+		MaxTokens:   maxTokens,
+		Extra:       s.Params,
+	})
+}
+
+// answerCache returns the directory, key, and TTL for caching this answer.
+// The key is empty when answer_cache_ttl_seconds is unset, when skip is set,
+// or when there is no config path to cache next to.
+func (a *App) answerCache(provider, model, prompt, question, settings string, skip bool) (string, string, time.Duration) {
+	dir := cache.DirForConfig(a.cfgPath)
+	if skip || a.cfg.AnswerCacheTTL <= 0 || dir == "" {
+		return "", "", 0
+	}
+	return dir, cache.Key(provider, model, prompt, question, settings), time.Duration(a.cfg.AnswerCacheTTL) * time.Second
+}
 
+// cacheSettings encodes the request options that change an answer, so asking
+// again with another --temperature, --max-tokens or --param misses the cache.
+// Map keys marshal sorted, which keeps the encoding stable.
+func cacheSettings(opts askOptions) string {
+	buf, _ := json.Marshal(struct {
+		Temperature *float64       `json:"temperature,omitempty"`
+		MaxTokens   int            `json:"max_tokens,omitempty"`
+		Params      map[string]any `json:"params,omitempty"`
+	}{opts.Temperature, opts.MaxTokens, opts.Params})
+	return string(buf)
+}
+
+// printAnswer writes the answer in the format opts selects and then offers to
+// run the command. fallbackParsed reports that the response was not strict
+// JSON.
+func (a *App) printAnswer(opts askOptions, notes io.Writer, provider, model, question string, parsed assistant.Response, usage providers.Usage, renderMarkdown, fallbackParsed bool) error {
+	if opts.CommandOnly {
+		if !parsed.HasCommand() {
+			return fmt.Errorf("response contained no command")
+		}
+		fmt.Fprintln(a.stdout, parsed.Command)
+		return nil
+	}
 	if opts.AsJSON {
 		out := map[string]any{
 			"provider": provider,
@@ -197,63 +479,386 @@ func (a *App) runAsk(args []string) error {
 			"question": question,
 			"answer":   parsed.Answer,
 			"command":  parsed.Command,
+			"usage":    usage,
+		}
+		if opts.Output == "" {
+			enc := json.NewEncoder(a.stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		}
+		buf, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode JSON output: %w", err)
 		}
-		enc := json.NewEncoder(a.stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(out)
+		if err := writeOutputFile(opts.Output, append(buf, '\n')); err != nil {
+			return err
+		}
+		fmt.Fprintf(notes, "wrote %s\n", opts.Output)
+		if parsed.HasCommand() {
+			fmt.Fprintf(notes, "$ %s\n", parsed.Command)
+		}
+		return nil
 	}
-	if parsed.Answer != "" {
-		width := terminalWidth(a.stdout)
-		fmt.Fprintln(a.stdout, render.Markdown(parsed.Answer, width, renderMarkdown))
+	if opts.Output != "" {
+		// Files get the parsed answer as plain markdown; terminal escape codes
+		// have no place there and the command goes to notes instead.
+		if err := writeOutputFile(opts.Output, []byte(render.Markdown(parsed.Answer, 0, false, "")+"\n")); err != nil {
+			return err
+		}
+		fmt.Fprintf(notes, "wrote %s\n", opts.Output)
+		if parsed.HasCommand() {
+			fmt.Fprintf(notes, "$ %s\n", parsed.Command)
+		}
+	} else if parsed.Answer != "" {
+		width := a.renderWidth(opts.Width)
+		fmt.Fprintln(a.stdout, render.Markdown(parsed.Answer, width, renderMarkdown, a.markdownTheme(opts.Theme)))
+	}
+	if !opts.Quiet {
+		a.printUsage(usage)
+	}
+	if opts.Copy && parsed.Answer != "" {
+		a.copyToClipboard("answer", render.Markdown(parsed.Answer, 0, false, ""), notes)
 	}
 
 	if parsed.HasCommand() {
-		if opts.NoRun {
+		if opts.CopyCommand {
+			if !opts.Quiet {
+				fmt.Fprintln(a.stdout)
+			}
+			fmt.Fprintln(a.stdout, parsed.Command)
+			a.copyToClipboard("command", parsed.Command, notes)
+			return nil
+		}
+		if opts.Output != "" && opts.NoRun {
+			return nil
+		}
+		if opts.Quiet && !opts.DryRun {
+			fmt.Fprintln(a.stdout, parsed.Command)
+			return nil
+		}
+		if opts.NoRun || opts.DryRun {
 			fmt.Fprintln(a.stdout)
 			fmt.Fprintln(a.stdout, parsed.Command)
+			if opts.DryRun {
+				if err := clipboard.Copy(parsed.Command); err == nil {
+					fmt.Fprintln(notes, "dry run: command copied to clipboard, not executed")
+				} else {
+					fmt.Fprintln(notes, "dry run: command not executed")
+				}
+			}
 			return nil
 		}
-		if err := runner.PromptAndRun(runner.RunOptions{
-			Command: parsed.Command,
-			Stdin:   a.stdin,
-			Stdout:  a.stdout,
-			Stderr:  a.stderr,
-		}); err != nil {
+		status, err := runner.PromptAndRun(runner.RunOptions{
+			Command:     parsed.Command,
+			Stdin:       a.stdin,
+			Stdout:      a.stdout,
+			Stderr:      a.stderr,
+			AllowUnsafe: opts.Yes,
+			Edit:        opts.Edit,
+			Shell:       a.cfg.Shell,
+			Highlight:   a.cfg.HighlightCmds && !a.noColor,
+		})
+		if err != nil {
 			return err
 		}
+		if status != 0 {
+			fmt.Fprintf(a.stderr, "command exited with status %d\n", status)
+			return &ExitError{Code: status}
+		}
 	}
 
-	if parseErr != nil {
-		fmt.Fprintln(a.stderr, "warning: provider response was not strict JSON; used fallback parser")
+	if fallbackParsed {
+		fmt.Fprintln(notes, "warning: provider response was not strict JSON; used fallback parser")
 	}
 	return nil
 }
 
+// responseSchema returns the answer/command schema when strict JSON output is
+// requested by flag or config, and nil otherwise.
+func (a *App) responseSchema(strict bool) *providers.JSONSchema {
+	if !strict && !a.cfg.StrictJSON {
+		return nil
+	}
+	return &providers.JSONSchema{Name: "ask_response", Schema: assistant.Schema()}
+}
+
+// printUsage writes a one-line token summary to stderr, dimmed on terminals.
+func (a *App) printUsage(usage providers.Usage) {
+	if usage.IsZero() {
+		return
+	}
+	line := fmt.Sprintf("tokens: %d in / %d out", usage.PromptTokens, usage.CompletionTokens)
+	if isTerminalWriter(a.stderr) {
+		line = "\033[2m" + line + "\033[0m"
+	}
+	fmt.Fprintln(a.stderr, line)
+}
+
+// copyToClipboard copies text and reports the outcome; failures are warnings
+// on stderr and never fail the command.
+func (a *App) copyToClipboard(what, text string, notes io.Writer) {
+	if err := clipboard.Copy(text); err != nil {
+		fmt.Fprintf(a.stderr, "warning: unable to copy %s to clipboard: %v\n", what, err)
+		return
+	}
+	fmt.Fprintf(notes, "%s copied to clipboard\n", what)
+}
+
+// envTarget fills an empty provider or model flag from ASK_PROVIDER and
+// ASK_MODEL, giving the precedence flag > env > config.
+func (a *App) envTarget(provider, model string) (string, string) {
+	if strings.TrimSpace(provider) == "" {
+		provider = a.provider
+	}
+	if strings.TrimSpace(provider) == "" {
+		provider = os.Getenv("ASK_PROVIDER")
+	}
+	if strings.TrimSpace(model) == "" {
+		model = os.Getenv("ASK_MODEL")
+	}
+	return provider, model
+}
+
+// resolveAskTarget resolves the provider and model for a request, falling back
+// to config defaults and auto-selecting a model when none is configured.
+func (a *App) resolveAskTarget(providerInput string, modelInput string) (string, string, providers.Client, error) {
+	provider := strings.ToLower(strings.TrimSpace(providerInput))
+	if provider == "" {
+		provider = a.defaultProvider()
+	}
+	if provider == "" {
+		return "", "", nil, fmt.Errorf("no default provider set; run `ask provider set <name>` or pass --provider")
+	}
+	if !a.cfg.ProviderExists(provider) {
+		return "", "", nil, fmt.Errorf("provider %q is not configured", provider)
+	}
+
+	model := strings.TrimSpace(modelInput)
+	if model == "" {
+		model = strings.TrimSpace(a.cfg.GetModel(provider))
+	}
+	model = a.cfg.ResolveModel(provider, model)
+
+	if err := a.checkCredentials(provider); err != nil {
+		return "", "", nil, err
+	}
+	client, err := a.newClient(provider)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if model == "" {
+		models, listErr := a.fetchModels(context.Background(), provider, client, false)
+		if listErr != nil {
+			return "", "", nil, fmt.Errorf("no model set for provider %q and unable to list models: %w", provider, listErr)
+		}
+		if len(models) == 0 {
+			return "", "", nil, fmt.Errorf("no models available for provider %q", provider)
+		}
+		model = selectDefaultModel(models)
+		if err := a.updateConfig(func(c *config.Config) error { c.SetModel(provider, model); return nil }); err != nil {
+			return "", "", nil, err
+		}
+	}
+	return provider, model, client, nil
+}
+
+// checkCredentials fails early with setup instructions when provider needs an
+// API key and none resolves, instead of letting the HTTP call fail later.
+func (a *App) checkCredentials(provider string) error {
+	if !providers.RequiresAPIKey(provider) || a.cfg.ResolveAPIKey(provider) != "" {
+		return nil
+	}
+	if envVar := a.apiKeyEnv(provider); envVar != "" {
+		return fmt.Errorf("no API key for %s; set %s or run 'ask key set %s'", provider, envVar, provider)
+	}
+	return fmt.Errorf("no API key for %s; run 'ask key set %s'", provider, provider)
+}
+
+// buildSystemPrompt returns the built-in system prompt, or system followed by
+// the JSON response contract when a custom prompt is configured. codeFences
+// lets Markdown answers use fenced code blocks. withGit adds the branch and
+// status summary when cwd is inside a git repository.
+func buildSystemPrompt(system, shell string, renderMarkdown, codeFences, withGit bool) string {
+	cwd, _ := os.Getwd()
+	var prompt string
+	if strings.TrimSpace(system) != "" {
+		prompt = assistant.BuildCustomPrompt(system, runner.ResolveShell(shell), cwd, runtime.GOOS, renderMarkdown, codeFences)
+	} else {
+		prompt = assistant.BuildPrompt(runner.ResolveShell(shell), cwd, runtime.GOOS, renderMarkdown, codeFences)
+	}
+	return addGitContext(prompt, cwd, withGit)
+}
+
+// buildRawSystemPrompt is buildSystemPrompt for --raw: the instructions and
+// terminal context without the JSON response contract.
+func buildRawSystemPrompt(system, shell string, withGit bool) string {
+	cwd, _ := os.Getwd()
+	return addGitContext(assistant.BuildRawPrompt(system, runner.ResolveShell(shell), cwd, runtime.GOOS), cwd, withGit)
+}
+
+// addGitContext appends the git line for cwd to prompt when withGit is set
+// and cwd is inside a repository.
+func addGitContext(prompt, cwd string, withGit bool) string {
+	if withGit {
+		if git, ok := gitContext(cwd); ok {
+			prompt = assistant.WithGitContext(prompt, git)
+		}
+	}
+	return prompt
+}
+
 func (a *App) newClient(provider string) (providers.Client, error) {
 	provider = strings.ToLower(strings.TrimSpace(provider))
 	apiKey := a.cfg.ResolveAPIKey(provider)
 	if custom, ok := a.cfg.CustomProviders[provider]; ok {
+		basicUser, basicPassword := a.cfg.ResolveBasicAuth(provider)
 		settings := providers.OpenAICompatibleSettings{
-			Name:       provider,
-			ModelsPath: custom.ModelsPath,
-			ChatPath:   custom.ChatPath,
-			AuthHeader: custom.AuthHeader,
-			AuthPrefix: custom.AuthPrefix,
+			Name:              provider,
+			ModelsPath:        custom.ModelsPath,
+			ChatPath:          custom.ChatPath,
+			ModelsResponseKey: custom.ModelsResponseKey,
+			ModelIDField:      custom.ModelIDField,
+			AuthHeader:        custom.AuthHeader,
+			AuthPrefix:        custom.AuthPrefix,
+			AuthQueryParam:    custom.AuthQueryParam,
+			BasicUser:         basicUser,
+			BasicPassword:     basicPassword,
 		}
 		return providers.NewOpenAICompatible(settings, providers.ClientOptions{
-			APIKey:  apiKey,
-			BaseURL: custom.BaseURL,
-			Headers: custom.Headers,
+			APIKey:          apiKey,
+			BaseURL:         custom.BaseURL,
+			Headers:         custom.Headers,
+			ExtraBody:       custom.ExtraBody,
+			Retries:         a.cfg.Retries,
+			Timeout:         a.requestTimeout(provider),
+			ProxyURL:        custom.ProxyURL,
+			UserAgent:       a.resolveUserAgent(),
+			Logger:          a.providerTrace(provider),
+			CapabilityCache: modelcache.CapabilitiesPath(a.cfgPath),
 		})
 	}
 	return providers.New(provider, providers.ClientOptions{
-		APIKey:  apiKey,
-		BaseURL: a.cfg.ResolveBaseURL(provider),
+		APIKey:          apiKey,
+		BaseURL:         a.cfg.ResolveBaseURL(provider),
+		Retries:         a.cfg.Retries,
+		APIVersion:      a.cfg.Providers[provider].APIVersion,
+		Headers:         a.cfg.Providers[provider].Headers,
+		ExtraBody:       a.cfg.GetExtraBody(provider),
+		Region:          a.cfg.ResolveRegion(provider),
+		CloudProject:    a.cfg.ResolveProject(provider),
+		TokenCommand:    a.cfg.Providers[provider].TokenCommand,
+		Timeout:         a.requestTimeout(provider),
+		Organization:    a.cfg.OpenAIOrg,
+		Project:         a.cfg.OpenAIProject,
+		ProxyURL:        a.cfg.GetProxyURL(provider),
+		UserAgent:       a.resolveUserAgent(),
+		Logger:          a.providerTrace(provider),
+		CapabilityCache: modelcache.CapabilitiesPath(a.cfgPath),
 	})
 }
 
-func (a *App) saveConfig() error {
-	return config.Save(a.cfgPath, a.cfg)
+// resolveUserAgent returns the User-Agent for provider requests: --user-agent,
+// then config user_agent. "" leaves the providers default of ask/<version>.
+func (a *App) resolveUserAgent() string {
+	if a.userAgent != "" {
+		return a.userAgent
+	}
+	return strings.TrimSpace(a.cfg.UserAgent)
+}
+
+// enableTrace turns on --verbose tracing to stderr.
+func (a *App) enableTrace() {
+	if a.trace != nil {
+		return
+	}
+	a.trace = slog.New(slog.NewTextHandler(a.stderr, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && (attr.Key == slog.TimeKey || attr.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+}
+
+// tracef logs a trace event when --verbose is set.
+func (a *App) tracef(msg string, args ...any) {
+	if a.trace != nil {
+		a.trace.Debug(msg, args...)
+	}
+}
+
+// providerTrace returns the logger passed to provider clients, or nil when
+// tracing is off.
+func (a *App) providerTrace(provider string) *slog.Logger {
+	if a.trace == nil {
+		return nil
+	}
+	return a.trace.With("provider", provider)
+}
+
+// requestTimeout returns the --timeout override when set, otherwise the
+// provider's timeout_seconds, or 0 when neither is configured.
+func (a *App) requestTimeout(provider string) time.Duration {
+	if a.timeout > 0 {
+		return a.timeout
+	}
+	return a.cfg.GetTimeout(provider)
+}
+
+// askTimeout returns the deadline for a single ask request to provider.
+func (a *App) askTimeout(provider string) time.Duration {
+	if timeout := a.requestTimeout(provider); timeout > 0 {
+		return timeout
+	}
+	return defaultAskTimeout
+}
+
+// updateConfig applies fn to a fresh copy of the config file under its lock
+// and saves it, then applies fn to a.cfg so the running command sees its own
+// change. Prompts and network calls belong before the call: the lock is held
+// only for the update, and a change made by a concurrent ask is not
+// overwritten with the copy loaded at startup.
+func (a *App) updateConfig(fn func(*config.Config) error) error {
+	if err := config.Update(a.cfgPath, fn); err != nil {
+		return err
+	}
+	return fn(a.cfg)
+}
+
+// interruptContext returns a context that is cancelled by the first Ctrl+C.
+// Signal handling is released when that happens, so a second Ctrl+C
+// terminates the process as usual.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// startSpinner shows a progress spinner on stderr while a network call runs.
+// It stays off with --no-spinner, --no-color, NO_COLOR, or when stdout or
+// stderr is not a terminal, so piped output never carries spinner frames.
+func (a *App) startSpinner(label string) func() {
+	enabled := !a.noSpinner && !a.noColor && os.Getenv("NO_COLOR") == "" &&
+		isTerminalWriter(a.stdout) && isTerminalWriter(a.stderr)
+	return startSpinner(enabled, a.stderr, label)
+}
+
+// renderWidth returns the markdown wrap width: override when set, then a
+// positive COLUMNS, then the detected terminal width.
+func (a *App) renderWidth(override int) int {
+	if override > 0 {
+		return override
+	}
+	if columns, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && columns > 0 {
+		return columns
+	}
+	return terminalWidth(a.stdout)
 }
 
 func terminalWidth(w io.Writer) int {
@@ -311,43 +916,6 @@ func parseKV(input string) (string, string, error) {
 	return k, v, nil
 }
 
-func parseAssistantFallbackFromCodeBlock(text string) string {
-	candidate := strings.TrimSpace(text)
-	if candidate == "" {
-		return ""
-	}
-	start := strings.Index(candidate, "```")
-	if start < 0 {
-		for _, line := range strings.Split(candidate, "\n") {
-			trimmed := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmed, "$ ") {
-				return strings.TrimSpace(strings.TrimPrefix(trimmed, "$ "))
-			}
-		}
-		return ""
-	}
-	end := strings.Index(candidate[start+3:], "```")
-	if end < 0 {
-		return ""
-	}
-	block := candidate[start+3 : start+3+end]
-	block = strings.TrimSpace(block)
-	lines := strings.Split(block, "\n")
-	if len(lines) == 0 {
-		return ""
-	}
-	if strings.HasPrefix(lines[0], "bash") || strings.HasPrefix(lines[0], "sh") || strings.HasPrefix(lines[0], "zsh") {
-		lines = lines[1:]
-	}
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" {
-			return strings.TrimPrefix(trimmed, "$ ")
-		}
-	}
-	return ""
-}
-
 func fallbackAssistantResponse(text string) assistant.Response {
 	text = strings.TrimSpace(text)
 	if text == "" {
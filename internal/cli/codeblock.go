@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+)
+
+// shellFenceLanguages are info strings that mark a fenced block as commands.
+var shellFenceLanguages = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "shell": true, "console": true,
+	"shell-session": true, "fish": true, "powershell": true, "pwsh": true, "ps1": true,
+}
+
+// multiLineShellSyntax matches constructs whose lines must stay on separate
+// lines rather than be chained with &&.
+var multiLineShellSyntax = regexp.MustCompile(`(^|\s)(if|then|else|elif|fi|for|while|until|do|done|case|esac|function)(\s|;|$)|<<|[{}]\s*$`)
+
+// inlineFence matches a command wrapped in triple backticks on one line.
+var inlineFence = regexp.MustCompile("```(?:(?:sh|bash|zsh)\\s+)?([^`\n]+)```")
+
+type fencedBlock struct {
+	lang   string
+	body   string
+	nested bool // body contains fences of its own
+}
+
+// parseAssistantFallbackFromCodeBlock extracts a command from a response that
+// ignored the JSON contract. Blocks tagged as shell are preferred over
+// untagged ones, blocks in other languages are ignored, and without any
+// fence the first "$ " line is used.
+func parseAssistantFallbackFromCodeBlock(text string) string {
+	candidate := strings.TrimSpace(text)
+	if candidate == "" {
+		return ""
+	}
+	blocks := fencedBlocks(candidate)
+	if len(blocks) == 0 {
+		if m := inlineFence.FindStringSubmatch(candidate); m != nil {
+			return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(m[1]), "$ "))
+		}
+		for _, line := range strings.Split(candidate, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "$ ") {
+				return strings.TrimSpace(strings.TrimPrefix(trimmed, "$ "))
+			}
+		}
+		return ""
+	}
+	for _, block := range blocks {
+		if shellFenceLanguages[block.lang] {
+			if cmd := commandFromBlock(block.body); cmd != "" {
+				return cmd
+			}
+		}
+	}
+	for _, block := range blocks {
+		if block.lang == "" && !block.nested {
+			if cmd := commandFromBlock(block.body); cmd != "" {
+				return cmd
+			}
+		}
+	}
+	return ""
+}
+
+// fencedBlocks returns the fenced code blocks in text in document order,
+// followed by any blocks nested inside them. A fence closes only on a line of
+// the same character at least as long as the opener, so ```` can wrap ```.
+func fencedBlocks(text string) []fencedBlock {
+	var blocks []fencedBlock
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		fence, info, ok := openingFence(lines[i])
+		if !ok {
+			continue
+		}
+		var body []string
+		closed := false
+		for i++; i < len(lines); i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				closed = true
+				break
+			}
+			body = append(body, lines[i])
+		}
+		if !closed {
+			break
+		}
+		lang := ""
+		if fields := strings.Fields(info); len(fields) > 0 {
+			lang = strings.ToLower(fields[0])
+		}
+		content := strings.Join(body, "\n")
+		inner := fencedBlocks(content)
+		blocks = append(blocks, fencedBlock{lang: lang, body: content, nested: len(inner) > 0})
+		blocks = append(blocks, inner...)
+	}
+	return blocks
+}
+
+// openingFence reports whether line opens a fenced block, returning the fence
+// run and the info string after it.
+func openingFence(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 {
+			info := trimmed[n:]
+			if c == "`" && strings.Contains(info, "`") {
+				return "", "", false
+			}
+			return trimmed[:n], strings.TrimSpace(info), true
+		}
+	}
+	return "", "", false
+}
+
+// commandFromBlock turns a shell block into one command line. Prompt markers
+// are stripped; in transcripts that mark commands with "$ ", unmarked lines
+// are output and dropped. Comments and blank lines are skipped. Independent
+// commands are chained with &&, while continuations, heredocs, and control
+// structures keep their newlines.
+func commandFromBlock(body string) string {
+	lines := strings.Split(body, "\n")
+	prompted := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "$ ") {
+			prompted = true
+			break
+		}
+	}
+
+	var commands []string
+	keepNewlines := false
+	continuing := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "$ "):
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "$ "))
+		case prompted && !continuing:
+			continue
+		}
+		if trimmed == "" || (strings.HasPrefix(trimmed, "#") && !continuing) {
+			continue
+		}
+		if continuing || strings.HasSuffix(trimmed, "\\") || multiLineShellSyntax.MatchString(trimmed) {
+			keepNewlines = true
+		}
+		continuing = strings.HasSuffix(trimmed, "\\")
+		commands = append(commands, trimmed)
+	}
+	if keepNewlines {
+		return strings.Join(commands, "\n")
+	}
+	return strings.Join(commands, " && ")
+}
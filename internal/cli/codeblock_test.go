@@ -0,0 +1,73 @@
+package cli
+
+import "testing"
+
+func TestParseAssistantFallbackFromCodeBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "empty", text: "", want: ""},
+		{name: "prose only", text: "Use git log to see history.", want: ""},
+		{name: "dollar line without fences", text: "Try this:\n$ git log --oneline\nIt lists commits.", want: "git log --oneline"},
+		{name: "inline fence", text: "Run ```ls -la``` to list files.", want: "ls -la"},
+		{name: "untagged block", text: "Run:\n```\ndf -h\n```", want: "df -h"},
+		{name: "bash block with prompt", text: "```bash\n$ du -sh .\n```", want: "du -sh ."},
+		{
+			name: "shell block preferred over earlier untagged block",
+			text: "Output looks like:\n```\nFilesystem  Size\n```\nRun:\n```sh\ndf -h /\n```",
+			want: "df -h /",
+		},
+		{
+			name: "other languages ignored",
+			text: "```json\n{\"a\": 1}\n```\nThen:\n```zsh\njq .a file.json\n```",
+			want: "jq .a file.json",
+		},
+		{name: "only non-shell block", text: "```python\nprint('hi')\n```", want: ""},
+		{
+			name: "independent commands chained",
+			text: "```bash\n$ git fetch origin\n$ git rebase origin/main\n```",
+			want: "git fetch origin && git rebase origin/main",
+		},
+		{
+			name: "console transcript drops output",
+			text: "```console\n$ whoami\nroot\n$ id -u\n0\n```",
+			want: "whoami && id -u",
+		},
+		{
+			name: "comments and blank lines skipped",
+			text: "```sh\n# update packages\n\napt update\napt upgrade -y\n```",
+			want: "apt update && apt upgrade -y",
+		},
+		{
+			name: "line continuations keep newlines",
+			text: "```bash\ndocker run \\\n  -p 8080:80 \\\n  nginx\n```",
+			want: "docker run \\\n-p 8080:80 \\\nnginx",
+		},
+		{
+			name: "control structure keeps newlines",
+			text: "```bash\nfor f in *.log; do\n  gzip \"$f\"\ndone\n```",
+			want: "for f in *.log; do\ngzip \"$f\"\ndone",
+		},
+		{
+			name: "nested fences",
+			text: "````markdown\nSome docs:\n```bash\n$ make test\n```\n````",
+			want: "make test",
+		},
+		{
+			name: "untagged outer block with nested fence is not a command",
+			text: "````\nExample:\n```\nnot this\n```\n````\n```sh\nmake\n```",
+			want: "make",
+		},
+		{name: "tilde fence", text: "~~~sh\nuname -a\n~~~", want: "uname -a"},
+		{name: "unclosed fence", text: "```bash\nrm -rf build", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAssistantFallbackFromCodeBlock(tt.text); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+	"github.com/sasanktumpati/ask/internal/providers"
+	"github.com/sasanktumpati/ask/internal/render"
+)
+
+type compareOptions struct {
+	Providers   []string
+	Models      []string
+	Provider    string // provider for --models; "" uses the current provider
+	Timeout     time.Duration
+	NoMarkdown  bool
+	AsJSON      bool
+	NoSpinner   bool
+	Files       []string
+	Contexts    []string
+	Temperature *float64
+	MaxTokens   int
+}
+
+// compareResult is one target's outcome. Err is reported inline so a single
+// failing provider does not hide the others.
+type compareResult struct {
+	Provider  string
+	Model     string
+	Answer    string
+	Command   string
+	Usage     providers.Usage
+	Truncated bool
+	Elapsed   time.Duration
+	Err       error
+}
+
+func parseCompareArgs(args []string) (compareOptions, string, error) {
+	opts := compareOptions{}
+	showHelp := false
+	splitList := func(v string) []string {
+		var out []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+
+	rest, err := scanOptions(args, []optionSpec{
+		{Names: []string{"help", "h"}, TakesValue: false, Set: func(string) error { showHelp = true; return nil }},
+		{Names: []string{"providers"}, TakesValue: true, Set: func(v string) error { opts.Providers = append(opts.Providers, splitList(v)...); return nil }},
+		{Names: []string{"models"}, TakesValue: true, Set: func(v string) error { opts.Models = append(opts.Models, splitList(v)...); return nil }},
+		{Names: []string{"provider", "p"}, TakesValue: true, Set: func(v string) error { opts.Provider = strings.TrimSpace(v); return nil }},
+		{Names: []string{"timeout"}, TakesValue: true, Set: func(v string) error {
+			d, err := parseDuration(v)
+			if err != nil {
+				return fmt.Errorf("--timeout: %w", err)
+			}
+			opts.Timeout = d
+			return nil
+		}},
+		{Names: []string{"no-markdown"}, TakesValue: false, Set: func(string) error { opts.NoMarkdown = true; return nil }},
+		{Names: []string{"json"}, TakesValue: false, Set: func(string) error { opts.AsJSON = true; return nil }},
+		{Names: []string{"no-spinner"}, TakesValue: false, Set: func(string) error { opts.NoSpinner = true; return nil }},
+		{Names: []string{"file", "f"}, TakesValue: true, Set: func(v string) error { opts.Files = append(opts.Files, strings.TrimSpace(v)); return nil }},
+		{Names: []string{"context"}, TakesValue: true, Set: func(v string) error { opts.Contexts = append(opts.Contexts, v); return nil }},
+		{Names: []string{"temperature", "t"}, TakesValue: true, Set: func(v string) error {
+			t, err := parseTemperature(v)
+			if err != nil {
+				return err
+			}
+			opts.Temperature = &t
+			return nil
+		}},
+		{Names: []string{"max-tokens"}, TakesValue: true, Set: func(v string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || n <= 0 {
+				return usageError("--max-tokens must be a positive integer")
+			}
+			opts.MaxTokens = n
+			return nil
+		}},
+	})
+	if err != nil {
+		return opts, "", err
+	}
+	if showHelp {
+		return opts, "", errShowHelp
+	}
+
+	switch {
+	case len(opts.Providers) > 0 && len(opts.Models) > 0:
+		return opts, "", usageError("use either --providers or --models, not both")
+	case len(opts.Providers) > 0 && opts.Provider != "":
+		return opts, "", usageError("--provider selects the provider for --models; list providers in --providers instead")
+	case len(opts.Providers)+len(opts.Models) < 2:
+		return opts, "", usageError("ask compare \"question\" --providers <p1,p2,...> | [--provider <name>] --models <m1,m2,...>")
+	}
+
+	question := strings.TrimSpace(strings.Join(rest, " "))
+	if question == "" {
+		return opts, "", fmt.Errorf("question is required")
+	}
+	return opts, question, nil
+}
+
+func (a *App) runCompare(args []string) error {
+	opts, question, err := parseCompareArgs(args)
+	if err != nil {
+		if errors.Is(err, errShowHelp) {
+			printHelp(a.stdout, "compare", a.cfgPath)
+			return nil
+		}
+		return err
+	}
+
+	files, err := attachFiles(opts.Files, a.stderr)
+	if err != nil {
+		return err
+	}
+	contexts, files := redactAttachments(opts.Contexts, files, a.stderr)
+	question = composeQuestion(question, contexts, files)
+	a.timeout = opts.Timeout
+
+	type target struct{ provider, model string }
+	var targets []target
+	for _, name := range opts.Providers {
+		targets = append(targets, target{provider: name})
+	}
+	modelsProvider := opts.Provider
+	if modelsProvider == "" {
+		modelsProvider = a.defaultProvider()
+	}
+	for _, model := range opts.Models {
+		targets = append(targets, target{provider: modelsProvider, model: model})
+	}
+
+	// Targets are resolved up front so the shared deadline only covers the
+	// requests themselves.
+	results := make([]compareResult, len(targets))
+	clients := make([]providers.Client, len(targets))
+	timeout := a.timeout
+	for i, t := range targets {
+		provider, model, client, err := a.resolveAskTarget(t.provider, t.model)
+		if err != nil {
+			results[i] = compareResult{Provider: t.provider, Model: t.model, Err: err}
+			continue
+		}
+		results[i] = compareResult{Provider: provider, Model: model}
+		clients[i] = client
+		if a.timeout == 0 && a.askTimeout(provider) > timeout {
+			timeout = a.askTimeout(provider)
+		}
+	}
+	if timeout == 0 {
+		timeout = defaultAskTimeout
+	}
+
+	renderMarkdown := a.cfg.RenderMarkdown && !opts.NoMarkdown
+	prompt := buildSystemPrompt(a.cfg.SystemPrompt, a.cfg.Shell, renderMarkdown, a.cfg.AllowCodeFences, a.cfg.GitContext)
+
+	if opts.NoSpinner || opts.AsJSON {
+		a.noSpinner = true
+	}
+	interrupt, stopInterrupt := interruptContext()
+	defer stopInterrupt()
+	ctx, cancel := context.WithTimeout(interrupt, timeout)
+	defer cancel()
+	stopSpinner := a.startSpinner(fmt.Sprintf("Asking %d models", len(targets)))
+
+	var wg sync.WaitGroup
+	for i := range results {
+		if clients[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(r *compareResult, client providers.Client) {
+			defer wg.Done()
+			maxTokens := opts.MaxTokens
+			if maxTokens == 0 {
+				maxTokens = a.cfg.GetMaxTokens(r.Provider)
+			}
+			start := time.Now()
+			resp, err := client.Ask(ctx, providers.AskRequest{
+				Model:       r.Model,
+				Prompt:      prompt,
+				Question:    question,
+				ExpectJSON:  true,
+				JSONSchema:  a.responseSchema(false),
+				Temperature: opts.Temperature,
+				MaxTokens:   maxTokens,
+			})
+			r.Elapsed = time.Since(start)
+			if err != nil {
+				r.Err = err
+				return
+			}
+			parsed, parseErr := assistant.Parse(resp.Text)
+			if parseErr != nil {
+				parsed = fallbackAssistantResponse(resp.Text)
+			}
+			r.Answer, r.Usage, r.Truncated = parsed.Answer, resp.Usage, resp.Truncated
+			if parsed.HasCommand() {
+				r.Command = parsed.Command
+			}
+		}(&results[i], clients[i])
+	}
+	wg.Wait()
+	stopSpinner()
+	if interrupt.Err() != nil {
+		fmt.Fprintln(a.stderr, "cancelled")
+		return &ExitError{Code: 130}
+	}
+
+	if opts.AsJSON {
+		err = a.printCompareJSON(results)
+	} else {
+		a.printCompare(results, renderMarkdown)
+	}
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("all %d requests failed", len(results))
+}
+
+func (r compareResult) label() string {
+	if r.Model == "" {
+		return r.Provider
+	}
+	return r.Provider + "/" + r.Model
+}
+
+func (a *App) printCompare(results []compareResult, renderMarkdown bool) {
+	width := a.renderWidth(0)
+	theme := a.markdownTheme("")
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(a.stdout)
+		}
+		header := "== " + r.label()
+		if r.Elapsed > 0 {
+			header += fmt.Sprintf(" (%s)", r.Elapsed.Round(100*time.Millisecond))
+		}
+		fmt.Fprintln(a.stdout, header+" ==")
+		if r.Err != nil {
+			fmt.Fprintf(a.stdout, "error: %v\n", r.Err)
+			continue
+		}
+		if r.Answer != "" {
+			fmt.Fprintln(a.stdout, render.Markdown(r.Answer, width, renderMarkdown, theme))
+		}
+		if r.Command != "" {
+			fmt.Fprintf(a.stdout, "$ %s\n", r.Command)
+		}
+		if !r.Usage.IsZero() {
+			fmt.Fprintf(a.stdout, "tokens: %d in / %d out\n", r.Usage.PromptTokens, r.Usage.CompletionTokens)
+		}
+		if r.Truncated {
+			fmt.Fprintln(a.stdout, "warning: response truncated at max tokens; raise --max-tokens")
+		}
+	}
+}
+
+func (a *App) printCompareJSON(results []compareResult) error {
+	out := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		entry := map[string]any{
+			"provider":   r.Provider,
+			"model":      r.Model,
+			"elapsed_ms": r.Elapsed.Milliseconds(),
+		}
+		if r.Err != nil {
+			entry["error"] = r.Err.Error()
+		} else {
+			entry["answer"] = r.Answer
+			entry["command"] = r.Command
+			entry["usage"] = r.Usage
+			entry["truncated"] = r.Truncated
+		}
+		out = append(out, entry)
+	}
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
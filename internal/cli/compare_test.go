@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestCompareRunsConcurrentlyAndKeepsOrder(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"slow answer","command":"ls"}`}}},
+		})
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"fast answer","command":""}`}}},
+		})
+	}))
+	defer fast.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"bad model"}}`, http.StatusBadRequest)
+	}))
+	defer broken.Close()
+
+	cfg := config.DefaultConfig()
+	for name, url := range map[string]string{"slow": slow.URL, "fast": fast.URL, "broken": broken.URL} {
+		if err := cfg.AddCustomProvider(name, config.OpenAICompatibleProvider{BaseURL: url, Model: name + "-model"}); err != nil {
+			t.Fatalf("AddCustomProvider error = %v", err)
+		}
+	}
+	cfg.Retries = -1
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: cfg}
+
+	if err := app.runCompare([]string{"list files", "--providers", "slow,broken,fast", "--no-markdown"}); err != nil {
+		t.Fatalf("runCompare error = %v", err)
+	}
+	out := stdout.String()
+	slowAt := strings.Index(out, "== slow/slow-model")
+	brokenAt := strings.Index(out, "== broken/broken-model")
+	fastAt := strings.Index(out, "== fast/fast-model")
+	if slowAt < 0 || brokenAt < slowAt || fastAt < brokenAt {
+		t.Fatalf("output order wrong:\n%s", out)
+	}
+	for _, want := range []string{"slow answer\n$ ls\n", "error: ", "bad model", "fast answer\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompareJSONAndAllFailed(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, name := range []string{"a", "b"} {
+		if err := cfg.AddCustomProvider(name, config.OpenAICompatibleProvider{BaseURL: "http://127.0.0.1:1", Model: "m"}); err != nil {
+			t.Fatalf("AddCustomProvider error = %v", err)
+		}
+	}
+	cfg.Retries = -1
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: cfg}
+
+	err := app.runCompare([]string{"q", "--providers", "a,b", "--json"})
+	if err == nil || !strings.Contains(err.Error(), "all 2 requests failed") {
+		t.Fatalf("err = %v", err)
+	}
+	var results []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("decode JSON: %v\n%s", err, stdout.String())
+	}
+	if len(results) != 2 || results[0]["provider"] != "a" || results[1]["error"] == nil {
+		t.Fatalf("results = %v", results)
+	}
+}
+
+func TestParseCompareArgs(t *testing.T) {
+	opts, question, err := parseCompareArgs([]string{"-p", "ollama", "--models", "llama3.2, qwen2.5", "why"})
+	if err != nil || question != "why" || opts.Provider != "ollama" || len(opts.Models) != 2 || opts.Models[1] != "qwen2.5" {
+		t.Fatalf("opts = %+v, question = %q, err = %v", opts, question, err)
+	}
+	for _, args := range [][]string{
+		{"--providers", "openai", "q"},
+		{"--providers", "openai,anthropic", "--models", "x,y", "q"},
+		{"--providers", "openai,anthropic"},
+	} {
+		if _, _, err := parseCompareArgs(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
 
 	"github.com/sasanktumpati/ask/internal/config"
@@ -35,6 +38,87 @@ func (a *App) runConfig(args []string) error {
 		}
 		fmt.Fprintln(a.stdout, config.TemplatePathForConfig(a.cfgPath))
 		return nil
+	case "edit":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+		}
+		return a.configEdit()
+	case "validate":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+		}
+		return a.configValidate()
+	case "export":
+		if a.showTopicHelpIfAnyFlagRequested("config", args, 1) {
+			return nil
+		}
+		redact := false
+		rest, err := scanOptions(args[1:], []optionSpec{
+			{Names: []string{"redact"}, TakesValue: false, Set: func(string) error { redact = true; return nil }},
+		})
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+		}
+		return a.configExport(redact)
+	case "import":
+		if a.showTopicHelpIfAnyFlagRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) != 2 {
+			return usageError("ask config import <path>")
+		}
+		return a.configImport(strings.TrimSpace(args[1]))
+	case "get":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) != 2 {
+			return usageError("ask config get <key>")
+		}
+		return a.configGet(strings.TrimSpace(args[1]))
+	case "set":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) != 3 {
+			return usageError("ask config set <key> <value>")
+		}
+		return a.configSet(strings.TrimSpace(args[1]), args[2])
+	case "schema":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+		}
+		buf, err := config.Schema()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.stdout, string(buf))
+		return nil
+	case "restore":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+		}
+		return a.configRestore()
+	case "profile", "profiles":
+		if a.showTopicHelpIfRequested("config", args, 1) {
+			return nil
+		}
+		return a.runConfigProfile(args[1:])
 	default:
 		return unknownSubcommand("config", sub)
 	}
@@ -53,3 +137,143 @@ func (a *App) configShow() error {
 	}
 	return nil
 }
+
+// configEdit opens the config file in the user's editor and reloads it once
+// the editor exits, creating the file from defaults first when missing.
+func (a *App) configEdit() error {
+	if _, err := os.Stat(a.cfgPath); errors.Is(err, os.ErrNotExist) {
+		if err := config.SaveLocked(a.cfgPath, config.DefaultConfig()); err != nil {
+			return err
+		}
+	}
+
+	editor := editorCommand(runtime.GOOS)
+	cmd := exec.Command(editor[0], append(editor[1:], a.cfgPath)...)
+	if stdin, ok := a.stdin.(*os.File); ok {
+		cmd.Stdin = stdin
+	} else {
+		cmd.Stdin = os.Stdin
+	}
+	cmd.Stdout = a.stdout
+	cmd.Stderr = a.stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run editor %s: %w", editor[0], err)
+	}
+
+	cfg, err := config.Load(a.cfgPath)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %w; run `ask config edit` to fix it", a.cfgPath, err)
+	}
+	a.cfg = cfg
+	return a.configValidate()
+}
+
+// configValidate checks the config file for unknown keys and semantic
+// problems, printing each finding and failing when any are found.
+func (a *App) configValidate() error {
+	buf, err := os.ReadFile(a.cfgPath)
+	if err != nil {
+		return err
+	}
+	problems, err := config.UnknownKeys(buf)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %w", a.cfgPath, err)
+	}
+	cfg, err := config.Load(a.cfgPath)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %w", a.cfgPath, err)
+	}
+	problems = append(problems, cfg.Validate()...)
+	if len(problems) == 0 {
+		fmt.Fprintf(a.stdout, "config OK: %s\n", a.cfgPath)
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(a.stdout, "- %v\n", problem)
+	}
+	return fmt.Errorf("config has %d problem(s): %s", len(problems), a.cfgPath)
+}
+
+// editorCommand returns the editor from $VISUAL or $EDITOR split into
+// arguments, defaulting to vi (notepad on Windows).
+func editorCommand(goos string) []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if goos == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func (a *App) configExport(redact bool) error {
+	var mask func(string) string
+	if redact {
+		mask = maskForShow
+	}
+	buf, err := a.cfg.Export(mask)
+	if err != nil {
+		return err
+	}
+	_, err = a.stdout.Write(buf)
+	return err
+}
+
+// configGet prints one scalar config value; secrets are masked.
+func (a *App) configGet(key string) error {
+	value, err := a.cfg.Get(key)
+	if err != nil {
+		return err
+	}
+	if config.IsSecretField(key) {
+		value = maskForShow(value)
+	}
+	fmt.Fprintln(a.stdout, value)
+	return nil
+}
+
+func (a *App) configSet(key, value string) error {
+	if err := a.updateConfig(func(c *config.Config) error { return c.Set(key, value) }); err != nil {
+		return err
+	}
+	updated, _ := a.cfg.Get(key)
+	fmt.Fprintf(a.stdout, "set %s to %s\n", key, updated)
+	return nil
+}
+
+// configRestore swaps config.json with the backup written by the last save
+// and reloads it.
+func (a *App) configRestore() error {
+	unlock, err := config.Lock(a.cfgPath, config.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := config.RestoreBackup(a.cfgPath); err != nil {
+		if errors.Is(err, config.ErrNoBackup) {
+			return fmt.Errorf("no backup to restore at %s", config.BackupPathForConfig(a.cfgPath))
+		}
+		return err
+	}
+	cfg, err := config.Load(a.cfgPath)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %w", a.cfgPath, err)
+	}
+	a.cfg = cfg
+	fmt.Fprintf(a.stdout, "restored config from %s\n", config.BackupPathForConfig(a.cfgPath))
+	return nil
+}
+
+func (a *App) configImport(path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read import: %w", err)
+	}
+	if err := a.updateConfig(func(c *config.Config) error { return c.Import(buf) }); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "imported config from %s\n", path)
+	return nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if got := editorCommand("linux"); strings.Join(got, " ") != "code --wait" {
+		t.Fatalf("editorCommand = %v", got)
+	}
+	t.Setenv("VISUAL", "nano")
+	if got := editorCommand("linux"); strings.Join(got, " ") != "nano" {
+		t.Fatalf("VISUAL should win, got %v", got)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := editorCommand("windows"); got[0] != "notepad" {
+		t.Fatalf("windows default = %v", got)
+	}
+	if got := editorCommand("darwin"); got[0] != "vi" {
+		t.Fatalf("default = %v", got)
+	}
+}
+
+func TestConfigEditReportsInvalidJSON(t *testing.T) {
+	if _, err := os.Stat("/bin/true"); err != nil {
+		t.Skip("no /bin/true")
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "/bin/true")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: path, cfg: config.DefaultConfig()}
+	err := app.configEdit()
+	if err == nil || !strings.Contains(err.Error(), "is invalid") {
+		t.Fatalf("configEdit error = %v, want invalid config error", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove config: %v", err)
+	}
+	if err := app.configEdit(); err != nil {
+		t.Fatalf("configEdit on missing file error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "config OK") {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+}
+
+func TestConfigSetKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfgPath: path, cfg: config.DefaultConfig()}
+
+	// Another ask saves after this one loaded its copy.
+	if err := config.Update(path, func(c *config.Config) error { c.SetAPIKey("openai", "sk-other-process"); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.runConfig([]string{"set", "providers.openai.model", "gpt-5"}); err != nil {
+		t.Fatalf("config set error = %v", err)
+	}
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.GetModel("openai") != "gpt-5" || saved.Providers["openai"].APIKey != "sk-other-process" {
+		t.Fatalf("saved openai = %+v, want both the model and the concurrent key", saved.Providers["openai"])
+	}
+	if _, err := os.Stat(config.LockPathForConfig(path)); !os.IsNotExist(err) {
+		t.Fatalf("lock file left behind: %v", err)
+	}
+}
+
+func TestConfigGetSetCommands(t *testing.T) {
+	var stdout bytes.Buffer
+	cfg := config.DefaultConfig()
+	cfg.SetAPIKey("openai", "sk-test-secret-1234")
+	path := filepath.Join(t.TempDir(), "config.json")
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: path, cfg: cfg}
+
+	if err := app.runConfig([]string{"set", "providers.openai.model", "gpt-5"}); err != nil {
+		t.Fatalf("config set error = %v", err)
+	}
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.GetModel("openai") != "gpt-5" {
+		t.Fatalf("saved model = %q", saved.GetModel("openai"))
+	}
+
+	stdout.Reset()
+	if err := app.runConfig([]string{"get", "providers.openai.model"}); err != nil || stdout.String() != "gpt-5\n" {
+		t.Fatalf("config get = %q, %v", stdout.String(), err)
+	}
+	stdout.Reset()
+	if err := app.runConfig([]string{"get", "providers.openai.api_key"}); err != nil || strings.Contains(stdout.String(), "secret") {
+		t.Fatalf("config get api_key = %q, %v; want masked", stdout.String(), err)
+	}
+}
+
+func TestConfigRestoreRepairsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.DefaultConfig()
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Shell = "zsh"
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{bad"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := Run([]string{"--config", path, "config", "restore"}, nil, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("config restore error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "restored config from") {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+	if _, err := config.Load(path); err != nil {
+		t.Fatalf("restored config does not load: %v", err)
+	}
+}
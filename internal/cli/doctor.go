@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+// doctorOllamaHint is the remediation for an unreachable Ollama server.
+const doctorOllamaHint = "start Ollama with `ollama serve`, or point OLLAMA_HOST at the server"
+
+// checklist prints doctor results as ✓/✗ lines with a fix under each
+// failure.
+type checklist struct {
+	a      *App
+	failed int
+}
+
+func (c *checklist) pass(format string, args ...any) {
+	mark := "✓"
+	if c.a.colorEnabled() {
+		mark = styleGreen + mark + styleReset
+	}
+	fmt.Fprintf(c.a.stdout, "%s %s\n", mark, fmt.Sprintf(format, args...))
+}
+
+func (c *checklist) fail(fix, format string, args ...any) {
+	c.failed++
+	mark := "✗"
+	if c.a.colorEnabled() {
+		mark = styleRed + mark + styleReset
+	}
+	fmt.Fprintf(c.a.stdout, "%s %s\n", mark, fmt.Sprintf(format, args...))
+	if fix != "" {
+		fmt.Fprintf(c.a.stdout, "    fix: %s\n", fix)
+	}
+}
+
+func (a *App) runDoctor(args []string) error {
+	if a.showTopicHelpIfAnyFlagRequested("doctor", args, 0) {
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	c := &checklist{a: a}
+	if !a.doctorConfig(c) {
+		return c.result()
+	}
+
+	provider, _ := a.envTarget("", "")
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		provider = a.defaultProvider()
+	}
+	switch {
+	case provider == "":
+		c.fail("run `ask provider set <name>` (see `ask provider list`)", "no current provider set")
+		return c.result()
+	case !a.cfg.ProviderExists(provider):
+		c.fail("run `ask provider list` and pick a configured provider", "provider %q is not configured", provider)
+		return c.result()
+	}
+	c.pass("current provider: %s", provider)
+
+	_, source := a.cfg.ResolveAPIKeySource(provider)
+	keyMissing := false
+	switch {
+	case source != "":
+		c.pass("api key: found in %s", source)
+	case !providers.RequiresAPIKey(provider):
+		c.pass("api key: not required for %s", provider)
+	default:
+		err := a.checkCredentials(provider)
+		fix, _ := strings.CutPrefix(err.Error(), fmt.Sprintf("no API key for %s; ", provider))
+		c.fail(fix, "no API key for %s", provider)
+		keyMissing = true
+	}
+
+	client, err := a.newClient(provider)
+	if err != nil {
+		c.fail(fmt.Sprintf("set it with `ask config set %s <url>`", baseURLKey(a.cfg, provider)), "provider settings: %v", err)
+		return c.result()
+	}
+	if base := a.cfg.ResolveBaseURL(provider); base != "" {
+		c.pass("base url: %s", base)
+	}
+
+	if model := a.cfg.GetModel(provider); model != "" {
+		c.pass("model: %s", model)
+	} else {
+		c.pass("model: none set; ask picks one on first use (choose with `ask models select`)")
+	}
+
+	// Without a key the probe can only fail authentication, so skip it.
+	if keyMissing {
+		return c.result()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.askTimeout(provider))
+	defer cancel()
+	stopSpinner := a.startSpinner("Contacting " + provider)
+	models, err := client.ListModels(ctx)
+	stopSpinner()
+	switch {
+	case errors.Is(err, providers.ErrListUnsupported):
+		c.pass("list models: not listable for %s; skipped", provider)
+	case err != nil:
+		c.fail(doctorProbeHint(provider, err), "list models: %v", a.explainError(err))
+	default:
+		c.pass("list models: %d available", len(models))
+	}
+	return c.result()
+}
+
+// doctorConfig checks that the config file parses, has no unknown keys and
+// passes Validate. It reports whether the remaining checks can run.
+func (a *App) doctorConfig(c *checklist) bool {
+	buf, err := os.ReadFile(a.cfgPath)
+	if err != nil {
+		c.fail("check the path and permissions, or pass --config", "read config: %v", err)
+		return false
+	}
+	unknown, err := config.UnknownKeys(buf)
+	if err == nil {
+		_, err = config.Load(a.cfgPath)
+	}
+	if err != nil {
+		c.fail("run `ask config edit` to repair it, or `ask config restore` to roll back", "%s is invalid: %v", a.cfgPath, err)
+		return false
+	}
+	c.pass("config readable: %s", a.cfgPath)
+
+	problems := append(unknown, a.cfg.Validate()...)
+	if len(problems) == 0 {
+		c.pass("config valid")
+		return true
+	}
+	for _, problem := range problems {
+		c.fail("", "config: %v", problem)
+	}
+	fmt.Fprintln(a.stdout, "    fix: edit the entries above with `ask config edit`")
+	return true
+}
+
+func (c *checklist) result() error {
+	if c.failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d check(s) failed", c.failed)
+}
+
+// doctorProbeHint suggests a fix for a failed model-list probe.
+func doctorProbeHint(provider string, err error) string {
+	var httpErr *providers.HTTPError
+	switch {
+	case errors.As(err, &httpErr) && httpErr.IsAuth():
+		return fmt.Sprintf("check the key with `ask key show %s` and replace it with `ask key set %s`", provider, provider)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "the provider did not answer in time; check your network or raise timeout_seconds"
+	case provider == "ollama":
+		return doctorOllamaHint
+	default:
+		return "check the base url and your network; rerun with --verbose to see the request"
+	}
+}
+
+// baseURLKey returns the config key holding provider's base URL.
+func baseURLKey(cfg *config.Config, provider string) string {
+	if _, ok := cfg.CustomProviders[provider]; ok {
+		return "custom_providers." + provider + ".base_url"
+	}
+	return "providers." + provider + ".base_url"
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestDoctorPassesForWorkingProvider(t *testing.T) {
+	t.Setenv("ASK_PROVIDER", "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "m1"}, {"id": "m2"}}})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("local", config.OpenAICompatibleProvider{BaseURL: server.URL, Model: "m1"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.CurrentProvider = "local"
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: path, cfg: cfg}
+
+	if err := app.runDoctor(nil); err != nil {
+		t.Fatalf("runDoctor error = %v\n%s", err, stdout.String())
+	}
+	for _, want := range []string{"✓ config valid", "✓ current provider: local", "✓ model: m1", "✓ list models: 2 available"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "✗") {
+		t.Fatalf("unexpected failure:\n%s", stdout.String())
+	}
+}
+
+func TestDoctorReportsMissingProviderAndKey(t *testing.T) {
+	t.Setenv("ASK_PROVIDER", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.DefaultConfig()
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: path, cfg: cfg}
+
+	err := app.runDoctor(nil)
+	if err == nil || !strings.Contains(stdout.String(), "✗ no current provider set") || !strings.Contains(stdout.String(), "ask provider set") {
+		t.Fatalf("err = %v, output:\n%s", err, stdout.String())
+	}
+
+	stdout.Reset()
+	app.provider = "openai"
+	if err := app.runDoctor(nil); err == nil {
+		t.Fatal("expected doctor to fail without an API key")
+	}
+	if !strings.Contains(stdout.String(), "✗ no API key for openai") || !strings.Contains(stdout.String(), "fix: set OPENAI_API_KEY") {
+		t.Fatalf("output:\n%s", stdout.String())
+	}
+}
+
+func TestDoctorSkipsProbeForUnlistableProviders(t *testing.T) {
+	t.Setenv("ASK_PROVIDER", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.DefaultConfig()
+	cfg.SetAPIKey("azure", "az-key")
+	cfg.SetBaseURL("azure", "https://example.openai.azure.com")
+	cfg.SetModel("azure", "my-deployment")
+	cfg.SetAPIKey("vertex", "vx-token")
+	cfg.SetModel("vertex", "gemini-2.0-flash")
+	vertex := cfg.Providers["vertex"]
+	vertex.Project = "my-project"
+	cfg.Providers["vertex"] = vertex
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, provider := range []string{"azure", "vertex"} {
+		var stdout bytes.Buffer
+		app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: path, cfg: cfg, provider: provider}
+		if err := app.runDoctor(nil); err != nil {
+			t.Fatalf("%s: runDoctor error = %v\n%s", provider, err, stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "✓ list models: not listable for "+provider) {
+			t.Fatalf("%s output:\n%s", provider, stdout.String())
+		}
+	}
+}
+
+func TestDoctorSuggestedRestoreRepairsInvalidConfig(t *testing.T) {
+	t.Setenv("ASK_PROVIDER", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.DefaultConfig()
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Shell = "zsh"
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{bad"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := Run([]string{"--config", path, "doctor"}, nil, &stdout, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected doctor to fail on an invalid config:\n%s", stdout.String())
+	}
+	const suggestion = "ask config restore"
+	if !strings.Contains(stdout.String(), "`"+suggestion+"`") {
+		t.Fatalf("doctor did not suggest %q:\n%s", suggestion, stdout.String())
+	}
+
+	args := append([]string{"--config", path}, strings.Fields(suggestion)[1:]...)
+	if err := Run(args, nil, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("suggested %q failed: %v", suggestion, err)
+	}
+	stdout.Reset()
+	_ = Run([]string{"--config", path, "doctor"}, nil, &stdout, &bytes.Buffer{})
+	if !strings.Contains(stdout.String(), "✓ config readable") {
+		t.Fatalf("config still invalid after restore:\n%s", stdout.String())
+	}
+}
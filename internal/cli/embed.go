@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+type embedOptions struct {
+	Provider string
+	Model    string
+	Output   string
+	Timeout  time.Duration
+}
+
+func parseEmbedArgs(args []string) (embedOptions, string, error) {
+	opts := embedOptions{}
+	showHelp := false
+
+	rest, err := scanOptions(args, []optionSpec{
+		{Names: []string{"help", "h"}, TakesValue: false, Set: func(string) error { showHelp = true; return nil }},
+		{Names: []string{"provider", "p"}, TakesValue: true, Set: func(v string) error { opts.Provider = strings.TrimSpace(v); return nil }},
+		{Names: []string{"model", "m"}, TakesValue: true, Set: func(v string) error { opts.Model = strings.TrimSpace(v); return nil }},
+		{Names: []string{"output", "o"}, TakesValue: true, Set: func(v string) error {
+			if opts.Output = strings.TrimSpace(v); opts.Output == "" {
+				return usageError("--output requires a non-empty path")
+			}
+			return nil
+		}},
+		{Names: []string{"timeout"}, TakesValue: true, Set: func(v string) error {
+			d, err := parseDuration(v)
+			if err != nil {
+				return fmt.Errorf("--timeout: %w", err)
+			}
+			opts.Timeout = d
+			return nil
+		}},
+	})
+	if err != nil {
+		return opts, "", err
+	}
+	if showHelp {
+		return opts, "", errShowHelp
+	}
+	if opts.Model == "" {
+		return opts, "", usageError("ask embed --model <id> \"text\"")
+	}
+	text := strings.TrimSpace(strings.Join(rest, " "))
+	if text == "" {
+		return opts, "", fmt.Errorf("text is required")
+	}
+	return opts, text, nil
+}
+
+func (a *App) runEmbed(args []string) error {
+	opts, text, err := parseEmbedArgs(args)
+	if err != nil {
+		if errors.Is(err, errShowHelp) {
+			printHelp(a.stdout, "embed", a.cfgPath)
+			return nil
+		}
+		return err
+	}
+	a.timeout = opts.Timeout
+
+	provider, err := a.resolveProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+	client, err := a.newClient(provider)
+	if err != nil {
+		return err
+	}
+	model := a.cfg.ResolveModel(provider, opts.Model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.askTimeout(provider))
+	defer cancel()
+	vectors, err := providers.Embed(ctx, client, model, []string{text})
+	if err != nil {
+		return err
+	}
+
+	out := map[string]any{
+		"provider":  provider,
+		"model":     model,
+		"embedding": vectors[0],
+	}
+	buf, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("encode embedding: %w", err)
+	}
+	buf = append(buf, '\n')
+	if opts.Output == "" {
+		_, err = a.stdout.Write(buf)
+		return err
+	}
+	if err := writeOutputFile(opts.Output, buf); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "wrote %d-dimension embedding to %s\n", len(vectors[0]), opts.Output)
+	return nil
+}
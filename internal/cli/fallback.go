@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+type askFunc func(provider, model string, client providers.Client) (providers.AskResponse, error)
+
+// askWithFallback calls ask with the primary provider and, while the error is
+// transient (network, timeout, 429, 5xx), with each fallback provider in
+// order using that provider's configured model. It returns the provider and
+// model that answered, or the last error once the list is exhausted. Notes
+// about skipped and failed providers go to notes.
+func (a *App) askWithFallback(notes io.Writer, provider, model string, client providers.Client, fallbacks []string, ask askFunc) (string, string, providers.AskResponse, error) {
+	resp, err := ask(provider, model, client)
+	if err == nil || !providers.IsTransient(err) {
+		return provider, model, resp, err
+	}
+
+	tried := map[string]bool{provider: true}
+	for _, next := range fallbacks {
+		next = strings.ToLower(strings.TrimSpace(next))
+		if next == "" || tried[next] {
+			continue
+		}
+		tried[next] = true
+
+		if !a.cfg.ProviderExists(next) {
+			fmt.Fprintf(notes, "note: skipping fallback provider %q: not configured\n", next)
+			continue
+		}
+		nextModel := a.cfg.ResolveModel(next, a.cfg.GetModel(next))
+		if nextModel == "" {
+			fmt.Fprintf(notes, "note: skipping fallback provider %q: no model set\n", next)
+			continue
+		}
+		nextClient, clientErr := a.newClient(next)
+		if clientErr != nil {
+			fmt.Fprintf(notes, "note: skipping fallback provider %q: %v\n", next, clientErr)
+			continue
+		}
+
+		fmt.Fprintf(notes, "note: %s failed (%s); falling back to %s/%s\n", provider, failureSummary(err), next, nextModel)
+		provider, model = next, nextModel
+		resp, err = ask(provider, model, nextClient)
+		if err == nil || !providers.IsTransient(err) {
+			return provider, model, resp, err
+		}
+	}
+	return provider, model, resp, err
+}
+
+// failureSummary shortens err for one-line notes, using just the HTTP status
+// for provider error responses.
+func failureSummary(err error) string {
+	var httpErr *providers.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status
+	}
+	return err.Error()
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+func TestAskWithFallback(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, name := range []string{"backup", "nomodel"} {
+		model := "backup-model"
+		if name == "nomodel" {
+			model = ""
+		}
+		if err := cfg.AddCustomProvider(name, config.OpenAICompatibleProvider{BaseURL: "http://127.0.0.1:1", Model: model}); err != nil {
+			t.Fatalf("AddCustomProvider error = %v", err)
+		}
+	}
+	var stderr bytes.Buffer
+	app := &App{stdout: &bytes.Buffer{}, stderr: &stderr, cfg: cfg}
+
+	var calls []string
+	ask := func(provider, model string, _ providers.Client) (providers.AskResponse, error) {
+		calls = append(calls, provider+"/"+model)
+		if provider == "primary" {
+			return providers.AskResponse{}, &providers.HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+		}
+		return providers.AskResponse{Text: "ok"}, nil
+	}
+
+	provider, model, resp, err := app.askWithFallback(&stderr, "primary", "m", nil, []string{"missing", "nomodel", "backup"}, ask)
+	if err != nil {
+		t.Fatalf("askWithFallback error = %v", err)
+	}
+	if provider != "backup" || model != "backup-model" || resp.Text != "ok" {
+		t.Fatalf("got %s/%s %q", provider, model, resp.Text)
+	}
+	if strings.Join(calls, ",") != "primary/m,backup/backup-model" {
+		t.Fatalf("calls = %v", calls)
+	}
+	if !strings.Contains(stderr.String(), "falling back to backup/backup-model") {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+}
+
+func TestRunAskQuietSilencesFallbackNotes(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	app, stdout := newStubApp(t, `{"answer":"ok","command":""}`)
+	app.cfg.Retries = -1
+	if err := app.cfg.AddCustomProvider("primary", config.OpenAICompatibleProvider{BaseURL: primary.URL, Model: "m"}); err != nil {
+		t.Fatalf("AddCustomProvider error = %v", err)
+	}
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+
+	if err := app.runAsk([]string{"-q", "-p", "primary", "--fallback", "missing,stub", "--no-history", "q"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "ok") {
+		t.Fatalf("stdout = %q, want the backup answer", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("stderr = %q, want fallback notes silenced by --quiet", stderr.String())
+	}
+}
+
+func TestAskWithFallbackStopsOnClientError(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("backup", config.OpenAICompatibleProvider{BaseURL: "http://127.0.0.1:1", Model: "m"}); err != nil {
+		t.Fatalf("AddCustomProvider error = %v", err)
+	}
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	badRequest := &providers.HTTPError{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}
+	calls := 0
+	_, _, _, err := app.askWithFallback(app.stderr, "primary", "m", nil, []string{"backup"}, func(string, string, providers.Client) (providers.AskResponse, error) {
+		calls++
+		return providers.AskResponse{}, badRequest
+	})
+	if !errors.Is(err, badRequest) || calls != 1 {
+		t.Fatalf("err = %v, calls = %d; want the 400 without fallback", err, calls)
+	}
+}
+
+func TestExplainAuthError(t *testing.T) {
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfg: config.DefaultConfig()}
+	err := app.explainError(fmt.Errorf("ask: %w", &providers.HTTPError{Provider: "openai", StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Body: "noise"}))
+	want := "authentication failed for openai (401 Unauthorized); check your API key (env OPENAI_API_KEY or config); rerun with --verbose to see the provider response"
+	if err == nil || err.Error() != want {
+		t.Fatalf("err = %v", err)
+	}
+
+	other := errors.New("boom")
+	if got := app.explainError(other); got != other {
+		t.Fatalf("non-auth error changed: %v", got)
+	}
+}
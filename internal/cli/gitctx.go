@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+)
+
+const gitContextTimeout = 2 * time.Second
+
+// gitContext reports the branch and status counts of the repository that
+// contains dir. It returns false when dir is not in a repository or git is
+// unavailable, so callers can leave the prompt unchanged.
+func gitContext(dir string) (assistant.GitContext, bool) {
+	if dir == "" || !insideGitRepo(dir) {
+		return assistant.GitContext{}, false
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return assistant.GitContext{}, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitContextTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain=v1", "--branch").Output()
+	if err != nil {
+		return assistant.GitContext{}, false
+	}
+	return parseGitStatus(string(out)), true
+}
+
+// insideGitRepo reports whether dir or one of its parents has a .git entry,
+// which is a directory in normal clones and a file in worktrees.
+func insideGitRepo(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// parseGitStatus summarizes `git status --porcelain=v1 --branch` output.
+func parseGitStatus(out string) assistant.GitContext {
+	var git assistant.GitContext
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			git.Branch = parseGitBranch(strings.TrimPrefix(line, "## "))
+			continue
+		}
+		if len(line) < 3 {
+			continue
+		}
+		x, y := line[0], line[1]
+		if x == '?' && y == '?' {
+			git.Untracked++
+			continue
+		}
+		if x != ' ' && x != '!' {
+			git.Staged++
+		}
+		if y != ' ' && y != '!' {
+			git.Modified++
+		}
+	}
+	return git
+}
+
+// parseGitBranch extracts the branch name from a porcelain branch header
+// such as "main...origin/main [ahead 1]" or "No commits yet on main".
+func parseGitBranch(header string) string {
+	header = strings.TrimPrefix(header, "No commits yet on ")
+	header = strings.TrimPrefix(header, "Initial commit on ")
+	if strings.HasPrefix(header, "HEAD (no branch)") {
+		return "(detached)"
+	}
+	if i := strings.Index(header, "..."); i >= 0 {
+		header = header[:i]
+	}
+	if i := strings.Index(header, " "); i >= 0 {
+		header = header[:i]
+	}
+	return header
+}
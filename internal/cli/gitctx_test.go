@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+)
+
+func TestParseGitStatus(t *testing.T) {
+	out := "## feature/x...origin/feature/x [ahead 2]\nM  staged.go\n M worktree.go\nMM both.go\n?? new.txt\n!! ignored.log\n"
+	got := parseGitStatus(out)
+	want := assistant.GitContext{Branch: "feature/x", Staged: 2, Modified: 2, Untracked: 1}
+	if got != want {
+		t.Fatalf("parseGitStatus = %+v, want %+v", got, want)
+	}
+
+	for header, branch := range map[string]string{
+		"## main":                  "main",
+		"## No commits yet on dev": "dev",
+		"## HEAD (no branch)":      "(detached)",
+	} {
+		if got := parseGitStatus(header + "\n").Branch; got != branch {
+			t.Fatalf("branch for %q = %q, want %q", header, got, branch)
+		}
+	}
+}
+
+func TestGitContextOutsideRepo(t *testing.T) {
+	if _, ok := gitContext(t.TempDir()); ok {
+		t.Fatal("gitContext should report false outside a repository")
+	}
+}
+
+func TestGitContextInRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "-C", dir, "init", "-q", "-b", "trunk").CombinedOutput(); err != nil {
+		t.Skipf("git init: %v: %s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	git, ok := gitContext(sub)
+	if !ok {
+		t.Fatal("gitContext should detect the repository from a subdirectory")
+	}
+	if git.Branch != "trunk" || git.Untracked != 1 {
+		t.Fatalf("gitContext = %+v", git)
+	}
+	prompt := assistant.WithGitContext("base", git)
+	if !strings.HasSuffix(prompt, "\nGit: branch=trunk, staged=0, modified=0, untracked=1") {
+		t.Fatalf("prompt = %q", prompt)
+	}
+}
@@ -3,12 +3,15 @@ package cli
 import (
 	"fmt"
 	"io"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/sasanktumpati/ask/internal/buildinfo"
 	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/render"
 )
 
-const version = "0.2.2"
+const version = buildinfo.Version
 
 func printHelp(w io.Writer, topic string, cfgPath string) {
 	switch topic {
@@ -26,6 +29,26 @@ func printHelp(w io.Writer, topic string, cfgPath string) {
 		printConfigHelp(w, cfgPath)
 	case "markdown":
 		printMarkdownHelp(w)
+	case "chat":
+		printChatHelp(w)
+	case "history":
+		printHistoryHelp(w)
+	case "embed":
+		printEmbedHelp(w)
+	case "prompt", "prompts":
+		printPromptHelp(w)
+	case "rerun":
+		printRerunHelp(w)
+	case "again":
+		printAgainHelp(w)
+	case "compare":
+		printCompareHelp(w)
+	case "cache":
+		printCacheHelp(w)
+	case "batch":
+		printBatchHelp(w)
+	case "doctor":
+		printDoctorHelp(w)
 	default:
 		fmt.Fprintf(w, "unknown help topic %q\n\n", topic)
 		printRootHelp(w, cfgPath)
@@ -45,8 +68,14 @@ func printRootHelp(w io.Writer, cfgPath string) {
 
 	fmt.Fprintln(tw, "GLOBAL FLAGS")
 	fmt.Fprintln(tw, "  -c, --config <path>\tconfig file path (or ASK_CONFIG)")
+	fmt.Fprintln(tw, "  --profile <name>\tuse a named config profile (or ASK_PROFILE)")
+	fmt.Fprintln(tw, "  -p, --provider <name>\tprovider for this run, including models, key and provider show")
 	fmt.Fprintln(tw, "  -h, --help\tshow help")
 	fmt.Fprintln(tw, "  -v, --version\tshow version")
+	fmt.Fprintln(tw, "  -V, --verbose\ttrace provider requests to stderr (credentials redacted)")
+	fmt.Fprintln(tw, "  --no-spinner\tdo not show a spinner during network calls (also off with NO_COLOR)")
+	fmt.Fprintln(tw, "  --no-color\tplain tables and no highlighted command preview (or NO_COLOR)")
+	fmt.Fprintln(tw, "  --user-agent <value>\tUser-Agent sent to providers (or config user_agent; default ask/<version>)")
 	fmt.Fprintln(tw)
 
 	fmt.Fprintln(tw, "COMMANDS")
@@ -55,7 +84,18 @@ func printRootHelp(w io.Writer, cfgPath string) {
 	fmt.Fprintln(tw, "  key\tset/show/clear API keys")
 	fmt.Fprintln(tw, "  config\tshow config and paths")
 	fmt.Fprintln(tw, "  markdown\ttoggle markdown rendering")
+	fmt.Fprintln(tw, "  chat\tinteractive multi-turn conversation")
+	fmt.Fprintln(tw, "  history\tlist/show/clear saved conversations")
+	fmt.Fprintln(tw, "  embed\tgenerate an embedding vector for text")
+	fmt.Fprintln(tw, "  prompt\tadd/list/remove prompt presets for --preset")
+	fmt.Fprintln(tw, "  rerun\trun the last returned command again")
+	fmt.Fprintln(tw, "  again\task the last question again, optionally elsewhere")
+	fmt.Fprintln(tw, "  compare\task several providers or models at once and compare answers")
+	fmt.Fprintln(tw, "  batch\task every question in a file and print JSON lines")
+	fmt.Fprintln(tw, "  cache\tclear cached answers")
+	fmt.Fprintln(tw, "  doctor\tcheck config, provider, API key and connectivity")
 	fmt.Fprintln(tw, "  help [topic]\tshow topic help")
+	fmt.Fprintln(tw, "  m, p, k, cfg\tshortcuts for models, provider, key and config")
 	fmt.Fprintln(tw)
 
 	fmt.Fprintln(tw, "EXAMPLES")
@@ -66,7 +106,7 @@ func printRootHelp(w io.Writer, cfgPath string) {
 	fmt.Fprintln(tw)
 
 	fmt.Fprintln(tw, "TOPICS")
-	fmt.Fprintln(tw, "  ask help ask|models|provider|key|config|markdown")
+	fmt.Fprintln(tw, "  ask help ask|models|provider|key|config|markdown|chat|history|embed|prompt|rerun|again|compare|batch|cache|doctor")
 	fmt.Fprintln(tw)
 
 	fmt.Fprintln(tw, "CONFIG")
@@ -85,64 +125,130 @@ func printAskHelp(w io.Writer) {
 	fmt.Fprintln(tw, "OPTIONS")
 	fmt.Fprintln(tw, "  -p, --provider <name>\tprovider to use")
 	fmt.Fprintln(tw, "  -m, --model <id>\tmodel to use")
-	fmt.Fprintln(tw, "  --timeout <dur|sec>\trequest timeout (default: 90s)")
+	fmt.Fprintln(tw, "  --timeout <dur|sec>\trequest timeout (default: provider timeout_seconds, else defaults.timeout_seconds, else 90s)")
 	fmt.Fprintln(tw, "  --no-markdown\tdisable markdown rendering for this call")
+	fmt.Fprintln(tw, "  --theme <name|path>\tmarkdown theme for this call (default: markdown_theme, else auto)")
+	fmt.Fprintln(tw, "  --width <n>\tmarkdown wrap width (default: COLUMNS, else the terminal width, else 100)")
 	fmt.Fprintln(tw, "  --no-run\tprint returned command without run prompt")
+	fmt.Fprintln(tw, "  --command-only\tprint only the command for scripts; never runs it (as --no-run) and fails if there is none")
+	fmt.Fprintln(tw, "  --dry-run\tprint and copy the command to the clipboard; never execute")
+	fmt.Fprintln(tw, "  -e, --edit\topen the command in $VISUAL/$EDITOR and run what is saved (empty cancels)")
+	fmt.Fprintln(tw, "  --copy\tcopy the answer text to the clipboard after printing it")
+	fmt.Fprintln(tw, "  --copy-command\tprint and copy the command without the run prompt")
 	fmt.Fprintln(tw, "  --json\tprint structured JSON")
+	fmt.Fprintln(tw, "  --raw\tfree-form answer printed verbatim: no JSON contract, parsing, markdown, cache or run prompt")
+	fmt.Fprintln(tw, "  --strict-json\tenforce the answer/command JSON schema where supported (or strict_json in config)")
+	fmt.Fprintln(tw, "  --git-context\tinclude the git branch and changed-file counts in the prompt (or git_context in config)")
+	fmt.Fprintln(tw, "  --code-fences\tlet markdown answers use fenced code blocks (or allow_code_fences in config)")
+	fmt.Fprintln(tw, "  -o, --output <path>\twrite the answer as plain markdown (the --json object, or the verbatim reply with --raw) to path; the command goes to stderr")
+	fmt.Fprintln(tw, "  --no-history\tdo not record this exchange in history")
+	fmt.Fprintln(tw, "  --refresh\tignore a cached answer and cache the new one (see ask help cache)")
+	fmt.Fprintln(tw, "  --no-cache\tneither read nor write the answer cache")
+	fmt.Fprintln(tw, "  --yes, --unsafe\tskip the extra confirmation for dangerous commands")
+	fmt.Fprintln(tw, "  -V, --verbose\ttrace provider, model, and HTTP requests/responses to stderr")
+	fmt.Fprintln(tw, "  -q, --quiet\tprint only the answer and command; no spinner, usage, notes, or run prompt")
+	fmt.Fprintln(tw, "  --no-spinner\tdo not show the progress spinner")
+	fmt.Fprintln(tw, "  -f, --file <path>\tattach file contents as context (repeatable, 128KB per file and in total)")
+	fmt.Fprintln(tw, "  --context <text>\tadd a labeled context snippet before files and the question (repeatable)")
+	fmt.Fprintln(tw, "  --no-redact\tsend --file and --context content without masking likely secrets")
+	fmt.Fprintln(tw, "  -i, --image <path|url>\tattach an image for vision models (repeatable, 20MB per file)")
+	fmt.Fprintln(tw, "  --preset <name>\twrap the question with a saved prompt preset (see ask help prompt)")
+	fmt.Fprintln(tw, "  --system <text>\treplace the built-in system prompt for this call (default: system_prompt)")
+	fmt.Fprintln(tw, "  --system-file <path>\tread the system prompt from a file")
+	fmt.Fprintln(tw, "  -t, --temperature <0-2>\tsampling temperature (default: provider default)")
+	fmt.Fprintln(tw, "  --max-tokens <n>\tmaximum output tokens (flag > provider max_tokens > built-in)")
+	fmt.Fprintln(tw, "  --param key=value\textra request field such as top_p=0.9 (repeatable; JSON values are decoded; dots nest, e.g. options.num_ctx=8192)")
+	fmt.Fprintln(tw, "  --fallback <p1,p2>\tproviders to try when the request fails transiently (default: fallback_providers)")
 	fmt.Fprintln(tw)
 	fmt.Fprintln(tw, "NOTES")
 	fmt.Fprintln(tw, "  Response contract is JSON with keys: answer, command")
+	fmt.Fprintln(tw, "  A custom --system/--system-file prompt replaces only the instructions; the JSON contract (omitted with --raw) and environment line are still appended")
 	fmt.Fprintln(tw, "  If command is present, ask prefills it so Enter runs it")
+	fmt.Fprintln(tw, "  Dangerous commands (rm -rf, dd, mkfs, sudo, ...) need an extra y confirmation")
+	fmt.Fprintln(tw, "  Provider/model precedence: --provider/--model > ASK_PROVIDER/ASK_MODEL > config")
 	_ = tw.Flush()
 }
 
 func printModelsHelp(w io.Writer) {
 	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
 	fmt.Fprintln(tw, "USAGE")
-	fmt.Fprintln(tw, "  ask models list [--provider <name>] [--search <text>]")
-	fmt.Fprintln(tw, "  ask models select [--provider <name>] [--search <text>]")
-	fmt.Fprintln(tw, "  ask models set <model> [--provider <name>]")
+	fmt.Fprintln(tw, "  ask models list [--provider <name>] [--search <text>] [--no-cache] [--json]")
+	fmt.Fprintln(tw, "  ask models select [--provider <name>] [--search <text>] [--no-cache] [--plain]")
+	fmt.Fprintln(tw, "  ask models set <model> [--provider <name>] [--force]")
 	fmt.Fprintln(tw, "  ask models current [--provider <name>]")
+	fmt.Fprintln(tw, "  ask models info <model> [--provider <name>] [--no-cache]")
+	fmt.Fprintln(tw, "  ask models refresh [--provider <name>]")
+	fmt.Fprintln(tw, "  ask models pull <model> [--provider ollama]")
+	fmt.Fprintln(tw, "  ask models alias [list] [--provider <name>]")
+	fmt.Fprintln(tw, "  ask models alias [set] <alias> <model> [--provider <name>]")
+	fmt.Fprintln(tw, "  ask models alias rm <alias> [--provider <name>]")
 	fmt.Fprintln(tw)
 	fmt.Fprintln(tw, "NOTES")
-	fmt.Fprintln(tw, "  list/select always call provider model-list APIs (not hardcoded)")
-	fmt.Fprintln(tw, "  select supports in-loop search using /text")
+	fmt.Fprintln(tw, "  list/select call provider model-list APIs (not hardcoded)")
+	fmt.Fprintln(tw, "  results are cached for model_cache_ttl_seconds (default: 3600); --no-cache bypasses")
+	fmt.Fprintln(tw, "  select opens a fuzzy picker on a terminal (arrows move, type to filter, esc cancels)")
+	fmt.Fprintln(tw, "  --plain uses the numbered prompt instead, which supports in-loop search using /text")
+	fmt.Fprintln(tw, "  set checks the model against the provider's list; --force skips the check")
+	fmt.Fprintln(tw, "  info shows context length and pricing when the provider reports them")
+	fmt.Fprintln(tw, "  pull downloads a model to the Ollama server, showing progress; Ctrl+C cancels")
+	fmt.Fprintln(tw, "  aliases are per provider and expand wherever a model is accepted (--model, models set)")
 	_ = tw.Flush()
 }
 
 func printProvidersHelp(w io.Writer) {
 	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
 	fmt.Fprintln(tw, "USAGE")
-	fmt.Fprintln(tw, "  ask provider list")
+	fmt.Fprintln(tw, "  ask provider list [--json]")
 	fmt.Fprintln(tw, "  ask provider current")
 	fmt.Fprintln(tw, "  ask provider set <name>")
 	fmt.Fprintln(tw, "  ask provider show [name]")
 	fmt.Fprintln(tw, "  ask provider add <name> --base-url <url> [options]")
+	fmt.Fprintln(tw, "  ask provider add <name> --from-file <path> [options]")
+	fmt.Fprintln(tw, "  ask provider export <name>")
 	fmt.Fprintln(tw, "  ask provider remove <name>")
+	fmt.Fprintln(tw, "  ask provider rename <old> <new>")
+	fmt.Fprintln(tw, "  ask provider clone <src> <dst>")
 	fmt.Fprintln(tw)
 	fmt.Fprintln(tw, "ADD OPTIONS")
+	fmt.Fprintln(tw, "  --from-file <path>\tread the provider from JSON (as written by export); flags override it")
 	fmt.Fprintln(tw, "  --model <id>\tdefault model for this provider")
 	fmt.Fprintln(tw, "  --api-key <key>\tstore API key in config")
 	fmt.Fprintln(tw, "  --api-key-env <ENV>\tenv var name for API key")
 	fmt.Fprintln(tw, "  --models-path <path>\tdefault: /models")
 	fmt.Fprintln(tw, "  --chat-path <path>\tdefault: /chat/completions")
+	fmt.Fprintln(tw, "  --models-response-key <path>\tfield holding the models list, e.g. result.models (default: data, then models)")
+	fmt.Fprintln(tw, "  --model-id-field <name>\tID field of each models entry (default: id, then name)")
 	fmt.Fprintln(tw, "  --auth-header <name>\tdefault: Authorization")
 	fmt.Fprintln(tw, "  --auth-prefix <text>\tdefault: Bearer ")
+	fmt.Fprintln(tw, "  --auth-query-param <name>\tsend the API key as this query parameter instead of a header")
+	fmt.Fprintln(tw, "  --basic-user <user>\tuse HTTP Basic auth with this user")
+	fmt.Fprintln(tw, "  --basic-password <pass>\tstore the Basic auth password in config")
+	fmt.Fprintln(tw, "  --basic-password-env <ENV>\tenv var name for the Basic auth password")
 	fmt.Fprintln(tw, "  --header key=value\tadditional static headers (repeatable)")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  list shows where each API key resolves from: env, config, keychain, - (none), or n/a when the provider needs no key")
+	fmt.Fprintln(tw, "  clone copies a custom provider, or turns an OpenAI-compatible built-in into a custom one")
+	fmt.Fprintln(tw, "  export prints a custom provider as JSON without its stored API key or Basic password")
 	_ = tw.Flush()
 }
 
 func printKeysHelp(w io.Writer) {
 	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
 	fmt.Fprintln(tw, "USAGE")
-	fmt.Fprintln(tw, "  ask key set <provider> [--value <key>] [--env <ENV_VAR>]")
-	fmt.Fprintln(tw, "  ask key show <provider>")
+	fmt.Fprintln(tw, "  ask key set <provider> [--value <key>] [--env <ENV_VAR>] [--keychain]")
+	fmt.Fprintln(tw, "  ask key show <provider> [--reveal [--force]]")
 	fmt.Fprintln(tw, "  ask key clear <provider>")
+	fmt.Fprintln(tw, "  ask key import-env")
 	fmt.Fprintln(tw)
 	fmt.Fprintln(tw, "NOTES")
 	fmt.Fprintln(tw, "  key set without --value prompts for secret input")
-	fmt.Fprintln(tw, "  or edit providers.<name>.api_key directly in config.json")
-	fmt.Fprintln(tw, "  env var values take precedence over config api_key")
+	fmt.Fprintln(tw, "  or edit providers.<name>.api_key with `ask config edit`")
+	fmt.Fprintln(tw, "  --keychain stores the key in the OS keychain and only a reference in config")
+	fmt.Fprintln(tw, "  precedence: keychain reference, then env var, then config api_key")
+	fmt.Fprintln(tw, "  show masks the key; --reveal prints it in full to a terminal (--force allows pipes)")
+	fmt.Fprintln(tw, "  import-env records api_key_env for each provider whose env var is set (never the secret)")
+	fmt.Fprintln(tw, "  source= reports where the resolved key came from: keychain, env:<NAME>, or config")
 	_ = tw.Flush()
 }
 
@@ -152,10 +258,35 @@ func printConfigHelp(w io.Writer, cfgPath string) {
 	fmt.Fprintln(tw, "  ask config show")
 	fmt.Fprintln(tw, "  ask config path")
 	fmt.Fprintln(tw, "  ask config template")
+	fmt.Fprintln(tw, "  ask config edit")
+	fmt.Fprintln(tw, "  ask config get <key>")
+	fmt.Fprintln(tw, "  ask config set <key> <value>")
+	fmt.Fprintln(tw, "  ask config validate")
+	fmt.Fprintln(tw, "  ask config export [--redact]")
+	fmt.Fprintln(tw, "  ask config import <path>")
+	fmt.Fprintln(tw, "  ask config restore")
+	fmt.Fprintln(tw, "  ask config schema")
+	fmt.Fprintln(tw, "  ask config profile [list]")
+	fmt.Fprintln(tw, "  ask config profile use <name>")
+	fmt.Fprintln(tw, "  ask config profile new <name>")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  edit opens config.json in $VISUAL/$EDITOR (default: vi) and validates it afterwards")
+	fmt.Fprintln(tw, "  get/set take dotted keys: render_markdown, providers.openai.model, custom_providers.<name>.base_url")
+	fmt.Fprintln(tw, "  set only changes scalar fields; api keys go through `ask key set`")
+	fmt.Fprintln(tw, "  validate reports unknown keys, missing base_url, unset env vars, and bad references")
+	fmt.Fprintln(tw, "  export prints the config JSON; --redact masks api_key values")
+	fmt.Fprintln(tw, "  import merges into the current config and never overwrites stored API keys")
+	fmt.Fprintln(tw, "  schema prints a JSON Schema for config.json; point \"$schema\" at a saved copy")
+	fmt.Fprintln(tw, "  every save keeps the previous config as config.json.bak; restore swaps it back in")
+	fmt.Fprintln(tw, "  profiles are separate config.<name>.json files; --profile > ASK_PROFILE > `profile use`")
+	fmt.Fprintln(tw, "  defaults.timeout_seconds applies to providers without their own timeout_seconds")
+	fmt.Fprintln(tw, "  aliases maps a word to the arguments it expands to, e.g. \"ms\": \"models select\"")
 	fmt.Fprintln(tw)
 	fmt.Fprintln(tw, "PATHS")
 	fmt.Fprintf(tw, "  Config:\t%s\n", cfgPath)
 	fmt.Fprintf(tw, "  Template:\t%s\n", config.TemplatePathForConfig(cfgPath))
+	fmt.Fprintf(tw, "  Backup:\t%s\n", config.BackupPathForConfig(cfgPath))
 	_ = tw.Flush()
 }
 
@@ -164,6 +295,188 @@ func printMarkdownHelp(w io.Writer) {
 	fmt.Fprintln(tw, "USAGE")
 	fmt.Fprintln(tw, "  ask markdown on")
 	fmt.Fprintln(tw, "  ask markdown off")
+	fmt.Fprintln(tw, "  ask markdown toggle")
 	fmt.Fprintln(tw, "  ask markdown status")
+	fmt.Fprintln(tw, "  ask markdown theme [name|path]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintf(tw, "  themes: %s, or a glamour JSON style file\n", strings.Join(render.Themes, ", "))
+	fmt.Fprintln(tw, "  --theme on ask/chat overrides markdown_theme for one call")
+	_ = tw.Flush()
+}
+
+func printChatHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask chat [options]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "OPTIONS")
+	fmt.Fprintln(tw, "  -p, --provider <name>\tprovider to use")
+	fmt.Fprintln(tw, "  -m, --model <id>\tmodel to use")
+	fmt.Fprintln(tw, "  --timeout <dur|sec>\tper-turn request timeout (default: provider timeout_seconds, else 90s)")
+	fmt.Fprintln(tw, "  --no-markdown\tdisable markdown rendering")
+	fmt.Fprintln(tw, "  --theme <name|path>\tmarkdown theme (default: markdown_theme, else auto)")
+	fmt.Fprintln(tw, "  --width <n>\tmarkdown wrap width (default: COLUMNS, else the terminal width, else 100)")
+	fmt.Fprintln(tw, "  --no-history\tdo not save this conversation")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Each turn sends the conversation so far")
+	fmt.Fprintln(tw, "  /reset clears history, /exit or Ctrl+D quits")
+	_ = tw.Flush()
+}
+
+func printEmbedHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask embed --model <id> \"text\" [options]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "OPTIONS")
+	fmt.Fprintln(tw, "  -p, --provider <name>\tprovider to use")
+	fmt.Fprintln(tw, "  -m, --model <id>\tembedding model (required)")
+	fmt.Fprintln(tw, "  -o, --output <path>\twrite the JSON result to path instead of stdout")
+	fmt.Fprintln(tw, "  --timeout <dur|sec>\trequest timeout (default: provider timeout_seconds, else 90s)")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Supported by OpenAI-compatible providers, azure, gemini, and ollama")
+	fmt.Fprintln(tw, "  Output is JSON: {\"provider\", \"model\", \"embedding\": [...]}")
+	_ = tw.Flush()
+}
+
+func printPromptHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask prompt list")
+	fmt.Fprintln(tw, "  ask prompt add <name> \"<template>\"")
+	fmt.Fprintln(tw, "  ask prompt rm <name>")
+	fmt.Fprintln(tw, "  ask --preset <name> \"question\"")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Presets are stored under prompts in config.json")
+	fmt.Fprintln(tw, "  {question} in a template is replaced by the question; without it the question is appended")
+	fmt.Fprintln(tw, "  Presets wrap the question only, so they compose with the system prompt and --system")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "EXAMPLES")
+	fmt.Fprintln(tw, "  ask prompt add sql \"Review this SQL for correctness and performance: {question}\"")
+	fmt.Fprintln(tw, "  ask --preset sql \"SELECT * FROM users WHERE id IN (SELECT ...)\"")
+	_ = tw.Flush()
+}
+
+func printRerunHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask rerun [--print] [--edit] [--yes]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "OPTIONS")
+	fmt.Fprintln(tw, "  --print\tprint the last command instead of running it")
+	fmt.Fprintln(tw, "  -e, --edit\topen the command in $VISUAL/$EDITOR before running it")
+	fmt.Fprintln(tw, "  --yes, --unsafe\tskip the extra confirmation for dangerous commands")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  The most recent command returned by ask is stored in last_command.json next to config.json")
+	fmt.Fprintln(tw, "  Answers without a command keep the previous one")
+	fmt.Fprintln(tw, "  Questions asked with --no-history do not replace it")
+	_ = tw.Flush()
+}
+
+func printAgainHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask again [-p <provider>] [-m <model>] [-t <0-2>] [ask flags]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Replays the last question, including its attached files and context, for a fresh answer")
+	fmt.Fprintln(tw, "  Raise --temperature to avoid getting the same answer back")
+	fmt.Fprintln(tw, "  Accepts every ask flag (see ask help ask); the question is read from last_command.json")
+	_ = tw.Flush()
+}
+
+func printDoctorHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask doctor")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "CHECKS")
+	fmt.Fprintln(tw, "  config\tthe config file parses and passes `ask config validate`")
+	fmt.Fprintln(tw, "  provider\ta current provider is set (--provider > ASK_PROVIDER > current_provider) and exists")
+	fmt.Fprintln(tw, "  api key\tthe provider's API key resolves, when it needs one")
+	fmt.Fprintln(tw, "  base url\tthe provider settings are complete enough to build a client")
+	fmt.Fprintln(tw, "  list models\tthe provider answers a model-list request")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Each failure is marked ✗ with a fix below it; the command exits non-zero when any check fails")
+	fmt.Fprintln(tw, "  Checks after a failed config or provider check are skipped, as is the probe when the key is missing")
+	_ = tw.Flush()
+}
+
+func printBatchHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask batch <file|-> [options]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "OPTIONS")
+	fmt.Fprintln(tw, "  -p, --provider <name>\tprovider for every question (default: current provider)")
+	fmt.Fprintln(tw, "  -m, --model <id>\tmodel for every question (default: provider's model)")
+	fmt.Fprintln(tw, "  --json\tread the file as a JSON array of strings (implied by a .json extension)")
+	fmt.Fprintln(tw, "  --timeout <dur|sec>\tdeadline for each question (default: provider timeout)")
+	fmt.Fprintln(tw, "  -t, --temperature <0-2>\tsampling temperature for every question")
+	fmt.Fprintln(tw, "  --max-tokens <n>\tmaximum output tokens for every question")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Without --json the file holds one question per line; blank lines are skipped")
+	fmt.Fprintln(tw, "  Questions run one at a time; each result is printed as a JSON line as soon as it arrives")
+	fmt.Fprintln(tw, "  A failing question records its error and the run continues; the exit status reports any failure")
+	fmt.Fprintln(tw, "  Commands are never run and nothing is saved to history")
+	_ = tw.Flush()
+}
+
+func printCompareHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask compare \"question\" --providers <p1,p2,...>")
+	fmt.Fprintln(tw, "  ask compare \"question\" [--provider <name>] --models <m1,m2,...>")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "OPTIONS")
+	fmt.Fprintln(tw, "  --providers <list>\tproviders to ask, each with its configured model")
+	fmt.Fprintln(tw, "  --models <list>\tmodels to ask on one provider (default: current provider)")
+	fmt.Fprintln(tw, "  -p, --provider <name>\tprovider for --models")
+	fmt.Fprintln(tw, "  --timeout <dur|sec>\tshared deadline for all requests (default: longest provider timeout)")
+	fmt.Fprintln(tw, "  -t, --temperature <0-2>\tsampling temperature for every request")
+	fmt.Fprintln(tw, "  --max-tokens <n>\tmaximum output tokens for every request")
+	fmt.Fprintln(tw, "  -f, --file <path>\tattach file contents as context (repeatable)")
+	fmt.Fprintln(tw, "  --context <text>\tadd a labeled context snippet (repeatable)")
+	fmt.Fprintln(tw, "  --no-markdown\tdisable markdown rendering")
+	fmt.Fprintln(tw, "  --json\tprint a JSON array with one object per provider/model")
+	fmt.Fprintln(tw, "  --no-spinner\tdo not show the progress spinner")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Requests run concurrently; answers print in the order given")
+	fmt.Fprintln(tw, "  A failing provider shows its error inline; the command fails only when every request fails")
+	fmt.Fprintln(tw, "  Commands are shown but never run, and comparisons are not recorded in history")
+	_ = tw.Flush()
+}
+
+func printCacheHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask cache clear")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Answers are cached only when answer_cache_ttl_seconds is set in config (default: off)")
+	fmt.Fprintln(tw, "  Entries are keyed by provider, model, system prompt, and question, and stored under cache/answers")
+	fmt.Fprintln(tw, "  Only the answer and command text are cached; commands are never run without the usual prompt")
+	fmt.Fprintln(tw, "  Questions with --image, and responses that were not strict JSON, are not cached")
+	_ = tw.Flush()
+}
+
+func printHistoryHelp(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "USAGE")
+	fmt.Fprintln(tw, "  ask history list")
+	fmt.Fprintln(tw, "  ask history show <id>")
+	fmt.Fprintln(tw, "  ask history clear")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NOTES")
+	fmt.Fprintln(tw, "  Transcripts are stored as JSON in the history/ directory next to config.json")
+	fmt.Fprintln(tw, "  One-shot questions are appended to the most recent transcript unless --no-history is set")
 	_ = tw.Flush()
 }
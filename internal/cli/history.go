@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sasanktumpati/ask/internal/assistant"
+	"github.com/sasanktumpati/ask/internal/history"
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+func (a *App) runHistory(args []string) error {
+	if len(args) == 0 {
+		return a.historyList()
+	}
+	if a.showTopicHelpIfRequested("history", args, 0) {
+		return nil
+	}
+
+	sub := strings.ToLower(strings.TrimSpace(args[0]))
+	switch sub {
+	case "list", "ls":
+		if a.showTopicHelpIfRequested("history", args, 1) {
+			return nil
+		}
+		return a.historyList()
+	case "show":
+		if a.showTopicHelpIfRequested("history", args, 1) {
+			return nil
+		}
+		if len(args) < 2 {
+			return usageError("ask history show <id>")
+		}
+		return a.historyShow(strings.TrimSpace(args[1]))
+	case "clear":
+		if a.showTopicHelpIfRequested("history", args, 1) {
+			return nil
+		}
+		removed, err := history.Clear(a.historyDir())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "removed %d transcript(s)\n", removed)
+		return nil
+	default:
+		return unknownSubcommand("history", sub)
+	}
+}
+
+func (a *App) historyDir() string {
+	return history.DirForConfig(a.cfgPath)
+}
+
+func (a *App) historyList() error {
+	transcripts, err := history.List(a.historyDir())
+	if err != nil {
+		return err
+	}
+	if len(transcripts) == 0 {
+		fmt.Fprintln(a.stdout, "no saved conversations")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUPDATED\tPROVIDER\tMODEL\tTURNS\tFIRST QUESTION")
+	for _, t := range transcripts {
+		first := ""
+		for _, m := range t.Messages {
+			if m.Role == providers.RoleUser {
+				first = truncateForList(m.Content, 48)
+				break
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			t.ID, t.UpdatedAt.Local().Format("2006-01-02 15:04"), t.Provider, t.Model, len(t.Messages)/2, first)
+	}
+	return tw.Flush()
+}
+
+func (a *App) historyShow(id string) error {
+	t, err := history.Load(a.historyDir(), id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "id=%s provider=%s model=%s\n", t.ID, t.Provider, t.Model)
+	for _, m := range t.Messages {
+		fmt.Fprintln(a.stdout)
+		if m.Role != providers.RoleAssistant {
+			fmt.Fprintf(a.stdout, "> %s\n", m.Content)
+			continue
+		}
+		parsed, parseErr := assistant.Parse(m.Content)
+		if parseErr != nil {
+			parsed = fallbackAssistantResponse(m.Content)
+		}
+		if parsed.Answer != "" {
+			fmt.Fprintln(a.stdout, parsed.Answer)
+		}
+		if parsed.HasCommand() {
+			fmt.Fprintf(a.stdout, "$ %s\n", parsed.Command)
+		}
+	}
+	return nil
+}
+
+// recordExchange appends a question/answer pair to transcript, creating a new
+// transcript when nil, and persists it. Failures are reported as warnings.
+func (a *App) recordExchange(transcript *history.Transcript, provider, model, question, answer string) *history.Transcript {
+	if transcript == nil {
+		transcript = history.New(provider, model)
+	}
+	transcript.Append(
+		history.Message{Role: providers.RoleUser, Content: question},
+		history.Message{Role: providers.RoleAssistant, Content: answer},
+	)
+	if err := history.Save(a.historyDir(), transcript); err != nil {
+		fmt.Fprintln(a.stderr, "warning: unable to save history:", err)
+	}
+	return transcript
+}
+
+func truncateForList(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
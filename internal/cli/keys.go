@@ -1,14 +1,21 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/secrets"
 
 	"golang.org/x/term"
 )
 
+// keychainBackend returns the OS keychain; tests replace it with a fake.
+var keychainBackend = secrets.Default
+
 func (a *App) runKeys(args []string) error {
 	if len(args) == 0 || a.showTopicHelpIfRequested("key", args, 0) {
 		return nil
@@ -20,25 +27,42 @@ func (a *App) runKeys(args []string) error {
 		if a.showTopicHelpIfRequested("key", args, 1) {
 			return nil
 		}
-		return a.keySet(args[1:])
+		return a.keySet(a.withGlobalProvider(args[1:]))
 	case "clear":
 		if a.showTopicHelpIfRequested("key", args, 1) {
 			return nil
 		}
-		return a.keyClear(args[1:])
+		return a.keyClear(a.withGlobalProvider(args[1:]))
 	case "show":
 		if a.showTopicHelpIfRequested("key", args, 1) {
 			return nil
 		}
-		return a.keyShow(args[1:])
+		return a.keyShow(a.withGlobalProvider(args[1:]))
+	case "import-env":
+		if a.showTopicHelpIfRequested("key", args, 1) {
+			return nil
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+		}
+		return a.keyImportEnv()
 	default:
 		return unknownSubcommand("key", sub)
 	}
 }
 
+// withGlobalProvider supplies the global --provider as the provider argument
+// of a key subcommand when args start with a flag or are empty.
+func (a *App) withGlobalProvider(args []string) []string {
+	if a.provider == "" || (len(args) > 0 && !strings.HasPrefix(args[0], "-")) {
+		return args
+	}
+	return append([]string{a.provider}, args...)
+}
+
 func (a *App) keySet(args []string) error {
 	if len(args) == 0 {
-		return usageError("ask key set <provider> [--value <key>] [--env <ENV_VAR>]")
+		return usageError("ask key set <provider> [--value <key>] [--env <ENV_VAR>] [--keychain]")
 	}
 
 	provider := strings.ToLower(strings.TrimSpace(args[0]))
@@ -48,9 +72,11 @@ func (a *App) keySet(args []string) error {
 
 	var value string
 	var envVar string
+	useKeychain := false
 	rest, err := scanOptions(args[1:], []optionSpec{
 		{Names: []string{"value"}, TakesValue: true, Set: func(v string) error { value = strings.TrimSpace(v); return nil }},
 		{Names: []string{"env"}, TakesValue: true, Set: func(v string) error { envVar = strings.TrimSpace(v); return nil }},
+		{Names: []string{"keychain"}, TakesValue: false, Set: func(string) error { useKeychain = true; return nil }},
 	})
 	if err != nil {
 		return err
@@ -59,21 +85,38 @@ func (a *App) keySet(args []string) error {
 		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
 	}
 
-	if value == "" && envVar == "" {
+	if value == "" && (envVar == "" || useKeychain) {
 		prompted, err := a.readSecret("API key: ")
 		if err != nil {
 			return err
 		}
 		value = prompted
 	}
-
-	if envVar != "" {
-		a.cfg.SetAPIKeyEnv(provider, envVar)
+	if useKeychain && value == "" {
+		return fmt.Errorf("--keychain requires an API key")
 	}
-	if value != "" {
-		a.cfg.SetAPIKey(provider, value)
+
+	if useKeychain {
+		if err := keychainBackend().Store(provider, value); err != nil {
+			return fmt.Errorf("store key in keychain: %w", err)
+		}
 	}
-	if err := a.saveConfig(); err != nil {
+	err = a.updateConfig(func(c *config.Config) error {
+		if !c.ProviderExists(provider) {
+			return fmt.Errorf("provider %q is not configured", provider)
+		}
+		if envVar != "" {
+			c.SetAPIKeyEnv(provider, envVar)
+		}
+		if useKeychain {
+			c.SetAPIKeyRef(provider, secrets.Ref(provider))
+			c.SetAPIKey(provider, "")
+		} else if value != "" {
+			c.SetAPIKey(provider, value)
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -81,6 +124,9 @@ func (a *App) keySet(args []string) error {
 	if envVar != "" {
 		msg += fmt.Sprintf(" (env=%s)", envVar)
 	}
+	if useKeychain {
+		msg += " (keychain)"
+	}
 	fmt.Fprintln(a.stdout, msg)
 	return nil
 }
@@ -93,16 +139,25 @@ func (a *App) keyClear(args []string) error {
 	if !a.cfg.ProviderExists(provider) {
 		return fmt.Errorf("provider %q is not configured", provider)
 	}
-	if _, ok := a.cfg.CustomProviders[provider]; ok {
-		custom := a.cfg.CustomProviders[provider]
-		custom.APIKey = ""
-		custom.APIKeyEnv = ""
-		a.cfg.CustomProviders[provider] = custom
-	} else {
-		a.cfg.SetAPIKey(provider, "")
-		a.cfg.SetAPIKeyEnv(provider, "")
+	if account, ok := secrets.ParseRef(a.cfg.APIKeyRef(provider)); ok {
+		if err := keychainBackend().Delete(account); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("delete key from keychain: %w", err)
+		}
 	}
-	if err := a.saveConfig(); err != nil {
+	err := a.updateConfig(func(c *config.Config) error {
+		if custom, ok := c.CustomProviders[provider]; ok {
+			custom.APIKey = ""
+			custom.APIKeyEnv = ""
+			custom.APIKeyRef = ""
+			c.CustomProviders[provider] = custom
+		} else {
+			c.SetAPIKey(provider, "")
+			c.SetAPIKeyEnv(provider, "")
+			c.SetAPIKeyRef(provider, "")
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 	fmt.Fprintf(a.stdout, "cleared credentials for %s\n", provider)
@@ -111,17 +166,37 @@ func (a *App) keyClear(args []string) error {
 
 func (a *App) keyShow(args []string) error {
 	if len(args) == 0 {
-		return usageError("ask key show <provider>")
+		return usageError("ask key show <provider> [--reveal [--force]]")
 	}
 	provider := strings.ToLower(strings.TrimSpace(args[0]))
 	if !a.cfg.ProviderExists(provider) {
 		return fmt.Errorf("provider %q is not configured", provider)
 	}
+	reveal, force := false, false
+	rest, err := scanOptions(args[1:], []optionSpec{
+		{Names: []string{"reveal"}, TakesValue: false, Set: func(string) error { reveal = true; return nil }},
+		{Names: []string{"force"}, TakesValue: false, Set: func(string) error { force = true; return nil }},
+	})
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	if reveal && !force && !isTerminalWriter(a.stdout) {
+		return fmt.Errorf("--reveal refuses to print a key to a pipe or file; add --force to do it anyway")
+	}
 
-	resolved := a.cfg.ResolveAPIKey(provider)
+	resolved, source := a.cfg.ResolveAPIKeySource(provider)
 	masked := "<empty>"
 	if strings.TrimSpace(resolved) != "" {
 		masked = maskForShow(resolved)
+		if reveal {
+			masked = resolved
+		}
+	}
+	if source == "" {
+		source = "none"
 	}
 	storage := "none"
 	if custom, ok := a.cfg.CustomProviders[provider]; ok {
@@ -134,25 +209,78 @@ func (a *App) keyShow(args []string) error {
 			storage = "plain"
 		}
 	}
-	envVar := ""
-	if custom, ok := a.cfg.CustomProviders[provider]; ok {
-		envVar = strings.TrimSpace(custom.APIKeyEnv)
-	} else {
-		envVar = strings.TrimSpace(a.cfg.Providers[provider].APIKeyEnv)
+	ref := a.cfg.APIKeyRef(provider)
+	if ref != "" {
+		storage = "keychain"
+	}
+	envVar := a.apiKeyEnv(provider)
+
+	// Unset values are dimmed on a terminal so a missing key stands out.
+	t := newTable()
+	field := func(name, value string) {
+		style := rowPlain
+		if value == "none" || value == "<empty>" {
+			style = rowDim
+		}
+		t.row(style, "%s=%s", name, value)
+	}
+	field("provider", provider)
+	field("api_key", masked)
+	field("storage", storage)
+	field("source", source)
+	if ref != "" {
+		field("api_key_ref", ref)
+	}
+	if envVar != "" {
+		field("api_key_env", envVar)
+	}
+	return t.flush(a.stdout, a.colorEnabled())
+}
+
+// keyImportEnv links every provider whose API key env var is populated by
+// recording api_key_env in config. Only the variable name is stored, never
+// the secret.
+func (a *App) keyImportEnv() error {
+	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tENV\tSTATUS")
+	var linked []string
+	for _, provider := range a.cfg.ProviderNames() {
+		envVar := a.apiKeyEnv(provider)
 		if envVar == "" {
-			if defaults, ok := builtinEnv(provider); ok {
-				envVar = defaults
-			}
+			continue
 		}
+		status := "missing"
+		if strings.TrimSpace(os.Getenv(envVar)) != "" {
+			status = "linked"
+			linked = append(linked, provider)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", provider, envVar, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(linked) == 0 {
+		return nil
 	}
+	return a.updateConfig(func(c *config.Config) error {
+		for _, provider := range linked {
+			c.SetAPIKeyEnv(provider, a.apiKeyEnv(provider))
+		}
+		return nil
+	})
+}
 
-	fmt.Fprintf(a.stdout, "provider=%s\n", provider)
-	fmt.Fprintf(a.stdout, "api_key=%s\n", masked)
-	fmt.Fprintf(a.stdout, "storage=%s\n", storage)
-	if envVar != "" {
-		fmt.Fprintf(a.stdout, "api_key_env=%s\n", envVar)
+// apiKeyEnv returns the env var configured for provider's API key, falling
+// back to the built-in default.
+func (a *App) apiKeyEnv(provider string) string {
+	if custom, ok := a.cfg.CustomProviders[provider]; ok {
+		return strings.TrimSpace(custom.APIKeyEnv)
 	}
-	return nil
+	if envVar := strings.TrimSpace(a.cfg.Providers[provider].APIKeyEnv); envVar != "" {
+		return envVar
+	}
+	envVar, _ := builtinEnv(provider)
+	return envVar
 }
 
 func (a *App) readSecret(prompt string) (string, error) {
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestKeyShowReveal(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-env-secret-1234")
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: config.DefaultConfig()}
+
+	if err := app.runKeys([]string{"show", "openai"}); err != nil {
+		t.Fatalf("key show error = %v", err)
+	}
+	out := stdout.String()
+	if strings.Contains(out, "sk-env-secret-1234") || !strings.Contains(out, "source=env:OPENAI_API_KEY") {
+		t.Fatalf("masked output = %q", out)
+	}
+
+	if err := app.runKeys([]string{"show", "openai", "--reveal"}); err == nil {
+		t.Fatal("expected --reveal to refuse a non-terminal stdout")
+	}
+
+	stdout.Reset()
+	if err := app.runKeys([]string{"show", "openai", "--reveal", "--force"}); err != nil {
+		t.Fatalf("key show --reveal --force error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "api_key=sk-env-secret-1234\n") {
+		t.Fatalf("revealed output = %q", stdout.String())
+	}
+}
+
+func TestKeyImportEnv(t *testing.T) {
+	for _, provider := range config.BuiltinProviderNames() {
+		if env, ok := builtinEnv(provider); ok {
+			t.Setenv(env, "")
+		}
+	}
+	t.Setenv("GROQ_API_KEY", "gsk-test")
+	t.Setenv("MYPROXY_KEY", "proxy-secret")
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("myproxy", config.OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1", APIKeyEnv: "MYPROXY_KEY"}); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: cfgPath, cfg: cfg}
+
+	if err := app.runKeys([]string{"import-env"}); err != nil {
+		t.Fatalf("import-env error = %v", err)
+	}
+	var rows []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		rows = append(rows, strings.Join(strings.Fields(line), " "))
+	}
+	out := strings.Join(rows, "\n")
+	for _, want := range []string{"groq GROQ_API_KEY linked", "myproxy MYPROXY_KEY linked", "openai OPENAI_API_KEY missing"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ollama") {
+		t.Errorf("keyless ollama listed:\n%s", out)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Providers["groq"].APIKeyEnv != "GROQ_API_KEY" || saved.Providers["groq"].APIKey != "" {
+		t.Fatalf("saved groq = %+v", saved.Providers["groq"])
+	}
+}
+
+func TestKeyShowUsesGlobalProvider(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cfg := config.DefaultConfig()
+	cfg.SetAPIKey("anthropic", "sk-ant-test-9876")
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfg: cfg, cfgPath: filepath.Join(t.TempDir(), "config.json"), provider: "anthropic"}
+
+	if err := app.runKeys([]string{"show"}); err != nil {
+		t.Fatalf("key show error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "anthropic") || !strings.Contains(stdout.String(), "9876") {
+		t.Fatalf("key show output = %q", stdout.String())
+	}
+}
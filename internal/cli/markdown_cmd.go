@@ -3,6 +3,9 @@ package cli
 import (
 	"fmt"
 	"strings"
+
+	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/render"
 )
 
 func (a *App) runMarkdown(args []string) error {
@@ -19,22 +22,26 @@ func (a *App) runMarkdown(args []string) error {
 		if a.showTopicHelpIfRequested("markdown", args, 1) {
 			return nil
 		}
-		a.cfg.RenderMarkdown = true
-		if err := a.saveConfig(); err != nil {
-			return err
-		}
-		fmt.Fprintln(a.stdout, "markdown rendering enabled")
-		return nil
+		return a.setMarkdown(true)
 	case "off", "disable":
 		if a.showTopicHelpIfRequested("markdown", args, 1) {
 			return nil
 		}
-		a.cfg.RenderMarkdown = false
-		if err := a.saveConfig(); err != nil {
-			return err
+		return a.setMarkdown(false)
+	case "toggle":
+		if a.showTopicHelpIfRequested("markdown", args, 1) {
+			return nil
 		}
-		fmt.Fprintln(a.stdout, "markdown rendering disabled")
-		return nil
+		return a.setMarkdown(!a.cfg.RenderMarkdown)
+	case "theme":
+		if a.showTopicHelpIfRequested("markdown", args, 1) {
+			return nil
+		}
+		if len(args) < 2 {
+			fmt.Fprintf(a.stdout, "markdown_theme=%s\n", displayTheme(a.cfg.MarkdownTheme))
+			return nil
+		}
+		return a.setMarkdownTheme(strings.TrimSpace(args[1]))
 	case "status":
 		if a.showTopicHelpIfRequested("markdown", args, 1) {
 			return nil
@@ -45,11 +52,58 @@ func (a *App) runMarkdown(args []string) error {
 	}
 }
 
+func (a *App) setMarkdown(enabled bool) error {
+	if err := a.updateConfig(func(c *config.Config) error { c.RenderMarkdown = enabled; return nil }); err != nil {
+		return err
+	}
+	if enabled {
+		fmt.Fprintln(a.stdout, "markdown rendering enabled")
+	} else {
+		fmt.Fprintln(a.stdout, "markdown rendering disabled")
+	}
+	return nil
+}
+
 func (a *App) markdownStatus() error {
 	status := "off"
 	if a.cfg.RenderMarkdown {
 		status = "on"
 	}
-	fmt.Fprintf(a.stdout, "markdown=%s\n", status)
+	fmt.Fprintf(a.stdout, "markdown=%s theme=%s\n", status, displayTheme(a.cfg.MarkdownTheme))
+	return nil
+}
+
+func (a *App) setMarkdownTheme(theme string) error {
+	if !render.ValidTheme(theme) {
+		return fmt.Errorf("unknown markdown theme %q; use one of %s or a glamour JSON style file", theme, strings.Join(render.Themes, ", "))
+	}
+	if theme == render.ThemeAuto {
+		theme = ""
+	}
+	if err := a.updateConfig(func(c *config.Config) error { c.MarkdownTheme = theme; return nil }); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "markdown theme set to %s\n", displayTheme(theme))
 	return nil
 }
+
+// markdownTheme returns the --theme override or the configured theme,
+// warning and falling back to auto when the name is not recognized.
+func (a *App) markdownTheme(override string) string {
+	theme := strings.TrimSpace(override)
+	if theme == "" {
+		theme = a.cfg.MarkdownTheme
+	}
+	if !render.ValidTheme(theme) {
+		fmt.Fprintf(a.stderr, "warning: unknown markdown theme %q; using auto\n", theme)
+		return render.ThemeAuto
+	}
+	return theme
+}
+
+func displayTheme(theme string) string {
+	if theme == "" {
+		return render.ThemeAuto
+	}
+	return theme
+}
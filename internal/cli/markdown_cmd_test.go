@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestMarkdownToggle(t *testing.T) {
+	var stdout bytes.Buffer
+	path := filepath.Join(t.TempDir(), "config.json")
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: path, cfg: config.DefaultConfig()}
+
+	for _, want := range []string{"markdown rendering disabled\n", "markdown rendering enabled\n"} {
+		stdout.Reset()
+		if err := app.runMarkdown([]string{"toggle"}); err != nil {
+			t.Fatalf("markdown toggle error = %v", err)
+		}
+		if stdout.String() != want {
+			t.Fatalf("stdout = %q, want %q", stdout.String(), want)
+		}
+		saved, err := config.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.RenderMarkdown != app.cfg.RenderMarkdown {
+			t.Fatalf("saved render_markdown = %v, want %v", saved.RenderMarkdown, app.cfg.RenderMarkdown)
+		}
+	}
+}
@@ -2,17 +2,44 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/modelcache"
 	"github.com/sasanktumpati/ask/internal/providers"
 )
 
+type modelArgs struct {
+	Provider string
+	Search   string
+	NoCache  bool
+	Force    bool
+	JSON     bool
+	Plain    bool // numbered selection even on a terminal
+}
+
+// modelView is the JSON shape of a model in `models list --json`. Prices are
+// USD per token.
+type modelView struct {
+	ID              string   `json:"id"`
+	DisplayName     string   `json:"display_name,omitempty"`
+	Current         bool     `json:"current"`
+	Aliases         []string `json:"aliases,omitempty"`
+	ContextLength   int      `json:"context_length,omitempty"`
+	MaxOutputTokens int      `json:"max_output_tokens,omitempty"`
+	PromptPrice     float64  `json:"prompt_price,omitempty"`
+	CompletionPrice float64  `json:"completion_price,omitempty"`
+}
+
 func (a *App) runModels(args []string) error {
 	if len(args) == 0 {
-		return a.listModels("", "")
+		return a.listModels(modelArgs{})
 	}
 	if a.showTopicHelpIfRequested("models", args, 0) {
 		return nil
@@ -24,77 +51,202 @@ func (a *App) runModels(args []string) error {
 		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
 			return nil
 		}
-		provider, search, rest, err := parseProviderSearch(args[1:])
+		opts, rest, err := parseModelArgs(args[1:])
 		if err != nil {
 			return err
 		}
 		if len(rest) > 0 {
-			search = strings.Join(rest, " ")
+			opts.Search = strings.Join(rest, " ")
 		}
-		return a.listModels(provider, search)
+		return a.listModels(opts)
 	case "current":
 		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
 			return nil
 		}
-		provider, _, rest, err := parseProviderSearch(args[1:])
+		opts, rest, err := parseModelArgs(args[1:])
 		if err != nil {
 			return err
 		}
 		if len(rest) > 0 {
 			return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
 		}
-		return a.currentModel(provider)
+		return a.currentModel(opts.Provider)
 	case "set":
 		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
 			return nil
 		}
-		provider, _, rest, err := parseProviderSearch(args[1:])
+		opts, rest, err := parseModelArgs(args[1:])
 		if err != nil {
 			return err
 		}
 		if len(rest) == 0 {
-			return usageError("ask models set <model> [--provider <name>]")
+			return usageError("ask models set <model> [--provider <name>] [--force]")
+		}
+		return a.setModel(opts.Provider, strings.Join(rest, " "), opts.Force)
+	case "info":
+		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
+			return nil
+		}
+		opts, rest, err := parseModelArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) == 0 {
+			return usageError("ask models info <model> [--provider <name>]")
+		}
+		return a.modelInfo(opts, strings.Join(rest, " "))
+	case "refresh":
+		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
+			return nil
+		}
+		opts, rest, err := parseModelArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+		}
+		return a.refreshModels(opts.Provider)
+	case "pull":
+		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
+			return nil
+		}
+		opts, rest, err := parseModelArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return usageError("ask models pull <model> [--provider ollama]")
 		}
-		return a.setModel(provider, strings.Join(rest, " "))
+		return a.pullModel(opts.Provider, rest[0])
+	case "alias", "aliases":
+		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
+			return nil
+		}
+		opts, rest, err := parseModelArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		return a.runModelAlias(opts.Provider, rest)
 	case "select":
 		if a.showTopicHelpIfAnyFlagRequested("models", args, 1) {
 			return nil
 		}
-		provider, search, rest, err := parseProviderSearch(args[1:])
+		opts, rest, err := parseModelArgs(args[1:])
 		if err != nil {
 			return err
 		}
 		if len(rest) > 0 {
-			search = strings.Join(rest, " ")
+			opts.Search = strings.Join(rest, " ")
 		}
-		return a.selectModel(provider, search)
+		return a.selectModel(opts)
 	default:
-		provider, search, rest, err := parseProviderSearch(args)
+		opts, rest, err := parseModelArgs(args)
 		if err != nil {
 			return err
 		}
 		if len(rest) > 0 {
-			search = strings.Join(rest, " ")
+			opts.Search = strings.Join(rest, " ")
+		}
+		return a.listModels(opts)
+	}
+}
+
+// fetchModels returns the model list for provider, serving it from the disk
+// cache when fresh and listed by the provider's current base URL, unless
+// noCache is set. Fresh results are written back to
+// the cache; cache write failures are reported as warnings.
+func (a *App) fetchModels(ctx context.Context, provider string, client providers.Client, noCache bool) ([]providers.Model, error) {
+	dir := modelcache.DirForConfig(a.cfgPath)
+	baseURL := a.cfg.ResolveBaseURL(provider)
+	ttl := a.modelCacheTTL()
+	if !noCache {
+		if models, ok := modelcache.Load(dir, provider, baseURL, ttl); ok {
+			return models, nil
+		}
+	}
+
+	stopSpinner := a.startSpinner("Fetching models")
+	models, err := client.ListModels(ctx)
+	stopSpinner()
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		if err := modelcache.Save(dir, provider, baseURL, models); err != nil {
+			fmt.Fprintln(a.stderr, "warning: unable to cache models:", err)
 		}
-		return a.listModels(provider, search)
 	}
+	return models, nil
 }
 
-func (a *App) listModels(providerInput string, search string) error {
+func (a *App) modelCacheTTL() time.Duration {
+	switch {
+	case a.cfg.ModelCacheTTL < 0:
+		return 0
+	case a.cfg.ModelCacheTTL == 0:
+		return modelcache.DefaultTTL
+	default:
+		return time.Duration(a.cfg.ModelCacheTTL) * time.Second
+	}
+}
+
+func (a *App) refreshModels(providerInput string) error {
 	provider, err := a.resolveProvider(providerInput)
 	if err != nil {
 		return err
 	}
+	if err := modelcache.Invalidate(modelcache.DirForConfig(a.cfgPath), provider); err != nil {
+		return err
+	}
+	client, err := a.newClient(provider)
+	if err != nil {
+		return err
+	}
+	models, err := a.fetchModels(context.Background(), provider, client, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "refreshed %d models for %s\n", len(models), provider)
+	return nil
+}
+
+func (a *App) listModels(opts modelArgs) error {
+	provider, err := a.resolveProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
 	client, err := a.newClient(provider)
 	if err != nil {
 		return err
 	}
 
-	models, err := client.ListModels(context.Background())
+	models, err := a.fetchModels(context.Background(), provider, client, opts.NoCache)
 	if err != nil {
 		return err
 	}
+	search := opts.Search
 	models = filterModels(models, search)
+	current := a.cfg.ResolveModel(provider, a.cfg.GetModel(provider))
+	aliases := aliasesByModel(a.cfg.ModelAliasesFor(provider))
+	if opts.JSON {
+		views := make([]modelView, 0, len(models))
+		for _, model := range models {
+			views = append(views, modelView{
+				ID:              model.ID,
+				DisplayName:     model.DisplayName,
+				Current:         model.ID == current,
+				Aliases:         aliases[model.ID],
+				ContextLength:   model.ContextLength,
+				MaxOutputTokens: model.MaxOutputTokens,
+				PromptPrice:     model.PromptPrice,
+				CompletionPrice: model.CompletionPrice,
+			})
+		}
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(views)
+	}
 	if len(models) == 0 {
 		if strings.TrimSpace(search) == "" {
 			fmt.Fprintf(a.stdout, "no models found for provider %s\n", provider)
@@ -104,29 +256,193 @@ func (a *App) listModels(providerInput string, search string) error {
 		return nil
 	}
 
-	current := a.cfg.GetModel(provider)
-	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
-	fmt.Fprintf(tw, "Provider:\t%s\n", provider)
-	fmt.Fprintf(tw, "Models:\t%d\n", len(models))
+	t := newTable()
+	t.row(rowPlain, "Provider:\t%s", provider)
+	t.row(rowPlain, "Models:\t%d", len(models))
 	if strings.TrimSpace(search) != "" {
-		fmt.Fprintf(tw, "Search:\t%q\n", search)
+		t.row(rowPlain, "Search:\t%q", search)
+	}
+	t.row(rowPlain, "")
+	if len(aliases) > 0 {
+		t.row(rowPlain, "CURRENT\tMODEL\tALIAS\tDISPLAY")
+	} else {
+		t.row(rowPlain, "CURRENT\tMODEL\tDISPLAY")
 	}
-	fmt.Fprintln(tw)
-	fmt.Fprintln(tw, "CURRENT\tMODEL\tDISPLAY")
 	for _, model := range models {
-		marker := ""
+		marker, style := "", rowPlain
 		if model.ID == current {
-			marker = "*"
+			marker, style = "*", rowCurrent
 		}
 		display := model.DisplayName
 		if display == model.ID {
 			display = ""
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\n", marker, model.ID, display)
+		if len(aliases) > 0 {
+			t.row(style, "%s\t%s\t%s\t%s", marker, model.ID, strings.Join(aliases[model.ID], ","), display)
+			continue
+		}
+		t.row(style, "%s\t%s\t%s", marker, model.ID, display)
+	}
+	return t.flush(a.stdout, a.colorEnabled())
+}
+
+// aliasesByModel inverts an alias-to-model map, sorting each model's aliases.
+func aliasesByModel(aliases map[string]string) map[string][]string {
+	byModel := map[string][]string{}
+	for alias, model := range aliases {
+		byModel[model] = append(byModel[model], alias)
+	}
+	for _, names := range byModel {
+		sort.Strings(names)
+	}
+	return byModel
+}
+
+func (a *App) runModelAlias(providerInput string, rest []string) error {
+	if len(rest) == 0 || (len(rest) == 1 && strings.EqualFold(rest[0], "list")) {
+		return a.listModelAliases(providerInput)
+	}
+	switch strings.ToLower(rest[0]) {
+	case "rm", "remove":
+		if len(rest) != 2 {
+			return usageError("ask models alias rm <alias> [--provider <name>]")
+		}
+		return a.removeModelAlias(providerInput, rest[1])
+	case "set":
+		// The explicit form lets an alias be named rm or list.
+		if len(rest) == 3 {
+			return a.setModelAlias(providerInput, rest[1], rest[2])
+		}
+	}
+	if len(rest) != 2 {
+		return usageError("ask models alias <alias> <model> [--provider <name>]")
+	}
+	return a.setModelAlias(providerInput, rest[0], rest[1])
+}
+
+func (a *App) setModelAlias(providerInput, alias, model string) error {
+	provider, err := a.resolveProvider(providerInput)
+	if err != nil {
+		return err
+	}
+	if err := a.updateConfig(func(c *config.Config) error { return c.SetModelAlias(provider, alias, model) }); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "alias %s now points to %s for %s\n", strings.ToLower(strings.TrimSpace(alias)), strings.TrimSpace(model), provider)
+	return nil
+}
+
+func (a *App) removeModelAlias(providerInput, alias string) error {
+	provider, err := a.resolveProvider(providerInput)
+	if err != nil {
+		return err
 	}
+	err = a.updateConfig(func(c *config.Config) error {
+		if !c.RemoveModelAlias(provider, alias) {
+			return fmt.Errorf("alias %q is not defined for %s", alias, provider)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "removed alias %s for %s\n", strings.ToLower(strings.TrimSpace(alias)), provider)
+	return nil
+}
+
+// listModelAliases prints aliases for providerInput, or for every provider
+// when it is empty.
+func (a *App) listModelAliases(providerInput string) error {
+	names := make([]string, 0, len(a.cfg.ModelAliases))
+	if strings.TrimSpace(providerInput) != "" {
+		provider, err := a.resolveProvider(providerInput)
+		if err != nil {
+			return err
+		}
+		names = append(names, provider)
+	} else {
+		for name := range a.cfg.ModelAliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tALIAS\tMODEL")
+	count := 0
+	for _, provider := range names {
+		aliases := a.cfg.ModelAliasesFor(provider)
+		keys := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			keys = append(keys, alias)
+		}
+		sort.Strings(keys)
+		for _, alias := range keys {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", provider, alias, aliases[alias])
+			count++
+		}
+	}
+	if count == 0 {
+		fmt.Fprintln(a.stdout, "no model aliases defined")
+		return nil
+	}
+	return tw.Flush()
+}
+
+func (a *App) modelInfo(opts modelArgs, modelID string) error {
+	provider, err := a.resolveProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+	client, err := a.newClient(provider)
+	if err != nil {
+		return err
+	}
+	models, err := a.fetchModels(context.Background(), provider, client, opts.NoCache)
+	if err != nil {
+		return err
+	}
+
+	modelID = strings.TrimSpace(modelID)
+	var model *providers.Model
+	for i := range models {
+		if strings.EqualFold(models[i].ID, modelID) {
+			model = &models[i]
+			break
+		}
+	}
+	if model == nil {
+		return fmt.Errorf("model %q not found for provider %s; run `ask models list --provider %s`", modelID, provider, provider)
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "Provider:\t%s\n", provider)
+	fmt.Fprintf(tw, "Model:\t%s\n", model.ID)
+	if model.DisplayName != "" && model.DisplayName != model.ID {
+		fmt.Fprintf(tw, "Display:\t%s\n", model.DisplayName)
+	}
+	fmt.Fprintf(tw, "Context length:\t%s\n", formatTokenCount(model.ContextLength))
+	fmt.Fprintf(tw, "Max output:\t%s\n", formatTokenCount(model.MaxOutputTokens))
+	fmt.Fprintf(tw, "Prompt price:\t%s\n", formatPrice(model.PromptPrice))
+	fmt.Fprintf(tw, "Completion price:\t%s\n", formatPrice(model.CompletionPrice))
 	return tw.Flush()
 }
 
+func formatTokenCount(n int) string {
+	if n <= 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d tokens", n)
+}
+
+// formatPrice renders a per-token USD price as a per-million-token figure.
+func formatPrice(perToken float64) string {
+	if perToken <= 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("$%.4g / 1M tokens", perToken*1_000_000)
+}
+
 func (a *App) currentModel(providerInput string) error {
 	provider, err := a.resolveProvider(providerInput)
 	if err != nil {
@@ -141,7 +457,7 @@ func (a *App) currentModel(providerInput string) error {
 	return nil
 }
 
-func (a *App) setModel(providerInput string, model string) error {
+func (a *App) setModel(providerInput string, model string, force bool) error {
 	provider, err := a.resolveProvider(providerInput)
 	if err != nil {
 		return err
@@ -150,16 +466,88 @@ func (a *App) setModel(providerInput string, model string) error {
 	if model == "" {
 		return fmt.Errorf("model cannot be empty")
 	}
-	a.cfg.SetModel(provider, model)
-	if err := a.saveConfig(); err != nil {
+	model = a.cfg.ResolveModel(provider, model)
+	if !force {
+		if model, err = a.verifyModel(provider, model); err != nil {
+			return err
+		}
+	}
+	if err := a.updateConfig(func(c *config.Config) error { c.SetModel(provider, model); return nil }); err != nil {
 		return err
 	}
 	fmt.Fprintf(a.stdout, "set model for %s to %s\n", provider, model)
 	return nil
 }
 
-func (a *App) selectModel(providerInput string, search string) error {
-	provider, err := a.resolveProvider(providerInput)
+// verifyModel checks model against the provider's model list and returns the
+// listed ID (matching is case-insensitive). Unknown models are rejected with a
+// suggestion; when the list cannot be fetched the model is accepted with a
+// warning.
+func (a *App) verifyModel(provider, model string) (string, error) {
+	client, err := a.newClient(provider)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: unable to verify model %s: %v\n", model, err)
+		return model, nil
+	}
+	models, err := a.fetchModels(context.Background(), provider, client, false)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: unable to verify model %s: %v\n", model, err)
+		return model, nil
+	}
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		if strings.EqualFold(m.ID, model) {
+			return m.ID, nil
+		}
+		ids = append(ids, m.ID)
+	}
+	if suggestion := closestMatch(model, ids); suggestion != "" {
+		return "", fmt.Errorf("model %q not found for %s; did you mean %q? (use --force to set it anyway)", model, provider, suggestion)
+	}
+	return "", fmt.Errorf("model %q not found for %s; run `ask models list` or use --force to set it anyway", model, provider)
+}
+
+// closestMatch returns the candidate with the smallest edit distance to
+// target, or "" when none is close enough to be a plausible typo.
+func closestMatch(target string, candidates []string) string {
+	target = strings.ToLower(target)
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := editDistance(target, strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist < 0 || bestDist > len([]rune(target))/3+2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func (a *App) selectModel(opts modelArgs) error {
+	provider, err := a.resolveProvider(opts.Provider)
 	if err != nil {
 		return err
 	}
@@ -167,7 +555,7 @@ func (a *App) selectModel(providerInput string, search string) error {
 	if err != nil {
 		return err
 	}
-	models, err := client.ListModels(context.Background())
+	models, err := a.fetchModels(context.Background(), provider, client, opts.NoCache)
 	if err != nil {
 		return err
 	}
@@ -175,7 +563,23 @@ func (a *App) selectModel(providerInput string, search string) error {
 		return fmt.Errorf("no models available for %s", provider)
 	}
 
-	activeSearch := strings.TrimSpace(search)
+	if !opts.Plain && a.canUsePicker() {
+		ids := make([]string, len(models))
+		for i, m := range models {
+			ids[i] = m.ID
+		}
+		chosen, err := a.runPicker("provider="+provider, ids, strings.TrimSpace(opts.Search))
+		if err != nil {
+			return err
+		}
+		if chosen == "" {
+			fmt.Fprintln(a.stdout, "selection cancelled")
+			return nil
+		}
+		return a.applySelectedModel(provider, chosen)
+	}
+
+	activeSearch := strings.TrimSpace(opts.Search)
 	for {
 		filtered := filterModels(models, activeSearch)
 		if len(filtered) == 0 {
@@ -217,23 +621,25 @@ func (a *App) selectModel(providerInput string, search string) error {
 			continue
 		}
 
-		chosen := filtered[n-1].ID
-		a.cfg.SetModel(provider, chosen)
-		if err := a.saveConfig(); err != nil {
-			return err
-		}
-		fmt.Fprintf(a.stdout, "set model for %s to %s\n", provider, chosen)
-		return nil
+		return a.applySelectedModel(provider, filtered[n-1].ID)
 	}
 }
 
-func parseProviderSearch(args []string) (provider string, search string, rest []string, err error) {
+func (a *App) applySelectedModel(provider, model string) error {
+	if err := a.updateConfig(func(c *config.Config) error { c.SetModel(provider, model); return nil }); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "set model for %s to %s\n", provider, model)
+	return nil
+}
+
+func parseModelArgs(args []string) (opts modelArgs, rest []string, err error) {
 	rest, err = scanOptions(args, []optionSpec{
 		{
 			Names:      []string{"provider", "p"},
 			TakesValue: true,
 			Set: func(v string) error {
-				provider = strings.TrimSpace(v)
+				opts.Provider = strings.TrimSpace(v)
 				return nil
 			},
 		},
@@ -241,12 +647,44 @@ func parseProviderSearch(args []string) (provider string, search string, rest []
 			Names:      []string{"search", "s"},
 			TakesValue: true,
 			Set: func(v string) error {
-				search = strings.TrimSpace(v)
+				opts.Search = strings.TrimSpace(v)
+				return nil
+			},
+		},
+		{
+			Names:      []string{"no-cache"},
+			TakesValue: false,
+			Set: func(string) error {
+				opts.NoCache = true
+				return nil
+			},
+		},
+		{
+			Names:      []string{"json"},
+			TakesValue: false,
+			Set: func(string) error {
+				opts.JSON = true
+				return nil
+			},
+		},
+		{
+			Names:      []string{"force"},
+			TakesValue: false,
+			Set: func(string) error {
+				opts.Force = true
+				return nil
+			},
+		},
+		{
+			Names:      []string{"plain"},
+			TakesValue: false,
+			Set: func(string) error {
+				opts.Plain = true
 				return nil
 			},
 		},
 	})
-	return provider, search, rest, err
+	return opts, rest, err
 }
 
 func filterModels(models []providers.Model, query string) []providers.Model {
@@ -268,7 +706,7 @@ func filterModels(models []providers.Model, query string) []providers.Model {
 func (a *App) resolveProvider(providerInput string) (string, error) {
 	provider := strings.ToLower(strings.TrimSpace(providerInput))
 	if provider == "" {
-		provider = strings.ToLower(strings.TrimSpace(a.cfg.CurrentProvider))
+		provider = a.defaultProvider()
 	}
 	if provider == "" {
 		return "", fmt.Errorf("no default provider set; run `ask provider set <name>` or pass --provider")
@@ -278,3 +716,12 @@ func (a *App) resolveProvider(providerInput string) (string, error) {
 	}
 	return provider, nil
 }
+
+// defaultProvider returns the global --provider when given, otherwise the
+// configured current provider.
+func (a *App) defaultProvider() string {
+	if a.provider != "" {
+		return a.provider
+	}
+	return strings.ToLower(strings.TrimSpace(a.cfg.CurrentProvider))
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestModelAliasCommands(t *testing.T) {
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: config.DefaultConfig()}
+
+	if err := app.runModels([]string{"alias", "sonnet", "anthropic/claude-3-5-sonnet-20241022", "--provider", "openrouter"}); err != nil {
+		t.Fatalf("alias set error = %v", err)
+	}
+	if err := app.runModels([]string{"set", "sonnet", "-p", "openrouter", "--force"}); err != nil {
+		t.Fatalf("models set error = %v", err)
+	}
+	if got := app.cfg.GetModel("openrouter"); got != "anthropic/claude-3-5-sonnet-20241022" {
+		t.Fatalf("model = %q, want expanded alias", got)
+	}
+
+	stdout.Reset()
+	if err := app.runModels([]string{"alias"}); err != nil {
+		t.Fatalf("alias list error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "openrouter  sonnet  anthropic/claude-3-5-sonnet-20241022") {
+		t.Fatalf("alias list = %q", stdout.String())
+	}
+
+	if err := app.runModels([]string{"alias", "rm", "sonnet", "--provider", "openrouter"}); err != nil {
+		t.Fatalf("alias rm error = %v", err)
+	}
+	if err := app.runModels([]string{"alias", "rm", "sonnet", "--provider", "openrouter"}); err == nil {
+		t.Fatal("expected error removing a missing alias")
+	}
+}
+
+func TestModelAliasSetAllowsSubcommandNames(t *testing.T) {
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: config.DefaultConfig()}
+
+	for _, alias := range []string{"rm", "list"} {
+		if err := app.runModels([]string{"alias", "set", alias, "gpt-4o-mini", "--provider", "openai"}); err != nil {
+			t.Fatalf("alias set %s error = %v", alias, err)
+		}
+		if got := app.cfg.ResolveModel("openai", alias); got != "gpt-4o-mini" {
+			t.Fatalf("ResolveModel(%q) = %q", alias, got)
+		}
+	}
+	if err := app.runModels([]string{"alias", "rm", "rm", "--provider", "openai"}); err != nil {
+		t.Fatalf("alias rm error = %v", err)
+	}
+	if got := app.cfg.ResolveModel("openai", "rm"); got != "rm" {
+		t.Fatalf("alias rm survived removal: %q", got)
+	}
+}
+
+func TestSetModelVerifiesAgainstModelList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "gpt-4o-mini"}, {"id": "gpt-4o"}}})
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("stub", config.OpenAICompatibleProvider{BaseURL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.ModelCacheTTL = -1
+	var stderr bytes.Buffer
+	app := &App{stdout: &bytes.Buffer{}, stderr: &stderr, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: cfg}
+
+	if err := app.setModel("stub", "GPT-4o-Mini", false); err != nil {
+		t.Fatalf("setModel error = %v", err)
+	}
+	if got := cfg.GetModel("stub"); got != "gpt-4o-mini" {
+		t.Fatalf("model = %q, want listed ID", got)
+	}
+
+	err := app.setModel("stub", "gpt-4o-mnii", false)
+	if err == nil || !strings.Contains(err.Error(), `did you mean "gpt-4o-mini"`) {
+		t.Fatalf("typo err = %v", err)
+	}
+	if err := app.setModel("stub", "my-private-model", true); err != nil || cfg.GetModel("stub") != "my-private-model" {
+		t.Fatalf("--force err = %v, model = %q", err, cfg.GetModel("stub"))
+	}
+
+	server.Close()
+	if err := app.setModel("stub", "offline-model", false); err != nil {
+		t.Fatalf("offline setModel error = %v", err)
+	}
+	if !strings.Contains(stderr.String(), "warning: unable to verify model offline-model") {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	ids := []string{"claude-3-5-sonnet", "claude-3-5-haiku", "gpt-4o"}
+	if got := closestMatch("claude-3-5-sonet", ids); got != "claude-3-5-sonnet" {
+		t.Fatalf("closestMatch = %q", got)
+	}
+	if got := closestMatch("llama3", ids); got != "" {
+		t.Fatalf("closestMatch for unrelated model = %q", got)
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "m1"}, {"id": "m2", "context_length": 8192}}})
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("stub", config.OpenAICompatibleProvider{BaseURL: server.URL, Model: "m2"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetCurrentProvider("stub")
+	cfg.ModelCacheTTL = -1
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	if err := app.runModels([]string{"list", "--json"}); err != nil {
+		t.Fatalf("models list --json error = %v", err)
+	}
+	var models []modelView
+	if err := json.Unmarshal(stdout.Bytes(), &models); err != nil {
+		t.Fatalf("decode %q: %v", stdout.String(), err)
+	}
+	if len(models) != 2 || models[1].ID != "m2" || !models[1].Current || models[1].ContextLength != 8192 {
+		t.Fatalf("models = %+v", models)
+	}
+
+	stdout.Reset()
+	if err := app.runProviders([]string{"list", "--json"}); err != nil {
+		t.Fatalf("provider list --json error = %v", err)
+	}
+	var views []providerView
+	if err := json.Unmarshal(stdout.Bytes(), &views); err != nil {
+		t.Fatalf("decode %q: %v", stdout.String(), err)
+	}
+	var stub *providerView
+	for i := range views {
+		if views[i].Name == "stub" {
+			stub = &views[i]
+		}
+	}
+	if stub == nil || !stub.Current || stub.Type != "custom-openai-compatible" || stub.BaseURL != server.URL {
+		t.Fatalf("providers = %+v", views)
+	}
+}
+
+func TestModelsPull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"pulling manifest"}` + "\n" +
+			`{"status":"pulling abc","total":2048,"completed":1024}` + "\n" +
+			`{"status":"pulling abc","total":2048,"completed":2048}` + "\n" +
+			`{"status":"success"}` + "\n"))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.SetBaseURL("ollama", server.URL)
+	var stdout, stderr bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &stderr, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: cfg}
+
+	if err := app.runModels([]string{"pull", "llama3.2", "--provider", "ollama"}); err != nil {
+		t.Fatalf("models pull error = %v", err)
+	}
+	if stdout.String() != "pulled llama3.2\n" {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+	if stderr.String() != "pulling manifest\npulling abc\nsuccess\n" {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+
+	err := app.runModels([]string{"pull", "gpt-4o", "--provider", "openai"})
+	if err == nil || !strings.Contains(err.Error(), "only available for ollama") {
+		t.Fatalf("openai pull err = %v", err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 1536: "1.5 KB", 4 << 30: "4.0 GB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteOutputFileCreatesParents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "out", "result.txt")
+	if err := writeOutputFile(path, []byte("answer\n")); err != nil {
+		t.Fatalf("writeOutputFile error = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "answer\n" {
+		t.Fatalf("content = %q, err = %v", got, err)
+	}
+
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	err = writeOutputFile(filepath.Join(blocker, "result.txt"), []byte("x"))
+	if err == nil || !strings.HasPrefix(err.Error(), "--output ") {
+		t.Fatalf("error = %v, want --output error", err)
+	}
+}
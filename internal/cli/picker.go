@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// picker is the state of the full-screen fuzzy selector: the query typed so
+// far, the items matching it, and the highlighted row.
+type picker struct {
+	items   []string
+	query   string
+	matches []int // indexes into items, best match first
+	cursor  int   // index into matches
+	offset  int   // first visible match
+}
+
+// pickerKey is one decoded keypress.
+type pickerKey int
+
+const (
+	keyRune pickerKey = iota
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyClear
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyIgnore
+)
+
+func newPicker(items []string, query string) *picker {
+	p := &picker{items: items, query: query}
+	p.filter()
+	return p
+}
+
+// filter recomputes matches for the current query and resets the cursor.
+func (p *picker) filter() {
+	type scored struct {
+		index int
+		score int
+	}
+	var hits []scored
+	for i, item := range p.items {
+		if score, ok := fuzzyScore(item, p.query); ok {
+			hits = append(hits, scored{i, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	p.matches = p.matches[:0]
+	for _, hit := range hits {
+		p.matches = append(p.matches, hit.index)
+	}
+	p.cursor, p.offset = 0, 0
+}
+
+// handle applies key to the picker. It reports done when the user chose an
+// item or cancelled; chosen is empty on cancel.
+func (p *picker) handle(key pickerKey, r rune, page int) (done bool, chosen string) {
+	switch key {
+	case keyEnter:
+		if len(p.matches) == 0 {
+			return false, ""
+		}
+		return true, p.items[p.matches[p.cursor]]
+	case keyCancel:
+		return true, ""
+	case keyRune:
+		p.query += string(r)
+		p.filter()
+	case keyBackspace:
+		if p.query != "" {
+			_, size := utf8.DecodeLastRuneInString(p.query)
+			p.query = p.query[:len(p.query)-size]
+			p.filter()
+		}
+	case keyClear:
+		p.query = ""
+		p.filter()
+	case keyUp:
+		p.move(-1, page)
+	case keyDown:
+		p.move(1, page)
+	case keyPageUp:
+		p.move(-page, page)
+	case keyPageDown:
+		p.move(page, page)
+	}
+	return false, ""
+}
+
+// move shifts the cursor by delta, clamped to the matches, and scrolls so it
+// stays within a window of page rows.
+func (p *picker) move(delta, page int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = max(0, min(len(p.matches)-1, p.cursor+delta))
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if page > 0 && p.cursor >= p.offset+page {
+		p.offset = p.cursor - page + 1
+	}
+}
+
+// fuzzyScore reports whether every rune of query appears in item in order,
+// ignoring case, and scores the match: consecutive runs, matches at word
+// starts and an early first match rank higher.
+func fuzzyScore(item, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	target := []rune(strings.ToLower(item))
+	want := []rune(strings.ToLower(query))
+	score, next, prev := 0, 0, -2
+	for i, r := range target {
+		if next == len(want) {
+			break
+		}
+		if r != want[next] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 3
+		}
+		if i == 0 || (!unicode.IsLetter(target[i-1]) && !unicode.IsDigit(target[i-1])) {
+			score += 2
+		}
+		if next == 0 {
+			score -= min(i, 10)
+		}
+		prev = i
+		next++
+	}
+	return score, next == len(want)
+}
+
+// decodeKey maps one read from a raw-mode terminal to a key.
+func decodeKey(buf []byte) (pickerKey, rune) {
+	switch s := string(buf); s {
+	case "\r", "\n":
+		return keyEnter, 0
+	case "\x1b", "\x03", "\x04":
+		return keyCancel, 0
+	case "\x7f", "\x08":
+		return keyBackspace, 0
+	case "\x15":
+		return keyClear, 0
+	case "\x1b[A", "\x1bOA", "\x10":
+		return keyUp, 0
+	case "\x1b[B", "\x1bOB", "\x0e":
+		return keyDown, 0
+	case "\x1b[5~":
+		return keyPageUp, 0
+	case "\x1b[6~":
+		return keyPageDown, 0
+	}
+	r, size := utf8.DecodeRune(buf)
+	if r == utf8.RuneError || size != len(buf) || !unicode.IsPrint(r) {
+		return keyIgnore, 0
+	}
+	return keyRune, r
+}
+
+// canUsePicker reports whether the full-screen picker can run: both stdin and
+// stdout must be terminals.
+func (a *App) canUsePicker() bool {
+	in, ok := a.stdin.(*os.File)
+	return ok && term.IsTerminal(int(in.Fd())) && isTerminalWriter(a.stdout)
+}
+
+// runPicker shows items in a full-screen fuzzy selector on the alternate
+// screen and returns the chosen item, or "" when the user cancelled.
+func (a *App) runPicker(title string, items []string, query string) (string, error) {
+	in := a.stdin.(*os.File)
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("enter raw terminal mode: %w", err)
+	}
+	fmt.Fprint(a.stdout, "\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Fprint(a.stdout, "\033[?25h\033[?1049l")
+		_ = term.Restore(int(in.Fd()), state)
+	}()
+
+	p := newPicker(items, query)
+	buf := make([]byte, 64)
+	for {
+		page := a.drawPicker(title, p)
+		n, err := in.Read(buf)
+		if err != nil {
+			if err == io.EOF {
+				return "", nil
+			}
+			return "", fmt.Errorf("read key: %w", err)
+		}
+		key, r := decodeKey(buf[:n])
+		if done, chosen := p.handle(key, r, page); done {
+			return chosen, nil
+		}
+	}
+}
+
+// drawPicker renders p and returns the number of list rows shown.
+func (a *App) drawPicker(title string, p *picker) int {
+	width, height := 80, 24
+	if out, ok := a.stdout.(*os.File); ok {
+		if w, h, err := term.GetSize(int(out.Fd())); err == nil && w > 0 && h > 0 {
+			width, height = w, h
+		}
+	}
+	page := max(1, height-3)
+	p.move(0, page)
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&b, "%s  %d/%d\r\n", title, len(p.matches), len(p.items))
+	fmt.Fprintf(&b, "> %s\r\n", p.query)
+	for row := 0; row < page && p.offset+row < len(p.matches); row++ {
+		i := p.offset + row
+		line := truncateForList(p.items[p.matches[i]], max(width-2, 10))
+		if i == p.cursor {
+			fmt.Fprintf(&b, "\033[7m> %s\033[0m\r\n", line)
+		} else {
+			fmt.Fprintf(&b, "  %s\r\n", line)
+		}
+	}
+	fmt.Fprintf(&b, "\033[%d;1H\033[2mup/down move, type to filter, enter select, esc cancel\033[0m", height)
+	fmt.Fprint(a.stdout, b.String())
+	return page
+}
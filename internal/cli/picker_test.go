@@ -0,0 +1,76 @@
+package cli
+
+import "testing"
+
+func TestFuzzyScoreRanksTighterMatchesHigher(t *testing.T) {
+	if _, ok := fuzzyScore("gpt-4o-mini", "g4m"); !ok {
+		t.Fatal("g4m should match gpt-4o-mini")
+	}
+	if _, ok := fuzzyScore("gpt-4o-mini", "mg"); ok {
+		t.Fatal("out-of-order query should not match")
+	}
+	tight, _ := fuzzyScore("gpt-4o-mini", "mini")
+	loose, _ := fuzzyScore("llama-3-instruct", "mini")
+	if tight <= loose {
+		t.Fatalf("expected consecutive match to score higher: %d <= %d", tight, loose)
+	}
+}
+
+func TestPickerFiltersAndChooses(t *testing.T) {
+	p := newPicker([]string{"gpt-4o", "gpt-4o-mini", "o3-mini"}, "")
+	for _, r := range "mini" {
+		p.handle(keyRune, r, 10)
+	}
+	if len(p.matches) != 2 {
+		t.Fatalf("expected 2 matches for mini, got %d", len(p.matches))
+	}
+	p.handle(keyDown, 0, 10)
+	done, chosen := p.handle(keyEnter, 0, 10)
+	if !done || chosen != p.items[p.matches[1]] {
+		t.Fatalf("unexpected choice %q (done=%v)", chosen, done)
+	}
+
+	p.handle(keyClear, 0, 10)
+	if p.query != "" || len(p.matches) != 3 {
+		t.Fatalf("clear should reset the filter, got %q with %d matches", p.query, len(p.matches))
+	}
+	if done, chosen := p.handle(keyCancel, 0, 10); !done || chosen != "" {
+		t.Fatalf("cancel should finish without a choice, got %q", chosen)
+	}
+}
+
+func TestPickerScrollsWithinPage(t *testing.T) {
+	p := newPicker([]string{"a", "b", "c", "d", "e"}, "")
+	for range 3 {
+		p.handle(keyDown, 0, 2)
+	}
+	if p.cursor != 3 || p.offset != 2 {
+		t.Fatalf("cursor=%d offset=%d, want 3 and 2", p.cursor, p.offset)
+	}
+	p.handle(keyPageDown, 0, 2)
+	if p.cursor != 4 {
+		t.Fatalf("page down should clamp to the last match, got %d", p.cursor)
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	cases := []struct {
+		in   string
+		key  pickerKey
+		rune rune
+	}{
+		{"\r", keyEnter, 0},
+		{"\x1b", keyCancel, 0},
+		{"\x1b[A", keyUp, 0},
+		{"\x1b[B", keyDown, 0},
+		{"\x7f", keyBackspace, 0},
+		{"é", keyRune, 'é'},
+		{"\x1b[Z", keyIgnore, 0},
+	}
+	for _, tc := range cases {
+		key, r := decodeKey([]byte(tc.in))
+		if key != tc.key || r != tc.rune {
+			t.Errorf("decodeKey(%q) = %v, %q; want %v, %q", tc.in, key, r, tc.key, tc.rune)
+		}
+	}
+}
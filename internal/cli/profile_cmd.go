@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func (a *App) runConfigProfile(args []string) error {
+	if len(args) == 0 {
+		return a.profileList()
+	}
+	sub := strings.ToLower(strings.TrimSpace(args[0]))
+	switch sub {
+	case "list", "ls":
+		return a.profileList()
+	case "use":
+		if len(args) != 2 {
+			return usageError("ask config profile use <name>")
+		}
+		return a.profileUse(strings.ToLower(strings.TrimSpace(args[1])))
+	case "new":
+		if len(args) != 2 {
+			return usageError("ask config profile new <name>")
+		}
+		return a.profileNew(strings.ToLower(strings.TrimSpace(args[1])))
+	default:
+		return unknownSubcommand("config profile", sub)
+	}
+}
+
+func (a *App) profileList() error {
+	names, err := config.ListProfiles(a.basePath)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		marker := " "
+		if name == a.profile {
+			marker = "*"
+		}
+		fmt.Fprintf(a.stdout, "%s %s\n", marker, name)
+	}
+	return nil
+}
+
+func (a *App) profileUse(name string) error {
+	if name != config.DefaultProfile {
+		if err := config.ValidateProfileName(name); err != nil {
+			return err
+		}
+		if _, err := os.Stat(config.ProfilePath(a.basePath, name)); errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("profile %q does not exist; create it with `ask config profile new %s`", name, name)
+		}
+	}
+	if err := config.SetActiveProfile(a.basePath, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "active profile: %s\n", name)
+	if env := strings.TrimSpace(os.Getenv("ASK_PROFILE")); env != "" && !strings.EqualFold(env, name) {
+		fmt.Fprintf(a.stderr, "warning: ASK_PROFILE=%s overrides the active profile in this shell\n", env)
+	}
+	return nil
+}
+
+func (a *App) profileNew(name string) error {
+	if name == config.DefaultProfile {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+	path := config.ProfilePath(a.basePath, name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("profile %q already exists at %s", name, path)
+	}
+	if err := config.SaveLocked(path, config.DefaultConfig()); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "created profile %s at %s\n", name, path)
+	fmt.Fprintf(a.stdout, "switch to it with `ask config profile use %s` or `ask --profile %s ...`\n", name, name)
+	return nil
+}
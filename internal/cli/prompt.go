@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func (a *App) runPrompt(args []string) error {
+	if a.showTopicHelpIfAnyFlagRequested("prompt", args, 0) {
+		return nil
+	}
+	if len(args) == 0 {
+		return a.promptList()
+	}
+	sub := strings.ToLower(strings.TrimSpace(args[0]))
+	switch sub {
+	case "list", "ls":
+		return a.promptList()
+	case "add", "set":
+		if len(args) < 3 {
+			return usageError("ask prompt add <name> \"<template>\"")
+		}
+		return a.promptAdd(args[1], strings.Join(args[2:], " "))
+	case "rm", "remove":
+		if len(args) != 2 {
+			return usageError("ask prompt rm <name>")
+		}
+		return a.promptRemove(args[1])
+	default:
+		return unknownSubcommand("prompt", sub)
+	}
+}
+
+func (a *App) promptList() error {
+	if len(a.cfg.Prompts) == 0 {
+		fmt.Fprintln(a.stdout, "no prompt presets; add one with `ask prompt add <name> \"<template>\"`")
+		return nil
+	}
+	names := make([]string, 0, len(a.cfg.Prompts))
+	for name := range a.cfg.Prompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTEMPLATE")
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t%s\n", name, strings.Join(strings.Fields(a.cfg.Prompts[name]), " "))
+	}
+	return tw.Flush()
+}
+
+func (a *App) promptAdd(name, template string) error {
+	if err := a.updateConfig(func(c *config.Config) error { return c.SetPrompt(name, template) }); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "saved prompt preset %s\n", strings.ToLower(strings.TrimSpace(name)))
+	return nil
+}
+
+func (a *App) promptRemove(name string) error {
+	err := a.updateConfig(func(c *config.Config) error {
+		if !c.RemovePrompt(name) {
+			return fmt.Errorf("prompt preset %q does not exist", name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "removed prompt preset %s\n", strings.ToLower(strings.TrimSpace(name)))
+	return nil
+}
+
+// applyPreset wraps question with the named prompt preset.
+func (a *App) applyPreset(name, question string) (string, error) {
+	template, ok := a.cfg.Prompt(name)
+	if !ok {
+		return "", fmt.Errorf("prompt preset %q does not exist; see `ask prompt list`", name)
+	}
+	return config.ApplyPrompt(template, question), nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestPromptCommands(t *testing.T) {
+	var stdout bytes.Buffer
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: cfgPath, cfg: config.DefaultConfig()}
+
+	if err := app.runPrompt([]string{"add", "SQL", "Review this SQL: {question}"}); err != nil {
+		t.Fatalf("prompt add error = %v", err)
+	}
+	if err := app.runPrompt([]string{"add", "sre", "Answer", "as", "a", "senior", "SRE."}); err != nil {
+		t.Fatalf("prompt add error = %v", err)
+	}
+	loaded, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := loaded.Prompt("sql"); got != "Review this SQL: {question}" {
+		t.Fatalf("saved preset = %q", got)
+	}
+
+	stdout.Reset()
+	if err := app.runPrompt([]string{"list"}); err != nil {
+		t.Fatalf("prompt list error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "sql   Review this SQL: {question}") || !strings.Contains(stdout.String(), "sre   Answer as a senior SRE.") {
+		t.Fatalf("prompt list = %q", stdout.String())
+	}
+
+	got, err := app.applyPreset("sql", "SELECT 1")
+	if err != nil || got != "Review this SQL: SELECT 1" {
+		t.Fatalf("applyPreset = %q, %v", got, err)
+	}
+	got, err = app.applyPreset("sre", "why is p99 up?")
+	if err != nil || got != "Answer as a senior SRE.\n\nwhy is p99 up?" {
+		t.Fatalf("applyPreset = %q, %v", got, err)
+	}
+
+	if err := app.runPrompt([]string{"rm", "sql"}); err != nil {
+		t.Fatalf("prompt rm error = %v", err)
+	}
+	if err := app.runPrompt([]string{"rm", "sql"}); err == nil {
+		t.Fatal("expected error removing a missing preset")
+	}
+	if _, err := app.applyPreset("sql", "SELECT 1"); err == nil {
+		t.Fatal("expected error for an unknown preset")
+	}
+}
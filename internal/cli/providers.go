@@ -1,17 +1,19 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/providers"
 )
 
 func (a *App) runProviders(args []string) error {
 	if len(args) == 0 {
-		return a.providerList()
+		return a.providerList(false)
 	}
 	if a.showTopicHelpIfRequested("provider", args, 0) {
 		return nil
@@ -19,8 +21,21 @@ func (a *App) runProviders(args []string) error {
 
 	sub := strings.ToLower(strings.TrimSpace(args[0]))
 	switch sub {
-	case "list":
-		return a.providerList()
+	case "list", "ls":
+		if a.showTopicHelpIfAnyFlagRequested("provider", args, 1) {
+			return nil
+		}
+		asJSON := false
+		rest, err := scanOptions(args[1:], []optionSpec{
+			{Names: []string{"json"}, TakesValue: false, Set: func(string) error { asJSON = true; return nil }},
+		})
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+		}
+		return a.providerList(asJSON)
 	case "current":
 		fmt.Fprintln(a.stdout, a.cfg.CurrentProvider)
 		return nil
@@ -35,11 +50,11 @@ func (a *App) runProviders(args []string) error {
 		if !a.cfg.ProviderExists(name) {
 			return fmt.Errorf("provider %q is not configured", name)
 		}
-		a.cfg.SetCurrentProvider(name)
-		if err := a.saveConfig(); err != nil {
+		if err := a.updateConfig(func(c *config.Config) error { c.SetCurrentProvider(name); return nil }); err != nil {
 			return err
 		}
 		fmt.Fprintf(a.stdout, "current provider set to %s\n", name)
+		a.warnIncompleteProvider(name)
 		return nil
 	case "add":
 		if a.showTopicHelpIfRequested("provider", args, 1) {
@@ -54,19 +69,52 @@ func (a *App) runProviders(args []string) error {
 			return usageError("ask provider remove <name>")
 		}
 		name := strings.ToLower(strings.TrimSpace(args[1]))
-		if err := a.cfg.RemoveCustomProvider(name); err != nil {
+		if err := a.updateConfig(func(c *config.Config) error { return c.RemoveCustomProvider(name) }); err != nil {
 			return err
 		}
-		if err := a.saveConfig(); err != nil {
+		fmt.Fprintf(a.stdout, "removed provider %s\n", name)
+		return nil
+	case "rename", "mv":
+		if a.showTopicHelpIfRequested("provider", args, 1) {
+			return nil
+		}
+		if len(args) != 3 {
+			return usageError("ask provider rename <old> <new>")
+		}
+		oldName := strings.ToLower(strings.TrimSpace(args[1]))
+		newName := strings.ToLower(strings.TrimSpace(args[2]))
+		if err := a.updateConfig(func(c *config.Config) error { return c.RenameCustomProvider(oldName, newName) }); err != nil {
 			return err
 		}
-		fmt.Fprintf(a.stdout, "removed provider %s\n", name)
+		fmt.Fprintf(a.stdout, "renamed provider %s to %s\n", oldName, newName)
+		return nil
+	case "clone", "cp":
+		if a.showTopicHelpIfRequested("provider", args, 1) {
+			return nil
+		}
+		if len(args) != 3 {
+			return usageError("ask provider clone <src> <dst>")
+		}
+		src := strings.ToLower(strings.TrimSpace(args[1]))
+		dst := strings.ToLower(strings.TrimSpace(args[2]))
+		if err := a.updateConfig(func(c *config.Config) error { return c.CloneProvider(src, dst) }); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "cloned provider %s to %s\n", src, dst)
 		return nil
+	case "export":
+		if a.showTopicHelpIfRequested("provider", args, 1) {
+			return nil
+		}
+		if len(args) != 2 {
+			return usageError("ask provider export <name>")
+		}
+		return a.providerExport(strings.ToLower(strings.TrimSpace(args[1])))
 	case "show", "inspect":
 		if a.showTopicHelpIfRequested("provider", args, 1) {
 			return nil
 		}
-		name := strings.TrimSpace(a.cfg.CurrentProvider)
+		name := a.defaultProvider()
 		if len(args) > 1 {
 			name = strings.TrimSpace(args[1])
 		}
@@ -76,100 +124,199 @@ func (a *App) runProviders(args []string) error {
 	}
 }
 
-func (a *App) providerList() error {
-	names := a.cfg.ProviderNames()
-	tw := tabwriter.NewWriter(a.stdout, 0, 2, 2, ' ', 0)
-	fmt.Fprintln(tw, "CURRENT\tNAME\tTYPE\tMODEL\tBASE_URL")
-	for _, name := range names {
-		marker := ""
-		if name == a.cfg.CurrentProvider {
-			marker = "*"
-		}
-		base := a.cfg.ResolveBaseURL(name)
-		model := a.cfg.GetModel(name)
-		ptype := "builtin"
-		if _, ok := a.cfg.CustomProviders[name]; ok {
-			ptype = "custom-openai-compatible"
+// warnIncompleteProvider prints hints when provider is missing the API key
+// or default model the next ask will need. It never fails the command.
+func (a *App) warnIncompleteProvider(provider string) {
+	if providers.RequiresAPIKey(provider) && a.cfg.ResolveAPIKey(provider) == "" {
+		if envVar := a.apiKeyEnv(provider); envVar != "" {
+			fmt.Fprintf(a.stderr, "warning: no API key configured for %s; set %s or run 'ask key set %s'\n", provider, envVar, provider)
+		} else {
+			fmt.Fprintf(a.stderr, "warning: no API key configured for %s; run 'ask key set %s'\n", provider, provider)
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", marker, name, ptype, model, base)
 	}
-	return tw.Flush()
-}
-
-func (a *App) providerShow(name string) error {
-	name = strings.ToLower(strings.TrimSpace(name))
-	if name == "" {
-		return fmt.Errorf("provider name is required")
-	}
-	if !a.cfg.ProviderExists(name) {
-		return fmt.Errorf("provider %q is not configured", name)
+	if strings.TrimSpace(a.cfg.GetModel(provider)) == "" {
+		fmt.Fprintf(a.stderr, "warning: no default model for %s; run 'ask models select'\n", provider)
 	}
+}
 
-	type providerView struct {
-		Name      string `json:"name"`
-		Current   bool   `json:"current"`
-		Model     string `json:"model,omitempty"`
-		BaseURL   string `json:"base_url,omitempty"`
-		APIKeyEnv string `json:"api_key_env,omitempty"`
-		HasAPIKey bool   `json:"has_api_key"`
-		Custom    bool   `json:"custom"`
-	}
+// providerView is the JSON shape of a provider in `provider show` and
+// `provider list --json`.
+type providerView struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Current   bool   `json:"current"`
+	Model     string `json:"model,omitempty"`
+	BaseURL   string `json:"base_url,omitempty"`
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+	HasAPIKey bool   `json:"has_api_key"`
+	KeySource string `json:"key_source,omitempty"`
+	Custom    bool   `json:"custom"`
+}
 
+func (a *App) providerView(name string) providerView {
 	view := providerView{
 		Name:    name,
+		Type:    "builtin",
 		Current: a.cfg.CurrentProvider == name,
 		Model:   a.cfg.GetModel(name),
 		BaseURL: a.cfg.ResolveBaseURL(name),
 		Custom:  false,
 	}
+	view.KeySource = a.apiKeySource(name)
+	view.HasAPIKey = view.KeySource != ""
 
 	if custom, ok := a.cfg.CustomProviders[name]; ok {
+		view.Type = "custom-openai-compatible"
 		view.Custom = true
 		view.APIKeyEnv = custom.APIKeyEnv
-		view.HasAPIKey = strings.TrimSpace(custom.APIKey) != ""
 	} else {
 		pc := a.cfg.Providers[name]
 		view.APIKeyEnv = strings.TrimSpace(pc.APIKeyEnv)
-		view.HasAPIKey = strings.TrimSpace(pc.APIKey) != ""
 		if view.APIKeyEnv == "" {
 			if defaults, ok := config.BuiltinProviderDefaults(name); ok {
 				view.APIKeyEnv = defaults.APIKeyEnv
 			}
 		}
 	}
+	return view
+}
+
+// apiKeySource returns where provider's API key resolves from: "env",
+// "config" or "keychain", or "" when no key is set.
+func (a *App) apiKeySource(provider string) string {
+	_, source := a.cfg.ResolveAPIKeySource(provider)
+	kind, _, _ := strings.Cut(source, ":")
+	return kind
+}
+
+// keyStatus summarizes where provider's API key would be resolved from:
+// "env", "config", "keychain", "-" when none is set, or "n/a" when none is set
+// and the provider does not require one.
+// It shares apiKeySource with providerView so the table and JSON agree.
+func (a *App) keyStatus(provider string) string {
+	switch source := a.apiKeySource(provider); {
+	case source != "":
+		return source
+	case !providers.RequiresAPIKey(provider):
+		return "n/a"
+	default:
+		return "-"
+	}
+}
+
+func (a *App) providerList(asJSON bool) error {
+	names := a.cfg.ProviderNames()
+	if asJSON {
+		views := make([]providerView, 0, len(names))
+		for _, name := range names {
+			views = append(views, a.providerView(name))
+		}
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(views)
+	}
+
+	t := newTable()
+	t.row(rowPlain, "CURRENT\tNAME\tTYPE\tMODEL\tKEY\tBASE_URL")
+	for _, name := range names {
+		view := a.providerView(name)
+		key := a.keyStatus(name)
+		marker, style := "", rowPlain
+		switch {
+		case view.Current:
+			marker, style = "*", rowCurrent
+		case key == "-" && providers.RequiresAPIKey(name):
+			style = rowDim
+		}
+		t.row(style, "%s\t%s\t%s\t%s\t%s\t%s", marker, name, view.Type, view.Model, key, view.BaseURL)
+	}
+	return t.flush(a.stdout, a.colorEnabled())
+}
+
+func (a *App) providerShow(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("provider name is required")
+	}
+	if !a.cfg.ProviderExists(name) {
+		return fmt.Errorf("provider %q is not configured", name)
+	}
 
 	enc := json.NewEncoder(a.stdout)
 	enc.SetIndent("", "  ")
-	return enc.Encode(view)
+	return enc.Encode(a.providerView(name))
 }
 
 func (a *App) providerAdd(args []string) error {
 	if len(args) == 0 {
-		return usageError("ask provider add <name> --base-url <url> [options]")
+		return usageError("ask provider add <name> (--base-url <url> | --from-file <path>) [options]")
 	}
 	name := strings.ToLower(strings.TrimSpace(args[0]))
 	if name == "" {
 		return fmt.Errorf("provider name is required")
 	}
 
-	input := config.OpenAICompatibleProvider{Headers: map[string]string{}}
+	// Flags are collected as overrides and applied after --from-file so they
+	// win regardless of their position on the command line.
+	var (
+		fromFile  string
+		overrides []func(*config.OpenAICompatibleProvider)
+	)
+	set := func(apply func(*config.OpenAICompatibleProvider)) error {
+		overrides = append(overrides, apply)
+		return nil
+	}
 
 	rest, err := scanOptions(args[1:], []optionSpec{
-		{Names: []string{"base-url"}, TakesValue: true, Set: func(v string) error { input.BaseURL = strings.TrimSpace(v); return nil }},
-		{Names: []string{"model"}, TakesValue: true, Set: func(v string) error { input.Model = strings.TrimSpace(v); return nil }},
-		{Names: []string{"api-key"}, TakesValue: true, Set: func(v string) error { input.APIKey = strings.TrimSpace(v); return nil }},
-		{Names: []string{"api-key-env"}, TakesValue: true, Set: func(v string) error { input.APIKeyEnv = strings.TrimSpace(v); return nil }},
-		{Names: []string{"models-path"}, TakesValue: true, Set: func(v string) error { input.ModelsPath = strings.TrimSpace(v); return nil }},
-		{Names: []string{"chat-path"}, TakesValue: true, Set: func(v string) error { input.ChatPath = strings.TrimSpace(v); return nil }},
-		{Names: []string{"auth-header"}, TakesValue: true, Set: func(v string) error { input.AuthHeader = strings.TrimSpace(v); return nil }},
-		{Names: []string{"auth-prefix"}, TakesValue: true, Set: func(v string) error { input.AuthPrefix = v; return nil }},
+		{Names: []string{"from-file"}, TakesValue: true, Set: func(v string) error { fromFile = strings.TrimSpace(v); return nil }},
+		{Names: []string{"base-url"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.BaseURL = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"model"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.Model = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"api-key"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.APIKey = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"api-key-env"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.APIKeyEnv = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"models-path"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.ModelsPath = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"chat-path"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.ChatPath = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"models-response-key"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.ModelsResponseKey = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"model-id-field"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.ModelIDField = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"auth-header"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.AuthHeader = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"auth-prefix"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.AuthPrefix = v })
+		}},
+		{Names: []string{"auth-query-param"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.AuthQueryParam = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"basic-user"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.BasicUser = strings.TrimSpace(v) })
+		}},
+		{Names: []string{"basic-password"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.BasicPassword = v })
+		}},
+		{Names: []string{"basic-password-env"}, TakesValue: true, Set: func(v string) error {
+			return set(func(p *config.OpenAICompatibleProvider) { p.BasicPasswordEnv = strings.TrimSpace(v) })
+		}},
 		{Names: []string{"header"}, TakesValue: true, Set: func(v string) error {
 			k, val, err := parseKV(v)
 			if err != nil {
 				return fmt.Errorf("--header: %w", err)
 			}
-			input.Headers[k] = val
-			return nil
+			return set(func(p *config.OpenAICompatibleProvider) { p.Headers[k] = val })
 		}},
 	})
 	if err != nil {
@@ -179,12 +326,63 @@ func (a *App) providerAdd(args []string) error {
 		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
 	}
 
-	if err := a.cfg.AddCustomProvider(name, input); err != nil {
-		return err
+	input := config.OpenAICompatibleProvider{}
+	if fromFile != "" {
+		if input, err = readProviderFile(fromFile); err != nil {
+			return err
+		}
 	}
-	if err := a.saveConfig(); err != nil {
+	if input.Headers == nil {
+		input.Headers = map[string]string{}
+	}
+	for _, apply := range overrides {
+		apply(&input)
+	}
+
+	if err := a.updateConfig(func(c *config.Config) error { return c.AddCustomProvider(name, input) }); err != nil {
 		return err
 	}
 	fmt.Fprintf(a.stdout, "added provider %s\n", name)
+	if conflicts := a.cfg.BaseURLConflicts(name); len(conflicts) > 0 {
+		fmt.Fprintf(a.stderr, "warning: %s uses the same base_url as %s\n", name, strings.Join(conflicts, ", "))
+	}
 	return nil
 }
+
+// readProviderFile decodes a custom provider definition in the shape written by
+// `provider export`. Unknown keys are rejected so typos do not go unnoticed.
+func readProviderFile(path string) (config.OpenAICompatibleProvider, error) {
+	var input config.OpenAICompatibleProvider
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return input, fmt.Errorf("--from-file: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return input, fmt.Errorf("--from-file %s: %w", path, err)
+	}
+	if dec.More() {
+		return input, fmt.Errorf("--from-file %s: unexpected data after provider object", path)
+	}
+	return input, nil
+}
+
+// providerExport prints a custom provider in the shape accepted by
+// `provider add --from-file`. Stored secrets are left out.
+func (a *App) providerExport(name string) error {
+	custom, ok := a.cfg.CustomProviders[name]
+	if !ok {
+		if a.cfg.ProviderExists(name) {
+			return fmt.Errorf("%q is a built-in provider; only custom providers can be exported", name)
+		}
+		return fmt.Errorf("provider %q is not configured", name)
+	}
+	custom.APIKey = ""
+	custom.APIKeyRef = ""
+	custom.BasicPassword = ""
+
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(custom)
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+	"github.com/sasanktumpati/ask/internal/secrets"
+)
+
+func TestProviderAddFromFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("proxy", config.OpenAICompatibleProvider{
+		BaseURL:   "https://llm.example.com/v1",
+		APIKey:    "secret",
+		APIKeyEnv: "PROXY_KEY",
+		Headers:   map[string]string{"X-Team": "core", "X-Env": "prod"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfg: cfg, cfgPath: filepath.Join(dir, "config.json")}
+
+	if err := app.runProviders([]string{"export", "proxy"}); err != nil {
+		t.Fatalf("provider export error = %v", err)
+	}
+	if strings.Contains(stdout.String(), "secret") {
+		t.Fatalf("export leaked API key: %s", stdout.String())
+	}
+	path := filepath.Join(dir, "proxy.json")
+	if err := os.WriteFile(path, stdout.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.runProviders([]string{"add", "staging", "--header", "X-Env=staging", "--from-file", path}); err != nil {
+		t.Fatalf("provider add --from-file error = %v", err)
+	}
+	got := cfg.CustomProviders["staging"]
+	if got.BaseURL != "https://llm.example.com/v1" || got.APIKeyEnv != "PROXY_KEY" || got.APIKey != "" {
+		t.Fatalf("staging = %+v", got)
+	}
+	if got.Headers["X-Team"] != "core" || got.Headers["X-Env"] != "staging" {
+		t.Fatalf("headers = %v, want file headers with the flag override", got.Headers)
+	}
+
+	typo := filepath.Join(dir, "typo.json")
+	if err := os.WriteFile(typo, []byte(`{"base_url":"https://x","api_key_evn":"X"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := app.runProviders([]string{"add", "typo", "--from-file", typo})
+	if err == nil || !strings.Contains(err.Error(), "api_key_evn") {
+		t.Fatalf("unknown key error = %v", err)
+	}
+}
+
+func TestProviderSetWarnsWhenKeyOrModelMissing(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cfg := config.DefaultConfig()
+	var stdout, stderr bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &stderr, cfg: cfg, cfgPath: filepath.Join(t.TempDir(), "config.json")}
+
+	if err := app.runProviders([]string{"set", "anthropic"}); err != nil {
+		t.Fatalf("provider set error = %v", err)
+	}
+	if cfg.CurrentProvider != "anthropic" {
+		t.Fatalf("current provider = %q", cfg.CurrentProvider)
+	}
+	for _, want := range []string{"no API key configured for anthropic", "no default model for anthropic"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Fatalf("stderr = %q, want %q", stderr.String(), want)
+		}
+	}
+
+	stderr.Reset()
+	if err := cfg.AddCustomProvider("local", config.OpenAICompatibleProvider{BaseURL: "http://localhost:8080/v1", Model: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.runProviders([]string{"set", "local"}); err != nil {
+		t.Fatalf("provider set error = %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("unexpected warnings for a complete provider: %q", stderr.String())
+	}
+}
+
+func TestProviderAddWarnsOnDuplicateBaseURL(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("proxy", config.OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1"}); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	app := &App{stdout: &bytes.Buffer{}, stderr: &stderr, cfg: cfg, cfgPath: filepath.Join(t.TempDir(), "config.json")}
+
+	if err := app.runProviders([]string{"add", "proxy2", "--base-url", "https://LLM.example.com/v1/"}); err != nil {
+		t.Fatalf("provider add error = %v", err)
+	}
+	if !strings.Contains(stderr.String(), "proxy2 uses the same base_url as proxy") {
+		t.Fatalf("stderr = %q, want duplicate base_url warning", stderr.String())
+	}
+
+	stderr.Reset()
+	if err := app.runProviders([]string{"add", "oai", "--base-url", "https://api.openai.com/v1"}); err != nil {
+		t.Fatalf("provider add error = %v", err)
+	}
+	if !strings.Contains(stderr.String(), "same base_url as openai") {
+		t.Fatalf("stderr = %q, want conflict with built-in openai", stderr.String())
+	}
+
+	stderr.Reset()
+	if err := app.runProviders([]string{"add", "other", "--base-url", "https://other.example.com/v1"}); err != nil {
+		t.Fatalf("provider add error = %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("unexpected warning: %q", stderr.String())
+	}
+}
+
+func TestKeyStatusNotApplicableWithoutRequiredKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("local", config.OpenAICompatibleProvider{BaseURL: "http://127.0.0.1:8080/v1"}); err != nil {
+		t.Fatalf("AddCustomProvider error = %v", err)
+	}
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	want := map[string]string{"bedrock": "n/a", "vertex": "n/a", "local": "n/a", "anthropic": "-"}
+	for name, status := range want {
+		if got := app.keyStatus(name); got != status {
+			t.Errorf("keyStatus(%q) = %q, want %q", name, got, status)
+		}
+	}
+}
+
+func TestProviderListShowsKeyStatus(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cfg := config.DefaultConfig()
+	cfg.SetAPIKey("gemini", "stored")
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	if err := app.runProviders([]string{"list"}); err != nil {
+		t.Fatalf("provider list error = %v", err)
+	}
+	want := map[string]string{"openai": "env", "gemini": "config", "anthropic": "-", "ollama": "n/a"}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, "*"))
+		if len(fields) < 4 {
+			continue
+		}
+		if status, ok := want[fields[0]]; ok {
+			if !strings.Contains(line, " "+status+" ") {
+				t.Errorf("%s row = %q, want KEY %s", fields[0], line, status)
+			}
+			delete(want, fields[0])
+		}
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing rows %v in:\n%s", want, stdout.String())
+	}
+}
+
+func TestProviderListJSONReportsResolvedKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cfg := config.DefaultConfig()
+	cfg.SetAPIKey("gemini", "stored")
+	var stdout bytes.Buffer
+	app := &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	if err := app.runProviders([]string{"list", "--json"}); err != nil {
+		t.Fatalf("provider list error = %v", err)
+	}
+	var views []providerView
+	if err := json.Unmarshal(stdout.Bytes(), &views); err != nil {
+		t.Fatalf("decode %q: %v", stdout.String(), err)
+	}
+	want := map[string]string{"openai": "env", "gemini": "config", "anthropic": ""}
+	for _, view := range views {
+		source, ok := want[view.Name]
+		if !ok {
+			continue
+		}
+		if view.KeySource != source || view.HasAPIKey != (source != "") {
+			t.Errorf("%s: key_source = %q, has_api_key = %v; want %q", view.Name, view.KeySource, view.HasAPIKey, source)
+		}
+		delete(want, view.Name)
+	}
+	if len(want) > 0 {
+		t.Fatalf("providers missing from list: %v", want)
+	}
+}
+
+func TestKeyStatusMatchesProviderView(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	fake := secrets.NewMemory()
+	if err := fake.Store("groq", "gsk-keychain"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(config.SetKeychain(fake))
+
+	cfg := config.DefaultConfig()
+	cfg.SetAPIKey("gemini", "stored")
+	cfg.SetAPIKeyRef("groq", secrets.Ref("groq"))
+	app := &App{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, cfg: cfg}
+
+	want := map[string]string{"openai": "env", "groq": "keychain", "gemini": "config", "ollama": "n/a", "anthropic": "-"}
+	for name, status := range want {
+		if got := app.keyStatus(name); got != status {
+			t.Errorf("keyStatus(%q) = %q, want %q", name, got, status)
+		}
+		source := status
+		if status == "-" || status == "n/a" {
+			source = ""
+		}
+		if view := app.providerView(name); view.KeySource != source || view.HasAPIKey != (source != "") {
+			t.Errorf("%s: view key_source = %q, has_api_key = %v; table says %q", name, view.KeySource, view.HasAPIKey, status)
+		}
+	}
+}
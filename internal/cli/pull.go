@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sasanktumpati/ask/internal/modelcache"
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+// pullModel downloads model with the provider's pull API (Ollama only),
+// showing progress on stderr. Ctrl+C cancels the download.
+func (a *App) pullModel(providerInput, model string) error {
+	provider, err := a.resolveProvider(providerInput)
+	if err != nil {
+		return err
+	}
+	client, err := a.newClient(provider)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	progress := &pullProgress{w: a.stderr, live: isTerminalWriter(a.stderr)}
+	err = providers.PullModel(ctx, client, model, progress.update)
+	progress.finish()
+	interrupted := ctx.Err() != nil
+	stop()
+	if err != nil {
+		switch {
+		case errors.Is(err, providers.ErrPullUnsupported):
+			return fmt.Errorf("models pull is only available for ollama; %s models are managed by the provider", provider)
+		case interrupted && errors.Is(err, context.Canceled):
+			fmt.Fprintln(a.stderr, "cancelled")
+			return &ExitError{Code: 130}
+		}
+		return err
+	}
+
+	if err := modelcache.Invalidate(modelcache.DirForConfig(a.cfgPath), provider); err != nil {
+		fmt.Fprintln(a.stderr, "warning: unable to clear model cache:", err)
+	}
+	fmt.Fprintf(a.stdout, "pulled %s\n", model)
+	return nil
+}
+
+// pullProgress renders pull updates. On a terminal the current layer's
+// progress is redrawn in place; otherwise each new status is printed once so
+// logs stay readable.
+type pullProgress struct {
+	w     io.Writer
+	live  bool
+	last  string
+	drawn bool
+	width int
+}
+
+func (p *pullProgress) update(u providers.PullProgress) {
+	line := u.Status
+	if u.Total > 0 {
+		line = fmt.Sprintf("%s %3d%% (%s / %s)", u.Status, u.Completed*100/u.Total, formatBytes(u.Completed), formatBytes(u.Total))
+	}
+	if !p.live {
+		if u.Status != p.last {
+			fmt.Fprintln(p.w, u.Status)
+			p.last = u.Status
+		}
+		return
+	}
+	if u.Status != p.last && p.drawn {
+		fmt.Fprintln(p.w)
+		p.width = 0
+	}
+	pad := p.width - len(line)
+	if pad < 0 {
+		pad = 0
+	}
+	fmt.Fprintf(p.w, "\r%s%*s", line, pad, "")
+	p.width = len(line)
+	p.last = u.Status
+	p.drawn = true
+}
+
+func (p *pullProgress) finish() {
+	if p.live && p.drawn {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// formatBytes renders n in binary units, e.g. 1.5 GB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/history"
+	"github.com/sasanktumpati/ask/internal/runner"
+)
+
+type rerunOptions struct {
+	Print bool
+	Yes   bool
+	Edit  bool
+}
+
+func parseRerunArgs(args []string) (rerunOptions, error) {
+	opts := rerunOptions{}
+	showHelp := false
+	rest, err := scanOptions(args, []optionSpec{
+		{Names: []string{"help", "h"}, TakesValue: false, Set: func(string) error { showHelp = true; return nil }},
+		{Names: []string{"print"}, TakesValue: false, Set: func(string) error { opts.Print = true; return nil }},
+		{Names: []string{"yes", "unsafe"}, TakesValue: false, Set: func(string) error { opts.Yes = true; return nil }},
+		{Names: []string{"edit", "e"}, TakesValue: false, Set: func(string) error { opts.Edit = true; return nil }},
+	})
+	if err != nil {
+		return opts, err
+	}
+	if showHelp {
+		return opts, errShowHelp
+	}
+	if len(rest) > 0 {
+		return opts, usageError("ask rerun [--print] [--edit] [--yes]")
+	}
+	return opts, nil
+}
+
+func (a *App) runRerun(args []string) error {
+	opts, err := parseRerunArgs(args)
+	if err != nil {
+		if errors.Is(err, errShowHelp) {
+			printHelp(a.stdout, "rerun", a.cfgPath)
+			return nil
+		}
+		return err
+	}
+
+	last, err := history.LoadLastCommand(history.LastCommandPath(a.cfgPath))
+	if err != nil {
+		if errors.Is(err, history.ErrNoLastCommand) {
+			return fmt.Errorf("%w; ask a question that returns a command first", err)
+		}
+		return err
+	}
+	if opts.Print {
+		fmt.Fprintln(a.stdout, last.Command)
+		return nil
+	}
+
+	if question := strings.TrimSpace(last.CommandQuestion); question != "" {
+		fmt.Fprintf(a.stderr, "from: %s\n", truncateForList(question, 72))
+	}
+	status, err := runner.PromptAndRun(runner.RunOptions{
+		Command:     last.Command,
+		Stdin:       a.stdin,
+		Stdout:      a.stdout,
+		Stderr:      a.stderr,
+		AllowUnsafe: opts.Yes,
+		Edit:        opts.Edit,
+		Shell:       a.cfg.Shell,
+		Highlight:   a.cfg.HighlightCmds && !a.noColor,
+	})
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		fmt.Fprintf(a.stderr, "command exited with status %d\n", status)
+		return &ExitError{Code: status}
+	}
+	return nil
+}
+
+// recordLastExchange stores question for `ask again` and command, when
+// present, for `ask rerun`. An answer without a command keeps the previous
+// command. Failures only warn, since the answer has already been delivered.
+func (a *App) recordLastExchange(provider, model, question, command string) {
+	path := history.LastCommandPath(a.cfgPath)
+	last := history.LastCommand{Question: question, Provider: provider, Model: model}
+	if command != "" {
+		last.Command, last.CommandQuestion = command, question
+	} else if previous, err := history.LoadLastCommand(path); err == nil {
+		last.Command, last.CommandQuestion = previous.Command, previous.CommandQuestion
+	}
+	if err := history.SaveLastCommand(path, last); err != nil {
+		fmt.Fprintln(a.stderr, "warning: unable to save last command:", err)
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+func TestRerunUsesLastReturnedCommand(t *testing.T) {
+	app, stdout := newStubApp(t, `{"answer":"List files.","command":"ls -la"}`)
+
+	if err := app.runRerun([]string{"--print"}); err == nil {
+		t.Fatal("expected an error before any command is recorded")
+	}
+	if err := app.runAsk([]string{"-q", "-p", "stub", "--no-run", "list", "files"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+
+	stdout.Reset()
+	if err := app.runRerun([]string{"--print"}); err != nil {
+		t.Fatalf("rerun --print error = %v", err)
+	}
+	if stdout.String() != "ls -la\n" {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+
+	// An answer without a command keeps the command for rerun.
+	noCommand := newStubProvider(t, `{"answer":"It lists files.","command":""}`)
+	if err := app.cfg.AddCustomProvider("talk", config.OpenAICompatibleProvider{BaseURL: noCommand.URL, Model: "m"}); err != nil {
+		t.Fatalf("AddCustomProvider error = %v", err)
+	}
+	if err := app.runAsk([]string{"-q", "-p", "talk", "what", "does", "ls", "do"}); err != nil {
+		t.Fatalf("runAsk error = %v", err)
+	}
+	stdout.Reset()
+	if err := app.runRerun([]string{"--print"}); err != nil || stdout.String() != "ls -la\n" {
+		t.Fatalf("rerun --print = %q, %v", stdout.String(), err)
+	}
+
+	if _, err := parseRerunArgs([]string{"extra"}); err == nil {
+		t.Fatal("expected usage error for positional arguments")
+	}
+}
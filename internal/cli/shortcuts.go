@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+// shortcuts maps bare-word command shortcuts to the command they stand for.
+var shortcuts = map[string]string{
+	"m":   "models",
+	"p":   "provider",
+	"k":   "key",
+	"cfg": "config",
+}
+
+// shortcutSubcommands lists the subcommands of each shortcut target. A
+// shortcut only applies when followed by nothing, a flag, help, or one of
+// these, so a question that happens to start with the same word still
+// reaches the assistant. A test checks the lists against the subcommands
+// each command's help documents.
+var shortcutSubcommands = map[string][]string{
+	"models":   {"list", "current", "set", "info", "refresh", "pull", "alias", "aliases", "select"},
+	"provider": {"list", "ls", "current", "set", "add", "remove", "rm", "delete", "rename", "mv", "clone", "cp", "export", "show", "inspect"},
+	"key":      {"set", "clear", "show", "import-env"},
+	"config":   {"show", "path", "template", "edit", "validate", "export", "import", "get", "set", "schema", "restore", "profile", "profiles"},
+}
+
+// expandShortcut replaces a leading command shortcut with its command.
+func expandShortcut(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	cmd, ok := shortcuts[strings.ToLower(strings.TrimSpace(args[0]))]
+	if !ok {
+		return args
+	}
+	if len(args) > 1 {
+		next := strings.ToLower(strings.TrimSpace(args[1]))
+		if !strings.HasPrefix(next, "-") && !isHelpToken(next) && !slices.Contains(shortcutSubcommands[cmd], next) {
+			return args
+		}
+	}
+	return append([]string{cmd}, args[1:]...)
+}
+
+// expandAlias replaces a leading user-defined alias from the config with the
+// arguments it stands for. Aliases are expanded once, so one alias cannot
+// refer to another, but the expansion may itself start with a shortcut.
+// Aliases named after a command, which a hand-edited config can still hold,
+// are ignored so they cannot hide it.
+func (a *App) expandAlias(args []string) []string {
+	if len(args) == 0 || config.IsCommandName(args[0]) {
+		return args
+	}
+	expansion, ok := a.cfg.Alias(args[0])
+	if !ok {
+		return args
+	}
+	return expandShortcut(append(expansion, args[1:]...))
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sasanktumpati/ask/internal/config"
+)
+
+// stubProvider is an OpenAI-compatible server that answers every chat
+// request with the same message content and records the request bodies.
+type stubProvider struct {
+	URL string
+
+	mu      sync.Mutex
+	content string
+	bodies  []map[string]any
+}
+
+func newStubProvider(t *testing.T, content string) *stubProvider {
+	t.Helper()
+	stub := &stubProvider{content: content}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		stub.mu.Lock()
+		stub.bodies = append(stub.bodies, body)
+		content := stub.content
+		stub.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": content}}},
+			"usage":   map[string]any{"prompt_tokens": 10, "completion_tokens": 5},
+		})
+	}))
+	t.Cleanup(server.Close)
+	stub.URL = server.URL
+	return stub
+}
+
+// setContent changes the message content of later replies.
+func (s *stubProvider) setContent(content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+}
+
+// requests returns the decoded bodies of the requests served so far.
+func (s *stubProvider) requests() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.bodies...)
+}
+
+// stubApp returns an App with a default config, a temporary config path and
+// a custom provider named "stub" pointing at baseURL, plus its stdout.
+func stubApp(t *testing.T, baseURL string) (*App, *bytes.Buffer) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	if err := cfg.AddCustomProvider("stub", config.OpenAICompatibleProvider{BaseURL: baseURL, Model: "m"}); err != nil {
+		t.Fatalf("AddCustomProvider error = %v", err)
+	}
+	var stdout bytes.Buffer
+	return &App{stdout: &stdout, stderr: &bytes.Buffer{}, cfgPath: filepath.Join(t.TempDir(), "config.json"), cfg: cfg}, &stdout
+}
+
+// newStubApp returns an App whose "stub" provider always answers content.
+func newStubApp(t *testing.T, content string) (*App, *bytes.Buffer) {
+	t.Helper()
+	return stubApp(t, newStubProvider(t, content).URL)
+}
+
+// lastMessage returns the content of the last message in a chat request body.
+func lastMessage(body map[string]any) string {
+	messages, _ := body["messages"].([]any)
+	if len(messages) == 0 {
+		return ""
+	}
+	message, _ := messages[len(messages)-1].(map[string]any)
+	content, _ := message["content"].(string)
+	return content
+}
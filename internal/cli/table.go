@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// ANSI styles for table rows.
+const (
+	styleBold  = "\033[1m"
+	styleDim   = "\033[2m"
+	styleGreen = "\033[32m"
+	styleRed   = "\033[31m"
+	styleReset = "\033[0m"
+)
+
+// rowStyle is how a table row is colored when color is enabled.
+type rowStyle int
+
+const (
+	rowPlain   rowStyle = iota
+	rowCurrent          // bold, with a green "*" marker in the first column
+	rowDim
+)
+
+// table aligns rows with tabwriter and colors whole lines afterwards, so
+// escape codes never count towards the column widths.
+type table struct {
+	buf    bytes.Buffer
+	tw     *tabwriter.Writer
+	styles []rowStyle
+}
+
+func newTable() *table {
+	t := &table{}
+	t.tw = tabwriter.NewWriter(&t.buf, 0, 2, 2, ' ', 0)
+	return t
+}
+
+// row writes one tab-separated line with style.
+func (t *table) row(style rowStyle, format string, args ...any) {
+	fmt.Fprintf(t.tw, format+"\n", args...)
+	t.styles = append(t.styles, style)
+}
+
+// flush writes the aligned table to w, colored when color is set.
+func (t *table) flush(w io.Writer, color bool) error {
+	if err := t.tw.Flush(); err != nil {
+		return err
+	}
+	if !color {
+		_, err := w.Write(t.buf.Bytes())
+		return err
+	}
+	var out strings.Builder
+	for i, line := range strings.SplitAfter(t.buf.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		style := rowPlain
+		if i < len(t.styles) {
+			style = t.styles[i]
+		}
+		switch {
+		case text == "" || style == rowPlain:
+			out.WriteString(text)
+		case style == rowCurrent && strings.HasPrefix(text, "*"):
+			out.WriteString(styleGreen + "*" + styleReset + styleBold + text[1:] + styleReset)
+		case style == rowCurrent:
+			out.WriteString(styleBold + text + styleReset)
+		case style == rowDim:
+			out.WriteString(styleDim + text + styleReset)
+		}
+		out.WriteString(strings.TrimPrefix(line, text))
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// colorEnabled reports whether tables on stdout may be colored: stdout must
+// be a terminal, and neither --no-color nor NO_COLOR may be set.
+func (a *App) colorEnabled() bool {
+	return !a.noColor && os.Getenv("NO_COLOR") == "" && isTerminalWriter(a.stdout)
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTableColorsRowsWithoutChangingWidths(t *testing.T) {
+	build := func() *table {
+		tbl := newTable()
+		tbl.row(rowPlain, "CURRENT\tNAME\tKEY")
+		tbl.row(rowCurrent, "*\topenai\tenv")
+		tbl.row(rowDim, "\tanthropic-long-name\t-")
+		return tbl
+	}
+
+	var plain strings.Builder
+	if err := build().flush(&plain, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain.String(), "\033[") {
+		t.Fatalf("plain table has escapes: %q", plain.String())
+	}
+
+	var colored strings.Builder
+	if err := build().flush(&colored, true); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(colored.String(), "\n")
+	if !strings.HasPrefix(lines[1], styleGreen+"*"+styleReset+styleBold) {
+		t.Fatalf("current row = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], styleDim) {
+		t.Fatalf("dim row = %q", lines[2])
+	}
+	stripped := regexp.MustCompile("\033\\[[0-9;]*m").ReplaceAllString(colored.String(), "")
+	if stripped != plain.String() {
+		t.Fatalf("colors changed the layout:\n%s\nwant:\n%s", stripped, plain.String())
+	}
+}
@@ -0,0 +1,91 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Copy copies text to the system clipboard using the first working clipboard
+// command for the current OS. When none works and stdout is a terminal that
+// is known to accept OSC52 (see osc52Enabled), the text is sent to the
+// terminal's clipboard with an escape sequence instead, which also works over
+// SSH.
+func Copy(text string) error {
+	err := copyText(text, runtime.GOOS)
+	if err == nil || strings.TrimSpace(text) == "" {
+		return err
+	}
+	if !osc52Enabled(os.Getenv) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return err
+	}
+	return writeOSC52(os.Stdout, strings.TrimSpace(text))
+}
+
+type clipboardCmd struct {
+	name string
+	args []string
+}
+
+func copyText(text string, goos string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("clipboard text is empty")
+	}
+
+	for _, c := range clipboardCommands(goos) {
+		cmd := exec.Command(c.name, c.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+	return errors.New("no working clipboard command found")
+}
+
+func clipboardCommands(goos string) []clipboardCmd {
+	switch goos {
+	case "darwin":
+		return []clipboardCmd{{name: "pbcopy"}}
+	case "windows":
+		return []clipboardCmd{{name: "cmd", args: []string{"/c", "clip"}}}
+	default:
+		return []clipboardCmd{
+			{name: "wl-copy"},
+			{name: "xclip", args: []string{"-selection", "clipboard"}},
+			{name: "xsel", args: []string{"--clipboard", "--input"}},
+		}
+	}
+}
+
+// osc52Enabled reports whether the OSC52 fallback may be used. ASK_OSC52=1
+// forces it on and ASK_OSC52=0 off; otherwise it is used only inside SSH
+// sessions, where local clipboard commands cannot reach the user's desktop.
+func osc52Enabled(getenv func(string) string) bool {
+	switch strings.TrimSpace(getenv("ASK_OSC52")) {
+	case "1", "true":
+		return true
+	case "0", "false":
+		return false
+	}
+	if getenv("TERM") == "dumb" {
+		return false
+	}
+	return getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != ""
+}
+
+// writeOSC52 writes the OSC52 "set clipboard" escape sequence for text.
+func writeOSC52(w io.Writer, text string) error {
+	seq := "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if _, err := io.WriteString(w, seq); err != nil {
+		return fmt.Errorf("write OSC52 sequence: %w", err)
+	}
+	return nil
+}
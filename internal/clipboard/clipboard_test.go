@@ -0,0 +1,69 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestClipboardCommandsByOS(t *testing.T) {
+	mac := clipboardCommands("darwin")
+	if len(mac) != 1 || mac[0].name != "pbcopy" {
+		t.Fatalf("darwin clipboard commands = %+v", mac)
+	}
+
+	win := clipboardCommands("windows")
+	if len(win) != 1 || win[0].name != "cmd" {
+		t.Fatalf("windows clipboard commands = %+v", win)
+	}
+	if len(win[0].args) != 2 || win[0].args[0] != "/c" || win[0].args[1] != "clip" {
+		t.Fatalf("windows clipboard args = %+v", win[0].args)
+	}
+
+	linux := clipboardCommands("linux")
+	if len(linux) < 3 {
+		t.Fatalf("linux clipboard commands = %+v", linux)
+	}
+}
+
+func TestCopyRejectsEmpty(t *testing.T) {
+	if err := copyText("   ", "darwin"); err == nil {
+		t.Fatal("expected error for empty clipboard text")
+	}
+}
+
+func TestWriteOSC52(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeOSC52(&buf, "ls -la | grep go"); err != nil {
+		t.Fatalf("writeOSC52 error = %v", err)
+	}
+	seq := buf.String()
+	if !strings.HasPrefix(seq, "\033]52;c;") || !strings.HasSuffix(seq, "\a") {
+		t.Fatalf("sequence = %q", seq)
+	}
+	payload := strings.TrimSuffix(strings.TrimPrefix(seq, "\033]52;c;"), "\a")
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || string(decoded) != "ls -la | grep go" {
+		t.Fatalf("payload %q decoded to %q (%v)", payload, decoded, err)
+	}
+}
+
+func TestOSC52Enabled(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"ASK_OSC52": "1"}, true},
+		{map[string]string{"SSH_TTY": "/dev/pts/1"}, true},
+		{map[string]string{"SSH_TTY": "/dev/pts/1", "ASK_OSC52": "0"}, false},
+		{map[string]string{"SSH_CONNECTION": "1 2 3 4", "TERM": "dumb"}, false},
+	}
+	for _, tt := range tests {
+		got := osc52Enabled(func(k string) string { return tt.env[k] })
+		if got != tt.want {
+			t.Errorf("osc52Enabled(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package clipboard copies text to the system clipboard.
+package clipboard
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetModelAlias maps alias to model for provider, replacing any existing
+// mapping for that alias.
+func (c *Config) SetModelAlias(provider, alias, model string) error {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	model = strings.TrimSpace(model)
+	if alias == "" {
+		return fmt.Errorf("alias cannot be empty")
+	}
+	if model == "" {
+		return fmt.Errorf("model cannot be empty")
+	}
+	if strings.EqualFold(alias, model) {
+		return fmt.Errorf("alias %q cannot point to itself", alias)
+	}
+	c.normalize()
+	if c.ModelAliases == nil {
+		c.ModelAliases = map[string]map[string]string{}
+	}
+	if c.ModelAliases[provider] == nil {
+		c.ModelAliases[provider] = map[string]string{}
+	}
+	c.ModelAliases[provider][alias] = model
+	return nil
+}
+
+// RemoveModelAlias deletes alias for provider and reports whether it existed.
+func (c *Config) RemoveModelAlias(provider, alias string) bool {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if _, ok := c.ModelAliases[provider][alias]; !ok {
+		return false
+	}
+	delete(c.ModelAliases[provider], alias)
+	c.normalize()
+	return true
+}
+
+// ModelAliasesFor returns the alias-to-model mappings for provider.
+func (c *Config) ModelAliasesFor(provider string) map[string]string {
+	return c.ModelAliases[strings.ToLower(strings.TrimSpace(provider))]
+}
+
+// ResolveModel expands model when it is an alias defined for provider and
+// otherwise returns it unchanged.
+func (c *Config) ResolveModel(provider, model string) string {
+	model = strings.TrimSpace(model)
+	if target, ok := c.ModelAliasesFor(provider)[strings.ToLower(model)]; ok {
+		return target
+	}
+	return model
+}
+
+func normalizeModelAliases(in map[string]map[string]string) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for provider, aliases := range in {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if provider == "" {
+			continue
+		}
+		for alias, model := range aliases {
+			alias = strings.ToLower(strings.TrimSpace(alias))
+			model = strings.TrimSpace(model)
+			if alias == "" || model == "" {
+				continue
+			}
+			if out[provider] == nil {
+				out[provider] = map[string]string{}
+			}
+			out[provider][alias] = model
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
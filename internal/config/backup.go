@@ -0,0 +1,82 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNoBackup indicates there is no config backup to restore.
+var ErrNoBackup = errors.New("no config backup found")
+
+// BackupPathForConfig returns the path of the backup kept for configPath.
+func BackupPathForConfig(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return path + ".bak"
+}
+
+// backupExisting copies the current file at path to its backup before it is
+// replaced with next. Nothing is written when the file is missing or
+// unchanged, so repeated saves do not clobber the last distinct version.
+func backupExisting(path string, next []byte) error {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read config for backup: %w", err)
+	}
+	if bytes.Equal(current, next) {
+		return nil
+	}
+	backup := BackupPathForConfig(path)
+	if err := os.WriteFile(backup, current, 0o600); err != nil {
+		return fmt.Errorf("write config backup: %w", err)
+	}
+	if err := os.Chmod(backup, 0o600); err != nil {
+		return fmt.Errorf("set config backup permissions: %w", err)
+	}
+	return nil
+}
+
+// RestoreBackup swaps the config at path with its backup, so the config
+// that was replaced becomes the new backup. The backup must decode as a
+// config; ErrNoBackup is returned when there is none.
+func RestoreBackup(path string) error {
+	backup := BackupPathForConfig(path)
+	buf, err := os.ReadFile(backup)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoBackup
+		}
+		return fmt.Errorf("read config backup: %w", err)
+	}
+	if err := json.Unmarshal(buf, DefaultConfig()); err != nil {
+		return fmt.Errorf("config backup %s is invalid: %w", backup, err)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read config: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return fmt.Errorf("write temp config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace config: %w", err)
+	}
+	if current == nil {
+		return os.Remove(backup)
+	}
+	if err := os.WriteFile(backup, current, 0o600); err != nil {
+		return fmt.Errorf("write config backup: %w", err)
+	}
+	return nil
+}
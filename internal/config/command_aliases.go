@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// commandNames are the top-level words the CLI dispatches as commands; a cli
+// test fails when its command table has a name missing here. An alias with
+// one of these names would hide the command, so it is rejected.
+var commandNames = []string{
+	"help", "version", "models", "model", "provider", "providers", "key", "keys", "config", "markdown",
+	"chat", "history", "embed", "prompt", "prompts", "rerun", "again", "compare", "batch", "cache", "doctor",
+}
+
+// IsCommandName reports whether name is a top-level command, which no alias
+// may shadow.
+func IsCommandName(name string) bool {
+	return slices.Contains(commandNames, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// SetAlias stores expansion as the command alias name, replacing any
+// existing alias with that name. Names of top-level commands are rejected.
+func (c *Config) SetAlias(name, expansion string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	expansion = strings.TrimSpace(expansion)
+	if name == "" {
+		return fmt.Errorf("alias name cannot be empty")
+	}
+	if strings.ContainsAny(name, " \t\n") || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("alias name %q cannot contain whitespace or start with -", name)
+	}
+	if IsCommandName(name) {
+		return fmt.Errorf("alias %q would shadow the %s command", name, name)
+	}
+	if expansion == "" {
+		return fmt.Errorf("alias %q expands to nothing", name)
+	}
+	if c.Aliases == nil {
+		c.Aliases = map[string]string{}
+	}
+	c.Aliases[name] = expansion
+	return nil
+}
+
+// Alias returns the arguments the command alias name expands to, split on
+// whitespace.
+func (c *Config) Alias(name string) ([]string, bool) {
+	expansion, ok := c.Aliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, false
+	}
+	return strings.Fields(expansion), true
+}
+
+func normalizeAliases(in map[string]string) map[string]string {
+	out := map[string]string{}
+	for name, expansion := range in {
+		name = strings.ToLower(strings.TrimSpace(name))
+		expansion = strings.TrimSpace(expansion)
+		if name == "" || expansion == "" || strings.ContainsAny(name, " \t\n") || strings.HasPrefix(name, "-") {
+			continue
+		}
+		out[name] = expansion
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -4,10 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/secrets"
 )
 
 const (
@@ -18,6 +23,7 @@ const (
 	currentVersion          = 1
 	envConfigPath           = "ASK_CONFIG"
 	envConfigDir            = "ASK_CONFIG_DIR"
+	envXDGConfigHome        = "XDG_CONFIG_HOME"
 )
 
 var (
@@ -25,65 +31,171 @@ var (
 	ErrConfigNotFound = errors.New("config file not found")
 )
 
-// ProviderConfig stores per-provider defaults and credentials.
-type ProviderConfig struct {
-	APIKey    string `json:"api_key"`
-	Model     string `json:"model"`
-	BaseURL   string `json:"base_url,omitempty"`
-	APIKeyEnv string `json:"api_key_env,omitempty"`
+// keychain resolves api_key_ref values; tests replace it with a fake.
+var keychain secrets.Backend = secrets.Default()
+
+// SetKeychain replaces the backend that resolves api_key_ref values and
+// returns a function restoring the previous one. Tests in other packages use
+// it to avoid the OS keychain.
+func SetKeychain(b secrets.Backend) (restore func()) {
+	previous := keychain
+	keychain = b
+	return func() { keychain = previous }
 }
 
-// OpenAICompatibleProvider defines a custom OpenAI-compatible provider.
-type OpenAICompatibleProvider struct {
-	BaseURL    string            `json:"base_url"`
+// ProviderConfig stores per-provider defaults and credentials.
+type ProviderConfig struct {
 	APIKey     string            `json:"api_key"`
 	Model      string            `json:"model"`
+	BaseURL    string            `json:"base_url,omitempty"`
 	APIKeyEnv  string            `json:"api_key_env,omitempty"`
-	ModelsPath string            `json:"models_path,omitempty"`
-	ChatPath   string            `json:"chat_path,omitempty"`
-	AuthHeader string            `json:"auth_header,omitempty"`
-	AuthPrefix string            `json:"auth_prefix,omitempty"`
+	APIKeyRef  string            `json:"api_key_ref,omitempty"`
+	APIVersion string            `json:"api_version,omitempty"`
+	MaxTokens  int               `json:"max_tokens,omitempty"`
+	Timeout    int               `json:"timeout_seconds,omitempty"`
+	ProxyURL   string            `json:"proxy_url,omitempty"`
 	Headers    map[string]string `json:"headers,omitempty"`
+	ExtraBody  map[string]any    `json:"extra_body,omitempty"` // merged into every request payload
+	// Region selects the cloud region for vertex and bedrock. Project and
+	// TokenCommand configure vertex; TokenCommand prints a bearer token,
+	// e.g. gcloud auth print-access-token.
+	Project      string `json:"project,omitempty"`
+	Region       string `json:"region,omitempty"`
+	TokenCommand string `json:"token_command,omitempty"`
+}
+
+// OpenAICompatibleProvider defines a custom OpenAI-compatible provider.
+type OpenAICompatibleProvider struct {
+	BaseURL           string            `json:"base_url"`
+	APIKey            string            `json:"api_key"`
+	Model             string            `json:"model"`
+	APIKeyEnv         string            `json:"api_key_env,omitempty"`
+	APIKeyRef         string            `json:"api_key_ref,omitempty"`
+	ModelsPath        string            `json:"models_path,omitempty"`
+	ChatPath          string            `json:"chat_path,omitempty"`
+	ModelsResponseKey string            `json:"models_response_key,omitempty"` // dot path to the list in the models response
+	ModelIDField      string            `json:"model_id_field,omitempty"`      // ID field of each models entry
+	AuthHeader        string            `json:"auth_header,omitempty"`
+	AuthPrefix        string            `json:"auth_prefix,omitempty"`
+	AuthQueryParam    string            `json:"auth_query_param,omitempty"` // sends the API key as this query parameter instead of the auth header
+	BasicUser         string            `json:"basic_user,omitempty"`       // enables HTTP Basic auth
+	BasicPassword     string            `json:"basic_password,omitempty"`
+	BasicPasswordEnv  string            `json:"basic_password_env,omitempty"` // env var with the Basic password; wins over basic_password
+	Headers           map[string]string `json:"headers,omitempty"`
+	MaxTokens         int               `json:"max_tokens,omitempty"`
+	Timeout           int               `json:"timeout_seconds,omitempty"`
+	ProxyURL          string            `json:"proxy_url,omitempty"`
+	ExtraBody         map[string]any    `json:"extra_body,omitempty"`
 }
 
 // Config is the persisted ask CLI configuration.
 type Config struct {
+	Schema          string                              `json:"$schema,omitempty"` // editor hint; see Schema
 	Version         int                                 `json:"version"`
 	CurrentProvider string                              `json:"current_provider"`
 	CurrentModels   map[string]string                   `json:"current_models,omitempty"` // legacy read-only compatibility
 	Providers       map[string]ProviderConfig           `json:"providers,omitempty"`
 	CustomProviders map[string]OpenAICompatibleProvider `json:"custom_providers,omitempty"`
 	OllamaHost      string                              `json:"ollama_host,omitempty"`
+	OpenAIOrg       string                              `json:"openai_organization,omitempty"` // sent as OpenAI-Organization
+	OpenAIProject   string                              `json:"openai_project,omitempty"`      // sent as OpenAI-Project
+	UserAgent       string                              `json:"user_agent,omitempty"`          // "" = ask/<version>
+	Defaults        *Defaults                           `json:"defaults,omitempty"`
 	RenderMarkdown  bool                                `json:"render_markdown"`
+	MarkdownTheme   string                              `json:"markdown_theme,omitempty"` // "" = auto
+	StrictJSON      bool                                `json:"strict_json,omitempty"`
+	Shell           string                              `json:"shell,omitempty"`                    // "" = $SHELL, or %COMSPEC% on Windows
+	SystemPrompt    string                              `json:"system_prompt,omitempty"`            // replaces the built-in instructions
+	GitContext      bool                                `json:"git_context,omitempty"`              // add branch and status counts to the prompt
+	AllowCodeFences bool                                `json:"allow_code_fences,omitempty"`        // let markdown answers use fenced code blocks
+	HighlightCmds   bool                                `json:"highlight_commands,omitempty"`       // colored preview above the run prompt
+	Retries         int                                 `json:"retries,omitempty"`                  // 0 = default, negative disables
+	ModelCacheTTL   int                                 `json:"model_cache_ttl_seconds,omitempty"`  // 0 = default, negative disables
+	AnswerCacheTTL  int                                 `json:"answer_cache_ttl_seconds,omitempty"` // 0 = answer cache off
+	Fallbacks       []string                            `json:"fallback_providers,omitempty"`
+	ModelAliases    map[string]map[string]string        `json:"model_aliases,omitempty"` // provider -> alias -> model
+	Prompts         map[string]string                   `json:"prompts,omitempty"`       // preset name -> template
+	Aliases         map[string]string                   `json:"aliases,omitempty"`       // command alias -> arguments
+}
+
+// Defaults holds settings that apply to every provider unless the provider
+// or a flag overrides them.
+type Defaults struct {
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"` // 0 = built-in 90s
+	// RenderMarkdown is another spelling of the top-level render_markdown;
+	// normalize moves it there so markdown on/off keeps a single source.
+	RenderMarkdown *bool `json:"render_markdown,omitempty"`
 }
 
 // BuiltinDefaults defines immutable defaults for built-in providers.
 type BuiltinDefaults struct {
 	BaseURL   string
 	APIKeyEnv string
+	// BaseURLEnv names an environment variable, such as OPENAI_BASE_URL,
+	// that replaces BaseURL when base_url is not configured.
+	BaseURLEnv string
 }
 
 var builtinProviders = map[string]BuiltinDefaults{
 	"anthropic": {
-		BaseURL:   "https://api.anthropic.com",
-		APIKeyEnv: "ANTHROPIC_API_KEY",
+		BaseURL:    "https://api.anthropic.com",
+		APIKeyEnv:  "ANTHROPIC_API_KEY",
+		BaseURLEnv: "ANTHROPIC_BASE_URL",
+	},
+	"azure": {
+		BaseURL:    "",
+		APIKeyEnv:  "AZURE_OPENAI_API_KEY",
+		BaseURLEnv: "AZURE_OPENAI_ENDPOINT",
+	},
+	"deepseek": {
+		BaseURL:   "https://api.deepseek.com/v1",
+		APIKeyEnv: "DEEPSEEK_API_KEY",
 	},
 	"gemini": {
 		BaseURL:   "https://generativelanguage.googleapis.com/v1beta",
 		APIKeyEnv: "GEMINI_API_KEY",
 	},
+	"groq": {
+		BaseURL:    "https://api.groq.com/openai/v1",
+		APIKeyEnv:  "GROQ_API_KEY",
+		BaseURLEnv: "GROQ_BASE_URL",
+	},
+	"mistral": {
+		BaseURL:   "https://api.mistral.ai/v1",
+		APIKeyEnv: "MISTRAL_API_KEY",
+	},
 	"ollama": {
-		BaseURL:   "http://127.0.0.1:11434",
-		APIKeyEnv: "",
+		BaseURL:    "http://127.0.0.1:11434",
+		APIKeyEnv:  "",
+		BaseURLEnv: "OLLAMA_HOST",
 	},
 	"openai": {
-		BaseURL:   "https://api.openai.com/v1",
-		APIKeyEnv: "OPENAI_API_KEY",
+		BaseURL:    "https://api.openai.com/v1",
+		APIKeyEnv:  "OPENAI_API_KEY",
+		BaseURLEnv: "OPENAI_BASE_URL",
 	},
 	"openrouter": {
 		BaseURL:   "https://openrouter.ai/api/v1",
 		APIKeyEnv: "OPENROUTER_API_KEY",
 	},
+	"bedrock": {
+		BaseURL:   "",
+		APIKeyEnv: "AWS_BEARER_TOKEN_BEDROCK",
+	},
+	"vertex": {
+		BaseURL:   "",
+		APIKeyEnv: "VERTEX_ACCESS_TOKEN",
+	},
+}
+
+// openAICompatibleBuiltins are the built-in providers that speak the OpenAI
+// chat completions API and can therefore be cloned into custom providers.
+var openAICompatibleBuiltins = map[string]bool{
+	"deepseek":   true,
+	"groq":       true,
+	"mistral":    true,
+	"openai":     true,
+	"openrouter": true,
 }
 
 // ResolvePath resolves config file path from CLI override, environment, or default.
@@ -98,6 +210,9 @@ func ResolvePath(pathOverride string) (string, error) {
 }
 
 // DefaultDir returns the default directory where ask stores its config.
+// ASK_CONFIG_DIR wins; an existing ~/.ask is kept for backward compatibility;
+// otherwise $XDG_CONFIG_HOME/ask, then an existing ~/.config/ask, and finally
+// ~/.ask.
 func DefaultDir() (string, error) {
 	if custom := strings.TrimSpace(os.Getenv(envConfigDir)); custom != "" {
 		return filepath.Clean(custom), nil
@@ -107,7 +222,22 @@ func DefaultDir() (string, error) {
 	if err != nil || strings.TrimSpace(home) == "" {
 		return "", fmt.Errorf("resolve user home directory: %w", err)
 	}
-	return filepath.Join(home, "."+defaultDirName), nil
+	legacy := filepath.Join(home, "."+defaultDirName)
+	if dirExists(legacy) {
+		return legacy, nil
+	}
+	if xdg := strings.TrimSpace(os.Getenv(envXDGConfigHome)); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(filepath.Clean(xdg), defaultDirName), nil
+	}
+	if dotConfig := filepath.Join(home, ".config", defaultDirName); dirExists(dotConfig) {
+		return dotConfig, nil
+	}
+	return legacy, nil
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 // DefaultPath returns the default full path to config.json.
@@ -259,6 +389,31 @@ func (c *Config) normalize() {
 	c.CurrentModels = nil
 	c.OllamaHost = strings.TrimRight(strings.TrimSpace(c.OllamaHost), "/")
 	c.CurrentProvider = strings.ToLower(strings.TrimSpace(c.CurrentProvider))
+	c.MarkdownTheme = strings.TrimSpace(c.MarkdownTheme)
+
+	fallbacks := make([]string, 0, len(c.Fallbacks))
+	for _, name := range c.Fallbacks {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			fallbacks = append(fallbacks, name)
+		}
+	}
+	c.Fallbacks = nil
+	if len(fallbacks) > 0 {
+		c.Fallbacks = fallbacks
+	}
+	if c.Defaults != nil {
+		if c.Defaults.RenderMarkdown != nil {
+			c.RenderMarkdown = *c.Defaults.RenderMarkdown
+			c.Defaults.RenderMarkdown = nil
+		}
+		if *c.Defaults == (Defaults{}) {
+			c.Defaults = nil
+		}
+	}
+	c.ModelAliases = normalizeModelAliases(c.ModelAliases)
+	c.Prompts = normalizePrompts(c.Prompts)
+	c.Aliases = normalizeAliases(c.Aliases)
 }
 
 // GetModel returns the configured default model for provider.
@@ -273,6 +428,123 @@ func (c *Config) GetModel(provider string) string {
 	return strings.TrimSpace(c.Providers[provider].Model)
 }
 
+// GetMaxTokens returns the configured output token limit for provider, or 0
+// when the provider's built-in default should be used.
+func (c *Config) GetMaxTokens(provider string) int {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if custom, ok := c.CustomProviders[provider]; ok {
+		return max(custom.MaxTokens, 0)
+	}
+	return max(c.Providers[provider].MaxTokens, 0)
+}
+
+// GetTimeout returns the configured request timeout for provider, falling
+// back to defaults.timeout_seconds, or 0 when the CLI default should be used.
+func (c *Config) GetTimeout(provider string) time.Duration {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	seconds := c.Providers[provider].Timeout
+	if custom, ok := c.CustomProviders[provider]; ok {
+		seconds = custom.Timeout
+	}
+	if seconds <= 0 && c.Defaults != nil {
+		seconds = c.Defaults.TimeoutSeconds
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetProxyURL returns the proxy_url configured for provider, or "" to use the
+// environment's proxy settings.
+func (c *Config) GetProxyURL(provider string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if custom, ok := c.CustomProviders[provider]; ok {
+		return strings.TrimSpace(custom.ProxyURL)
+	}
+	return strings.TrimSpace(c.Providers[provider].ProxyURL)
+}
+
+// envBaseURL returns the base URL set in env, or "". OLLAMA_HOST is
+// expanded like the Ollama CLI does; other variables hold a full URL.
+func envBaseURL(env string) string {
+	if env == "" {
+		return ""
+	}
+	if env == "OLLAMA_HOST" {
+		return ollamaHostURL(os.Getenv(env))
+	}
+	return strings.TrimRight(strings.TrimSpace(os.Getenv(env)), "/")
+}
+
+// ollamaHostURL turns an OLLAMA_HOST value into a base URL the way the
+// Ollama CLI does: the scheme defaults to http and the port to 11434 (443
+// for https), so "example.com", "0.0.0.0:8080" and "https://host" all work.
+func ollamaHostURL(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if raw == "" {
+		return ""
+	}
+	scheme, host := "http", raw
+	if before, after, ok := strings.Cut(raw, "://"); ok {
+		scheme, host = strings.ToLower(before), after
+	}
+	path := ""
+	if i := strings.Index(host, "/"); i >= 0 {
+		host, path = host[:i], host[i:]
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		port := "11434"
+		if scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+	return scheme + "://" + host + path
+}
+
+// ResolveProject returns the cloud project for provider from its config,
+// falling back to GOOGLE_CLOUD_PROJECT for vertex.
+func (c *Config) ResolveProject(provider string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if project := strings.TrimSpace(c.Providers[provider].Project); project != "" {
+		return project
+	}
+	if provider == "vertex" {
+		return strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	}
+	return ""
+}
+
+// ResolveRegion returns the cloud region for provider from its config,
+// falling back to GOOGLE_CLOUD_LOCATION for vertex and AWS_REGION or
+// AWS_DEFAULT_REGION for bedrock.
+func (c *Config) ResolveRegion(provider string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if region := strings.TrimSpace(c.Providers[provider].Region); region != "" {
+		return region
+	}
+	switch provider {
+	case "vertex":
+		return strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_LOCATION"))
+	case "bedrock":
+		if region := strings.TrimSpace(os.Getenv("AWS_REGION")); region != "" {
+			return region
+		}
+		return strings.TrimSpace(os.Getenv("AWS_DEFAULT_REGION"))
+	}
+	return ""
+}
+
+// GetExtraBody returns the extra_body configured for provider.
+func (c *Config) GetExtraBody(provider string) map[string]any {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if custom, ok := c.CustomProviders[provider]; ok {
+		return custom.ExtraBody
+	}
+	return c.Providers[provider].ExtraBody
+}
+
 // SetModel sets the default model for provider.
 func (c *Config) SetModel(provider string, model string) {
 	provider = strings.ToLower(strings.TrimSpace(provider))
@@ -317,9 +589,16 @@ func (c *Config) ResolveBaseURL(provider string) string {
 
 	defaults, builtin := BuiltinProviderDefaults(provider)
 	pc := c.Providers[provider]
-	if provider == "ollama" {
-		if strings.TrimSpace(c.OllamaHost) != "" {
-			return strings.TrimRight(strings.TrimSpace(c.OllamaHost), "/")
+	if provider == "ollama" && strings.TrimSpace(c.OllamaHost) != "" {
+		return strings.TrimRight(strings.TrimSpace(c.OllamaHost), "/")
+	}
+	// Base URL env vars such as OPENAI_BASE_URL apply unless base_url was
+	// changed from the built-in default, which the config template writes
+	// for Ollama.
+	explicit := strings.TrimRight(strings.TrimSpace(pc.BaseURL), "/")
+	if builtin && (explicit == "" || explicit == strings.TrimRight(defaults.BaseURL, "/")) {
+		if base := envBaseURL(defaults.BaseURLEnv); base != "" {
+			return base
 		}
 	}
 	if strings.TrimSpace(pc.BaseURL) != "" {
@@ -345,12 +624,22 @@ func (c *Config) compactForSave() *Config {
 				continue
 			}
 			normalized := ProviderConfig{
-				APIKey:    strings.TrimSpace(raw.APIKey),
-				Model:     strings.TrimSpace(raw.Model),
-				BaseURL:   strings.TrimRight(strings.TrimSpace(raw.BaseURL), "/"),
-				APIKeyEnv: strings.TrimSpace(raw.APIKeyEnv),
+				APIKey:       strings.TrimSpace(raw.APIKey),
+				Model:        strings.TrimSpace(raw.Model),
+				BaseURL:      strings.TrimRight(strings.TrimSpace(raw.BaseURL), "/"),
+				APIKeyEnv:    strings.TrimSpace(raw.APIKeyEnv),
+				APIKeyRef:    strings.TrimSpace(raw.APIKeyRef),
+				APIVersion:   strings.TrimSpace(raw.APIVersion),
+				MaxTokens:    max(raw.MaxTokens, 0),
+				Timeout:      max(raw.Timeout, 0),
+				ProxyURL:     strings.TrimSpace(raw.ProxyURL),
+				Headers:      normalizeHeaders(raw.Headers),
+				ExtraBody:    normalizeExtraBody(raw.ExtraBody),
+				Project:      strings.TrimSpace(raw.Project),
+				Region:       strings.TrimSpace(raw.Region),
+				TokenCommand: strings.TrimSpace(raw.TokenCommand),
 			}
-			if normalized.APIKey == "" && normalized.Model == "" && normalized.BaseURL == "" && normalized.APIKeyEnv == "" {
+			if reflect.ValueOf(normalized).IsZero() {
 				continue
 			}
 			providers[provider] = normalized
@@ -369,14 +658,24 @@ func (c *Config) compactForSave() *Config {
 				continue
 			}
 			normalized := OpenAICompatibleProvider{
-				BaseURL:    strings.TrimRight(strings.TrimSpace(raw.BaseURL), "/"),
-				APIKey:     strings.TrimSpace(raw.APIKey),
-				Model:      strings.TrimSpace(raw.Model),
-				APIKeyEnv:  strings.TrimSpace(raw.APIKeyEnv),
-				ModelsPath: strings.TrimSpace(raw.ModelsPath),
-				ChatPath:   strings.TrimSpace(raw.ChatPath),
-				AuthHeader: strings.TrimSpace(raw.AuthHeader),
-				AuthPrefix: raw.AuthPrefix,
+				BaseURL:           strings.TrimRight(strings.TrimSpace(raw.BaseURL), "/"),
+				APIKey:            strings.TrimSpace(raw.APIKey),
+				Model:             strings.TrimSpace(raw.Model),
+				APIKeyEnv:         strings.TrimSpace(raw.APIKeyEnv),
+				APIKeyRef:         strings.TrimSpace(raw.APIKeyRef),
+				ModelsPath:        strings.TrimSpace(raw.ModelsPath),
+				ChatPath:          strings.TrimSpace(raw.ChatPath),
+				AuthHeader:        strings.TrimSpace(raw.AuthHeader),
+				AuthPrefix:        raw.AuthPrefix,
+				AuthQueryParam:    strings.TrimSpace(raw.AuthQueryParam),
+				ModelsResponseKey: strings.TrimSpace(raw.ModelsResponseKey),
+				ModelIDField:      strings.TrimSpace(raw.ModelIDField),
+				BasicUser:         strings.TrimSpace(raw.BasicUser),
+				BasicPassword:     raw.BasicPassword,
+				BasicPasswordEnv:  strings.TrimSpace(raw.BasicPasswordEnv),
+				MaxTokens:         max(raw.MaxTokens, 0),
+				Timeout:           max(raw.Timeout, 0),
+				ProxyURL:          strings.TrimSpace(raw.ProxyURL),
 			}
 			if normalized.BaseURL == "" {
 				continue
@@ -393,19 +692,8 @@ func (c *Config) compactForSave() *Config {
 			if normalized.AuthPrefix == "Bearer " {
 				normalized.AuthPrefix = ""
 			}
-
-			headers := map[string]string{}
-			for key, value := range raw.Headers {
-				key = strings.TrimSpace(key)
-				value = strings.TrimSpace(value)
-				if key == "" || value == "" {
-					continue
-				}
-				headers[key] = value
-			}
-			if len(headers) > 0 {
-				normalized.Headers = headers
-			}
+			normalized.Headers = normalizeHeaders(raw.Headers)
+			normalized.ExtraBody = normalizeExtraBody(raw.ExtraBody)
 
 			customProviders[name] = normalized
 		}
@@ -422,6 +710,39 @@ func (c *Config) compactForSave() *Config {
 	return &compacted
 }
 
+// normalizeHeaders trims header names and values, dropping empty entries. It
+// returns nil when nothing is left so the field is omitted on save.
+func normalizeHeaders(raw map[string]string) map[string]string {
+	headers := map[string]string{}
+	for key, value := range raw {
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" || value == "" {
+			continue
+		}
+		headers[key] = value
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// normalizeExtraBody drops blank keys and returns nil for an empty map so
+// extra_body is omitted on save.
+func normalizeExtraBody(raw map[string]any) map[string]any {
+	extra := map[string]any{}
+	for key, value := range raw {
+		if key = strings.TrimSpace(key); key != "" {
+			extra[key] = value
+		}
+	}
+	if len(extra) == 0 {
+		return nil
+	}
+	return extra
+}
+
 func builtinProviderScaffold() map[string]ProviderConfig {
 	providers := map[string]ProviderConfig{}
 	for _, name := range BuiltinProviderNames() {
@@ -441,42 +762,72 @@ func builtinProviderScaffold() map[string]ProviderConfig {
 	return providers
 }
 
-// ResolveAPIKey returns effective API key, preferring configured env vars over stored key.
+// ResolveAPIKey returns effective API key, preferring a keychain reference,
+// then configured env vars, then the stored key.
 func (c *Config) ResolveAPIKey(provider string) string {
+	key, _ := c.ResolveAPIKeySource(provider)
+	return key
+}
+
+// ResolveBasicAuth returns the HTTP Basic credentials for a custom provider,
+// reading the password from basic_password_env when it is set in the
+// environment. The user is "" when Basic auth is not configured.
+func (c *Config) ResolveBasicAuth(provider string) (string, string) {
+	custom, ok := c.CustomProviders[strings.ToLower(strings.TrimSpace(provider))]
+	if !ok || strings.TrimSpace(custom.BasicUser) == "" {
+		return "", ""
+	}
+	if env := strings.TrimSpace(custom.BasicPasswordEnv); env != "" {
+		if v := os.Getenv(env); v != "" {
+			return strings.TrimSpace(custom.BasicUser), v
+		}
+	}
+	return strings.TrimSpace(custom.BasicUser), custom.BasicPassword
+}
+
+// ResolveAPIKeySource is ResolveAPIKey that also reports where the key came
+// from: "keychain", "env:<NAME>", "config", or "" when no key is set.
+func (c *Config) ResolveAPIKeySource(provider string) (string, string) {
 	provider = strings.ToLower(strings.TrimSpace(provider))
 	if provider == "" {
-		return ""
+		return "", ""
 	}
 
 	if custom, ok := c.CustomProviders[provider]; ok {
+		if v := fetchKeychainRef(custom.APIKeyRef); v != "" {
+			return v, "keychain"
+		}
 		if env := strings.TrimSpace(custom.APIKeyEnv); env != "" {
 			if v := strings.TrimSpace(os.Getenv(env)); v != "" {
-				return v
+				return v, "env:" + env
 			}
 		}
 		if v := strings.TrimSpace(custom.APIKey); v != "" {
-			return v
+			return v, "config"
 		}
-		return ""
+		return "", ""
 	}
 
 	pc := c.Providers[provider]
+	if v := fetchKeychainRef(pc.APIKeyRef); v != "" {
+		return v, "keychain"
+	}
 	if env := strings.TrimSpace(pc.APIKeyEnv); env != "" {
 		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
-			return v
+			return v, "env:" + env
 		}
 	}
 	if defaults, ok := BuiltinProviderDefaults(provider); ok {
 		if env := strings.TrimSpace(defaults.APIKeyEnv); env != "" {
 			if v := strings.TrimSpace(os.Getenv(env)); v != "" {
-				return v
+				return v, "env:" + env
 			}
 		}
 	}
 	if v := strings.TrimSpace(pc.APIKey); v != "" {
-		return v
+		return v, "config"
 	}
-	return ""
+	return "", ""
 }
 
 // SetAPIKey sets a provider API key in config.
@@ -493,6 +844,44 @@ func (c *Config) SetAPIKey(provider string, key string) {
 	c.Providers[provider] = pc
 }
 
+// SetAPIKeyRef sets a provider's keychain reference (see secrets.Ref).
+func (c *Config) SetAPIKeyRef(provider, ref string) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	ref = strings.TrimSpace(ref)
+	c.normalize()
+	if custom, ok := c.CustomProviders[provider]; ok {
+		custom.APIKeyRef = ref
+		c.CustomProviders[provider] = custom
+		return
+	}
+	pc := c.Providers[provider]
+	pc.APIKeyRef = ref
+	c.Providers[provider] = pc
+}
+
+// APIKeyRef returns the keychain reference configured for provider, if any.
+func (c *Config) APIKeyRef(provider string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if custom, ok := c.CustomProviders[provider]; ok {
+		return strings.TrimSpace(custom.APIKeyRef)
+	}
+	return strings.TrimSpace(c.Providers[provider].APIKeyRef)
+}
+
+// fetchKeychainRef resolves a keychain reference, returning "" when ref is
+// empty or the lookup fails so callers fall back to env and plaintext keys.
+func fetchKeychainRef(ref string) string {
+	account, ok := secrets.ParseRef(ref)
+	if !ok {
+		return ""
+	}
+	v, err := keychain.Fetch(account)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(v)
+}
+
 // SetAPIKeyEnv sets a provider API key environment variable name.
 func (c *Config) SetAPIKeyEnv(provider, envVar string) {
 	provider = strings.ToLower(strings.TrimSpace(provider))
@@ -591,6 +980,136 @@ func (c *Config) RemoveCustomProvider(name string) error {
 	return nil
 }
 
+// CloneProvider copies provider src to a new custom provider dst. Custom
+// providers are copied in full; OpenAI-compatible built-ins are materialized
+// with their resolved base URL, credentials and defaults.
+func (c *Config) CloneProvider(src, dst string) error {
+	src = strings.ToLower(strings.TrimSpace(src))
+	dst = strings.ToLower(strings.TrimSpace(dst))
+	if src == "" || dst == "" {
+		return fmt.Errorf("provider name is required")
+	}
+	if IsBuiltinProvider(dst) {
+		return fmt.Errorf("%q is a built-in provider", dst)
+	}
+	c.normalize()
+	if _, exists := c.CustomProviders[dst]; exists {
+		return fmt.Errorf("provider %q already exists", dst)
+	}
+
+	if custom, ok := c.CustomProviders[src]; ok {
+		headers := make(map[string]string, len(custom.Headers))
+		for k, v := range custom.Headers {
+			headers[k] = v
+		}
+		custom.Headers = headers
+		custom.ExtraBody = copyExtraBody(custom.ExtraBody)
+		c.CustomProviders[dst] = custom
+		return nil
+	}
+	defaults, ok := BuiltinProviderDefaults(src)
+	if !ok {
+		return fmt.Errorf("provider %q not found", src)
+	}
+	if !openAICompatibleBuiltins[src] {
+		return fmt.Errorf("cannot clone %q: it does not use the OpenAI-compatible API", src)
+	}
+	pc := c.Providers[src]
+	keyEnv := strings.TrimSpace(pc.APIKeyEnv)
+	if keyEnv == "" {
+		keyEnv = defaults.APIKeyEnv
+	}
+	headers := make(map[string]string, len(pc.Headers))
+	for k, v := range pc.Headers {
+		headers[k] = v
+	}
+	return c.AddCustomProvider(dst, OpenAICompatibleProvider{
+		BaseURL:   c.ResolveBaseURL(src),
+		APIKey:    pc.APIKey,
+		Model:     c.GetModel(src),
+		APIKeyEnv: keyEnv,
+		APIKeyRef: pc.APIKeyRef,
+		MaxTokens: pc.MaxTokens,
+		Timeout:   pc.Timeout,
+		ProxyURL:  pc.ProxyURL,
+		Headers:   headers,
+		ExtraBody: copyExtraBody(pc.ExtraBody),
+	})
+}
+
+// copyExtraBody returns a deep copy of an extra_body map, so nested objects
+// and arrays are not shared with the original.
+func copyExtraBody(extra map[string]any) map[string]any {
+	if extra == nil {
+		return nil
+	}
+	out, _ := copyJSONValue(extra).(map[string]any)
+	return out
+}
+
+func copyJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = copyJSONValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = copyJSONValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// RenameCustomProvider moves a custom provider to a new name, carrying over
+// references to it from current_provider, fallback_providers and
+// model_aliases.
+func (c *Config) RenameCustomProvider(oldName, newName string) error {
+	oldName = strings.ToLower(strings.TrimSpace(oldName))
+	newName = strings.ToLower(strings.TrimSpace(newName))
+	if oldName == "" || newName == "" {
+		return fmt.Errorf("provider name is required")
+	}
+	if IsBuiltinProvider(oldName) {
+		return fmt.Errorf("cannot rename built-in provider")
+	}
+	if IsBuiltinProvider(newName) {
+		return fmt.Errorf("%q is a built-in provider", newName)
+	}
+	c.normalize()
+	custom, ok := c.CustomProviders[oldName]
+	if !ok {
+		return fmt.Errorf("provider %q not found", oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, exists := c.CustomProviders[newName]; exists {
+		return fmt.Errorf("provider %q already exists", newName)
+	}
+
+	delete(c.CustomProviders, oldName)
+	c.CustomProviders[newName] = custom
+	if c.CurrentProvider == oldName {
+		c.CurrentProvider = newName
+	}
+	for i, name := range c.Fallbacks {
+		if name == oldName {
+			c.Fallbacks[i] = newName
+		}
+	}
+	if aliases, ok := c.ModelAliases[oldName]; ok {
+		delete(c.ModelAliases, oldName)
+		c.ModelAliases[newName] = aliases
+	}
+	return nil
+}
+
 func writeSecureJSON(path string, payload any) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o700); err != nil {
@@ -605,6 +1124,9 @@ func writeSecureJSON(path string, payload any) error {
 		return fmt.Errorf("encode config: %w", err)
 	}
 
+	if err := backupExisting(path, encoded); err != nil {
+		return err
+	}
 	tmp := path + ".tmp"
 	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
 		return fmt.Errorf("write temp config: %w", err)
@@ -1,14 +1,32 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/secrets"
 )
 
+func isolateHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("ASK_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	return home
+}
+
 func TestDefaultPathUsesAskDirectory(t *testing.T) {
+	isolateHome(t)
 	path, err := DefaultPath()
 	if err != nil {
 		t.Fatalf("DefaultPath() error = %v", err)
@@ -20,6 +38,7 @@ func TestDefaultPathUsesAskDirectory(t *testing.T) {
 }
 
 func TestDefaultTemplatePathUsesAskDirectory(t *testing.T) {
+	isolateHome(t)
 	path, err := DefaultTemplatePath()
 	if err != nil {
 		t.Fatalf("DefaultTemplatePath() error = %v", err)
@@ -30,6 +49,58 @@ func TestDefaultTemplatePathUsesAskDirectory(t *testing.T) {
 	}
 }
 
+func TestDefaultDirPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		xdg    string // relative to home; "" leaves XDG_CONFIG_HOME unset
+		askDir string // ASK_CONFIG_DIR relative to home
+		mkdirs []string
+		want   string
+	}{
+		{name: "fresh install", want: ".ask"},
+		{name: "xdg config home", xdg: "xdg", want: filepath.Join("xdg", "ask")},
+		{name: "existing dot config", mkdirs: []string{filepath.Join(".config", "ask")}, want: filepath.Join(".config", "ask")},
+		{name: "xdg beats dot config", xdg: "xdg", mkdirs: []string{filepath.Join(".config", "ask")}, want: filepath.Join("xdg", "ask")},
+		{name: "legacy dir kept", xdg: "xdg", mkdirs: []string{".ask", filepath.Join(".config", "ask")}, want: ".ask"},
+		{name: "explicit dir wins", xdg: "xdg", askDir: "custom", mkdirs: []string{".ask"}, want: "custom"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			home := isolateHome(t)
+			if tc.xdg != "" {
+				t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, tc.xdg))
+			}
+			if tc.askDir != "" {
+				t.Setenv("ASK_CONFIG_DIR", filepath.Join(home, tc.askDir))
+			}
+			for _, dir := range tc.mkdirs {
+				if err := os.MkdirAll(filepath.Join(home, dir), 0o700); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := DefaultDir()
+			if err != nil {
+				t.Fatalf("DefaultDir() error = %v", err)
+			}
+			if want := filepath.Join(home, tc.want); got != want {
+				t.Fatalf("DefaultDir() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDefaultDirIgnoresRelativeXDGConfigHome(t *testing.T) {
+	home := isolateHome(t)
+	t.Setenv("XDG_CONFIG_HOME", "relative/config")
+	got, err := DefaultDir()
+	if err != nil {
+		t.Fatalf("DefaultDir() error = %v", err)
+	}
+	if want := filepath.Join(home, ".ask"); got != want {
+		t.Fatalf("DefaultDir() = %q, want %q", got, want)
+	}
+}
+
 func TestAddCustomProviderDefaults(t *testing.T) {
 	cfg := DefaultConfig()
 	err := cfg.AddCustomProvider("myproxy", OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1"})
@@ -45,6 +116,92 @@ func TestAddCustomProviderDefaults(t *testing.T) {
 	}
 }
 
+func TestRenameCustomProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, name := range []string{"mypoxy", "other"} {
+		if err := cfg.AddCustomProvider(name, OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1", Model: "m1"}); err != nil {
+			t.Fatalf("AddCustomProvider error = %v", err)
+		}
+	}
+	cfg.SetCurrentProvider("mypoxy")
+	cfg.Fallbacks = []string{"openai", "mypoxy"}
+	if err := cfg.SetModelAlias("mypoxy", "fast", "m1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.RenameCustomProvider("mypoxy", "myproxy"); err != nil {
+		t.Fatalf("RenameCustomProvider error = %v", err)
+	}
+	if _, ok := cfg.CustomProviders["mypoxy"]; ok {
+		t.Fatal("old provider still present")
+	}
+	if cfg.CustomProviders["myproxy"].Model != "m1" || cfg.CurrentProvider != "myproxy" {
+		t.Fatalf("renamed provider = %+v, current = %q", cfg.CustomProviders["myproxy"], cfg.CurrentProvider)
+	}
+	if cfg.Fallbacks[1] != "myproxy" || cfg.ResolveModel("myproxy", "fast") != "m1" {
+		t.Fatalf("fallbacks = %v, aliases = %v", cfg.Fallbacks, cfg.ModelAliases)
+	}
+
+	for _, tt := range [][2]string{{"myproxy", "openai"}, {"myproxy", "other"}, {"openai", "x"}, {"missing", "x"}} {
+		if err := cfg.RenameCustomProvider(tt[0], tt[1]); err == nil {
+			t.Errorf("RenameCustomProvider(%q, %q) expected error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestCloneProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.AddCustomProvider("myproxy", OpenAICompatibleProvider{
+		BaseURL: "https://llm.example.com/v1",
+		Model:   "m1",
+		Headers: map[string]string{"X-Team": "a"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.CloneProvider("myproxy", "myproxy-b"); err != nil {
+		t.Fatalf("CloneProvider error = %v", err)
+	}
+	clone := cfg.CustomProviders["myproxy-b"]
+	clone.Headers["X-Team"] = "b"
+	if cfg.CustomProviders["myproxy"].Headers["X-Team"] != "a" || clone.BaseURL != "https://llm.example.com/v1" || clone.Model != "m1" {
+		t.Fatalf("clone = %+v, original = %+v", clone, cfg.CustomProviders["myproxy"])
+	}
+
+	cfg.SetModel("groq", "llama-3.3-70b")
+	if err := cfg.CloneProvider("groq", "groq-work"); err != nil {
+		t.Fatalf("CloneProvider(groq) error = %v", err)
+	}
+	groq := cfg.CustomProviders["groq-work"]
+	if groq.BaseURL != "https://api.groq.com/openai/v1" || groq.Model != "llama-3.3-70b" || groq.APIKeyEnv != "GROQ_API_KEY" || groq.ChatPath != "/chat/completions" {
+		t.Fatalf("materialized groq = %+v", groq)
+	}
+
+	for _, tt := range [][2]string{{"anthropic", "x"}, {"missing", "x"}, {"groq", "openai"}, {"groq", "myproxy"}} {
+		if err := cfg.CloneProvider(tt[0], tt[1]); err == nil {
+			t.Errorf("CloneProvider(%q, %q) expected error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestCloneProviderCopiesExtraBody(t *testing.T) {
+	cfg := DefaultConfig()
+	groq := cfg.Providers["groq"]
+	groq.ExtraBody = map[string]any{"reasoning": map[string]any{"effort": "low"}}
+	cfg.Providers["groq"] = groq
+	if err := cfg.CloneProvider("groq", "groq-work"); err != nil {
+		t.Fatalf("CloneProvider error = %v", err)
+	}
+	clone := cfg.CustomProviders["groq-work"].ExtraBody
+	nested, _ := clone["reasoning"].(map[string]any)
+	if nested["effort"] != "low" {
+		t.Fatalf("clone extra_body = %v", clone)
+	}
+	nested["effort"] = "high"
+	if cfg.Providers["groq"].ExtraBody["reasoning"].(map[string]any)["effort"] != "low" {
+		t.Fatal("clone shares nested extra_body with the original")
+	}
+}
+
 func TestResolveAPIKeyPrecedence_CustomProvider(t *testing.T) {
 	cfg := DefaultConfig()
 	if err := cfg.AddCustomProvider("proxy", OpenAICompatibleProvider{
@@ -61,6 +218,78 @@ func TestResolveAPIKeyPrecedence_CustomProvider(t *testing.T) {
 	}
 }
 
+func TestResolveBasicAuth(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.AddCustomProvider("gateway", OpenAICompatibleProvider{
+		BaseURL:          "https://llm.example.com/v1",
+		BasicUser:        "alice",
+		BasicPassword:    "from-config",
+		BasicPasswordEnv: "GATEWAY_PASSWORD",
+	}); err != nil {
+		t.Fatalf("AddCustomProvider() error = %v", err)
+	}
+
+	t.Setenv("GATEWAY_PASSWORD", "")
+	if user, pass := cfg.ResolveBasicAuth("gateway"); user != "alice" || pass != "from-config" {
+		t.Fatalf("ResolveBasicAuth() = %q, %q", user, pass)
+	}
+	t.Setenv("GATEWAY_PASSWORD", "from-env")
+	if _, pass := cfg.ResolveBasicAuth("gateway"); pass != "from-env" {
+		t.Fatalf("ResolveBasicAuth() password = %q, want from-env", pass)
+	}
+	if user, _ := cfg.ResolveBasicAuth("openai"); user != "" {
+		t.Fatalf("built-in provider resolved Basic user %q", user)
+	}
+}
+
+func TestResolveBaseURLOllamaHostEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", "http://127.0.0.1:11434"},
+		{"gpu-box", "http://gpu-box:11434"},
+		{"0.0.0.0:8080", "http://0.0.0.0:8080"},
+		{"https://ollama.example.com/", "https://ollama.example.com:443"},
+		{"http://[::1]", "http://[::1]:11434"},
+	}
+	for _, tt := range tests {
+		t.Setenv("OLLAMA_HOST", tt.env)
+		if got := DefaultConfig().ResolveBaseURL("ollama"); got != tt.want {
+			t.Errorf("OLLAMA_HOST=%q: ResolveBaseURL = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+
+	t.Setenv("OLLAMA_HOST", "gpu-box")
+	cfg := DefaultConfig()
+	cfg.SetBaseURL("ollama", "http://configured:11434")
+	if got := cfg.ResolveBaseURL("ollama"); got != "http://configured:11434" {
+		t.Fatalf("explicit base URL lost to OLLAMA_HOST: %q", got)
+	}
+}
+
+func TestResolveBaseURLProviderEnv(t *testing.T) {
+	t.Setenv("OPENAI_BASE_URL", "https://gateway.example.com/v1/")
+	t.Setenv("ANTHROPIC_BASE_URL", "")
+	cfg := DefaultConfig()
+	if got := cfg.ResolveBaseURL("openai"); got != "https://gateway.example.com/v1" {
+		t.Fatalf("OPENAI_BASE_URL ignored: ResolveBaseURL = %q", got)
+	}
+	if got := cfg.ResolveBaseURL("anthropic"); got != "https://api.anthropic.com" {
+		t.Fatalf("OPENAI_BASE_URL leaked to anthropic: %q", got)
+	}
+
+	cfg.SetBaseURL("openai", "https://configured.example.com/v1")
+	if got := cfg.ResolveBaseURL("openai"); got != "https://configured.example.com/v1" {
+		t.Fatalf("explicit base URL lost to OPENAI_BASE_URL: %q", got)
+	}
+
+	t.Setenv("OPENAI_BASE_URL", "")
+	if got := DefaultConfig().ResolveBaseURL("openai"); got != "https://api.openai.com/v1" {
+		t.Fatalf("ResolveBaseURL without env = %q", got)
+	}
+}
+
 func TestSetAPIKeyAffectsCustomProvider(t *testing.T) {
 	cfg := DefaultConfig()
 	if err := cfg.AddCustomProvider("proxy", OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1"}); err != nil {
@@ -107,6 +336,126 @@ func TestSaveLoadRoundTrip(t *testing.T) {
 	}
 }
 
+func TestGetTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.GetTimeout("openai"); got != 0 {
+		t.Fatalf("unset timeout = %s, want 0", got)
+	}
+	pc := cfg.Providers["openai"]
+	pc.Timeout = 45
+	cfg.Providers["openai"] = pc
+	cfg.CustomProviders = map[string]OpenAICompatibleProvider{"local": {BaseURL: "http://localhost:1234/v1", Timeout: 300}}
+
+	if got := cfg.GetTimeout("OpenAI"); got != 45*time.Second {
+		t.Fatalf("openai timeout = %s", got)
+	}
+	if got := cfg.GetTimeout("local"); got != 5*time.Minute {
+		t.Fatalf("custom timeout = %s", got)
+	}
+}
+
+func TestDefaultsSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{"version":1,"render_markdown":true,"defaults":{"timeout_seconds":180,"render_markdown":false},
+		"providers":{"openai":{"timeout_seconds":30}}}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if cfg.RenderMarkdown {
+		t.Fatal("defaults.render_markdown was not applied")
+	}
+	if got := cfg.GetTimeout("anthropic"); got != 3*time.Minute {
+		t.Fatalf("default timeout = %s, want 3m", got)
+	}
+	if got := cfg.GetTimeout("openai"); got != 30*time.Second {
+		t.Fatalf("provider timeout = %s, want it to win over defaults", got)
+	}
+
+	if err := cfg.Set("defaults.timeout_seconds", "60"); err != nil {
+		t.Fatalf("Set error = %v", err)
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		RenderMarkdown bool           `json:"render_markdown"`
+		Defaults       map[string]any `json:"defaults"`
+	}
+	if err := json.Unmarshal(buf, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.RenderMarkdown || saved.Defaults["timeout_seconds"] != float64(60) || saved.Defaults["render_markdown"] != nil {
+		t.Fatalf("saved config = %s", buf)
+	}
+	problems, err := UnknownKeys([]byte(`{"defaults":{"timeout":5}}`))
+	if err != nil || len(problems) != 1 || !strings.Contains(problems[0].Error(), "defaults.timeout") {
+		t.Fatalf("UnknownKeys = %v, %v", problems, err)
+	}
+}
+
+func TestModelAliasesAreProviderScoped(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.SetModelAlias("openrouter", "Fast", "anthropic/claude-3-5-haiku"); err != nil {
+		t.Fatalf("SetModelAlias error = %v", err)
+	}
+	if err := cfg.SetModelAlias("openai", "fast", "gpt-4o-mini"); err != nil {
+		t.Fatalf("SetModelAlias error = %v", err)
+	}
+	if got := cfg.ResolveModel("openrouter", "FAST"); got != "anthropic/claude-3-5-haiku" {
+		t.Fatalf("openrouter fast = %q", got)
+	}
+	if got := cfg.ResolveModel("openai", "fast"); got != "gpt-4o-mini" {
+		t.Fatalf("openai fast = %q", got)
+	}
+	if got := cfg.ResolveModel("groq", "fast"); got != "fast" {
+		t.Fatalf("unscoped alias should pass through, got %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !loaded.RemoveModelAlias("openai", "fast") || loaded.RemoveModelAlias("openai", "fast") {
+		t.Fatal("RemoveModelAlias should succeed once")
+	}
+	if _, ok := loaded.ModelAliases["openai"]; ok {
+		t.Fatalf("empty alias scope should be dropped: %v", loaded.ModelAliases)
+	}
+}
+
+func TestSetAliasRejectsOnlyCommandNames(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, name := range []string{"rm", "list", "ms"} {
+		if err := cfg.SetAlias(name, "models select"); err != nil {
+			t.Fatalf("SetAlias(%q) error = %v", name, err)
+		}
+	}
+	if err := cfg.SetAlias("Models", "chat"); err == nil {
+		t.Fatal("expected an alias named after a command to be rejected")
+	}
+
+	cfg.Aliases["chat"] = "models list"
+	found := false
+	for _, problem := range cfg.Validate() {
+		found = found || strings.Contains(problem.Error(), "aliases.chat")
+	}
+	if !found {
+		t.Fatalf("Validate did not report the shadowing alias: %v", cfg.Validate())
+	}
+}
+
 func TestEnsureTemplateCreatesTemplateOnce(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.template.json")
@@ -190,7 +539,7 @@ func TestDefaultConfigUsesBuiltinProviderDefaults(t *testing.T) {
 	}
 	for _, name := range BuiltinProviderNames() {
 		def, _ := BuiltinProviderDefaults(name)
-		if got := cfg.ResolveBaseURL(name); got == "" {
+		if got := cfg.ResolveBaseURL(name); got == "" && def.BaseURL != "" {
 			t.Fatalf("provider %q resolved empty base_url", name)
 		}
 		if def.APIKeyEnv != "" {
@@ -246,3 +595,552 @@ func TestSaveKeepsProviderScaffoldInDefaultConfig(t *testing.T) {
 		t.Fatalf("expected default config to include default provider model, got: %s", content)
 	}
 }
+
+func TestImportMergesWithoutClobberingSecrets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetAPIKey("openai", "sk-existing")
+	cfg.SetModel("openai", "gpt-local")
+
+	incoming := `{
+  "current_provider": "anthropic",
+  "providers": {
+    "openai": {"api_key": "sk-imported", "model": "gpt-imported"},
+    "anthropic": {"api_key": "********1234", "model": "claude-x"},
+    "gemini": {"api_key": "g-imported"}
+  },
+  "custom_providers": {
+    "proxy": {"base_url": "https://llm.example.com/v1/", "model": "m"}
+  }
+}`
+	if err := cfg.Import([]byte(incoming)); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if got := cfg.Providers["openai"]; got.APIKey != "sk-existing" || got.Model != "gpt-imported" {
+		t.Fatalf("openai = %+v", got)
+	}
+	if got := cfg.Providers["anthropic"]; got.APIKey != "" || got.Model != "claude-x" {
+		t.Fatalf("anthropic = %+v; masked keys must not be imported", got)
+	}
+	if got := cfg.Providers["gemini"].APIKey; got != "g-imported" {
+		t.Fatalf("gemini api key = %q", got)
+	}
+	if got := cfg.CustomProviders["proxy"]; got.BaseURL != "https://llm.example.com/v1" || got.ChatPath != "/chat/completions" {
+		t.Fatalf("proxy = %+v", got)
+	}
+	if cfg.CurrentProvider != "anthropic" {
+		t.Fatalf("current provider = %q", cfg.CurrentProvider)
+	}
+	if !cfg.RenderMarkdown {
+		t.Fatalf("render_markdown should be kept when absent from the import")
+	}
+}
+
+func TestImportIgnoresMaskedSecretsOfNewCustomProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	incoming := `{"custom_providers": {"proxy": {
+  "base_url": "https://llm.example.com/v1",
+  "api_key": "********1234",
+  "basic_user": "svc",
+  "basic_password": "********abcd"
+}}}`
+	if err := cfg.Import([]byte(incoming)); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	got := cfg.CustomProviders["proxy"]
+	if got.APIKey != "" || got.BasicPassword != "" {
+		t.Fatalf("proxy = %+v; masked secrets must not be imported", got)
+	}
+	if got.BasicUser != "svc" {
+		t.Fatalf("basic user = %q", got.BasicUser)
+	}
+}
+
+func TestImportRejectsBuiltinOverrides(t *testing.T) {
+	for _, incoming := range []string{
+		`{"custom_providers": {"openai": {"base_url": "https://evil.example.com"}}}`,
+		`{"providers": {"myproxy": {"base_url": "https://llm.example.com"}}}`,
+	} {
+		cfg := DefaultConfig()
+		if err := cfg.Import([]byte(incoming)); err == nil {
+			t.Fatalf("Import(%s) expected error", incoming)
+		}
+		if len(cfg.CustomProviders) != 0 {
+			t.Fatalf("rejected import must not modify config: %+v", cfg.CustomProviders)
+		}
+	}
+}
+
+func TestImportFailureLeavesConfigUnchanged(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetModel("openai", "gpt-local")
+	incoming := `{
+  "providers": {"openai": {"model": "gpt-imported"}},
+  "custom_providers": {"proxy": {"base_url": "https://llm.example.com/v1"}},
+  "model_aliases": {"openai": {"fast": "fast"}}
+}`
+	if err := cfg.Import([]byte(incoming)); err == nil {
+		t.Fatal("expected an error for a self-referencing alias")
+	}
+	if _, ok := cfg.CustomProviders["proxy"]; ok || cfg.GetModel("openai") != "gpt-local" {
+		t.Fatalf("failed import left partial changes: model=%q custom=%v", cfg.GetModel("openai"), cfg.CustomProviders)
+	}
+}
+
+func TestExportMasksKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetAPIKey("openai", "sk-secret")
+	buf, err := cfg.Export(func(string) string { return "***" })
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if strings.Contains(string(buf), "sk-secret") {
+		t.Fatalf("export leaked key: %s", buf)
+	}
+	if cfg.Providers["openai"].APIKey != "sk-secret" {
+		t.Fatalf("Export must not modify the config")
+	}
+}
+
+func TestResolveAPIKeyPrefersKeychainRef(t *testing.T) {
+	fake := secrets.NewMemory()
+	original := keychain
+	keychain = fake
+	t.Cleanup(func() { keychain = original })
+
+	cfg := DefaultConfig()
+	cfg.SetAPIKey("openai", "from-config")
+	cfg.SetAPIKeyRef("openai", secrets.Ref("openai"))
+	t.Setenv("OPENAI_API_KEY", "")
+
+	if got := cfg.ResolveAPIKey("openai"); got != "from-config" {
+		t.Fatalf("missing keychain entry should fall back, got %q", got)
+	}
+	if err := fake.Store("openai", "from-keychain"); err != nil {
+		t.Fatalf("Store error = %v", err)
+	}
+	t.Setenv("OPENAI_API_KEY", "from-env")
+	if got := cfg.ResolveAPIKey("openai"); got != "from-keychain" {
+		t.Fatalf("ResolveAPIKey() = %q, want from-keychain", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Fatalf("default config problems = %v", problems)
+	}
+
+	cfg.CustomProviders["broken"] = OpenAICompatibleProvider{}
+	cfg.CustomProviders["a"] = OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1", APIKeyEnv: "ASK_TEST_UNSET_KEY"}
+	cfg.CustomProviders["b"] = OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1/", APIKey: "k"}
+	cfg.CurrentProvider = "ghost"
+	cfg.Fallbacks = []string{"phantom"}
+	t.Setenv("ASK_TEST_UNSET_KEY", "")
+
+	var got []string
+	for _, p := range cfg.Validate() {
+		got = append(got, p.Error())
+	}
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{
+		"custom_providers.broken: base_url is required",
+		`current_provider: provider "ghost" does not exist`,
+		`fallback_providers: provider "phantom" does not exist`,
+		"a and b use the same base_url",
+		"a: api_key_env ASK_TEST_UNSET_KEY is not set",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Validate() missing %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	problems, err := UnknownKeys([]byte(`{
+  "version": 1,
+  "render_markdwon": true,
+  "providers": {"openai": {"api_key": "", "modle": "x"}},
+  "custom_providers": {"proxy": {"base_url": "https://x", "header": {}}}
+}`))
+	if err != nil {
+		t.Fatalf("UnknownKeys error = %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Error())
+	}
+	want := []string{
+		"render_markdwon: unknown key",
+		"providers.openai.modle: unknown key",
+		"custom_providers.proxy.header: unknown key",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("UnknownKeys = %v, want %v", got, want)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Setenv("ASK_PROFILE", "")
+	base := filepath.Join(t.TempDir(), "config.json")
+	if got := ProfilePath(base, DefaultProfile); got != base {
+		t.Fatalf("default profile path = %q", got)
+	}
+	work := ProfilePath(base, "work")
+	if filepath.Base(work) != "config.work.json" {
+		t.Fatalf("work profile path = %q", work)
+	}
+
+	for _, path := range []string{base, work, TemplatePathForConfig(base)} {
+		if err := Save(path, DefaultConfig()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := ListProfiles(base)
+	if err != nil {
+		t.Fatalf("ListProfiles error = %v", err)
+	}
+	if strings.Join(names, ",") != "default,work" {
+		t.Fatalf("profiles = %v", names)
+	}
+
+	if got, _ := ResolveProfile(base, ""); got != DefaultProfile {
+		t.Fatalf("initial profile = %q", got)
+	}
+	if err := SetActiveProfile(base, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ResolveProfile(base, ""); got != "work" {
+		t.Fatalf("saved profile = %q", got)
+	}
+	t.Setenv("ASK_PROFILE", "home")
+	if got, _ := ResolveProfile(base, ""); got != "home" {
+		t.Fatalf("env profile = %q", got)
+	}
+	if got, _ := ResolveProfile(base, "Default"); got != DefaultProfile {
+		t.Fatalf("flag profile = %q", got)
+	}
+	if _, err := ResolveProfile(base, "../etc"); err == nil {
+		t.Fatal("expected invalid profile name error")
+	}
+}
+
+func TestExtraBodySaveLoad(t *testing.T) {
+	cfg := DefaultConfig()
+	pc := cfg.Providers["openai"]
+	pc.ExtraBody = map[string]any{"reasoning_effort": "low"}
+	cfg.Providers["openai"] = pc
+	pc = cfg.Providers["gemini"]
+	pc.ExtraBody = map[string]any{}
+	cfg.Providers["gemini"] = pc
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if got := strings.Count(string(buf), `"extra_body"`); got != 1 {
+		t.Fatalf("extra_body written %d times, want only the non-empty one: %s", got, buf)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := loaded.GetExtraBody("openai")["reasoning_effort"]; got != "low" {
+		t.Fatalf("openai extra_body reasoning_effort = %v", got)
+	}
+}
+
+func TestSaveKeepsBackupAndRestoreSwapsIt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := RestoreBackup(path); !errors.Is(err, ErrNoBackup) {
+		t.Fatalf("RestoreBackup() without backup error = %v, want ErrNoBackup", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.SetCurrentProvider("openai")
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(BackupPathForConfig(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("first save should not create a backup, stat err = %v", err)
+	}
+	cfg.SetCurrentProvider("anthropic")
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	// An identical save must not replace the backup with the current config.
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(BackupPathForConfig(path))
+	if err != nil {
+		t.Fatalf("stat backup: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Fatalf("backup mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if err := RestoreBackup(path); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	restored, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.CurrentProvider != "openai" {
+		t.Fatalf("restored provider = %q, want openai", restored.CurrentProvider)
+	}
+	if err := RestoreBackup(path); err != nil {
+		t.Fatalf("second RestoreBackup() error = %v", err)
+	}
+	if again, _ := Load(path); again.CurrentProvider != "anthropic" {
+		t.Fatalf("provider after undo = %q, want anthropic", again.CurrentProvider)
+	}
+}
+
+func TestRestoreBackupRejectsInvalidBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"current_provider":"openai"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(BackupPathForConfig(path), []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreBackup(path); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Fatalf("RestoreBackup() error = %v, want invalid backup error", err)
+	}
+	if buf, _ := os.ReadFile(path); string(buf) != `{"current_provider":"openai"}` {
+		t.Fatalf("config changed after failed restore: %s", buf)
+	}
+}
+
+func TestUpdateKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := Save(path, DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	providers := []string{"openai", "anthropic"}
+	const rounds = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, len(providers)*rounds)
+	for _, provider := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				errs <- Update(path, func(cfg *Config) error {
+					cfg.SetModel(provider, fmt.Sprintf("%s-%d", provider, i))
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, provider := range providers {
+		if got, want := cfg.GetModel(provider), fmt.Sprintf("%s-%d", provider, rounds-1); got != want {
+			t.Fatalf("GetModel(%s) = %q, want %q", provider, got, want)
+		}
+	}
+	if _, err := os.Stat(LockPathForConfig(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lock file left behind, stat err = %v", err)
+	}
+}
+
+func TestLockTimesOutAndTakesOverStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	unlock, err := Lock(path, LockTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lock(path, 50*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Lock() error = %v, want ErrLocked", err)
+	}
+
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(LockPathForConfig(path), old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlockStale, err := Lock(path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Lock() over stale lock error = %v", err)
+	}
+	// The first holder's release must not remove the new holder's lock.
+	unlock()
+	if _, err := os.Stat(LockPathForConfig(path)); err != nil {
+		t.Fatalf("lock removed by previous holder: %v", err)
+	}
+	unlockStale()
+	if _, err := os.Stat(LockPathForConfig(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lock file left behind, stat err = %v", err)
+	}
+}
+
+func TestLockStaleTakeoverRace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	lockPath := LockPathForConfig(path)
+	if err := os.WriteFile(lockPath, []byte("1 1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first locker pauses after seeing the stale lock until a second
+	// locker has taken it over, then resumes its own takeover.
+	paused, resume := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	staleLockSeen = func() {
+		first := false
+		once.Do(func() { first = true })
+		if first {
+			close(paused)
+			<-resume
+		}
+	}
+	t.Cleanup(func() { staleLockSeen = func() {} })
+
+	result := make(chan error, 1)
+	go func() {
+		unlock, err := Lock(path, 100*time.Millisecond)
+		if err == nil {
+			unlock()
+		}
+		result <- err
+	}()
+	<-paused
+	unlock, err := Lock(path, LockTimeout)
+	if err != nil {
+		t.Fatalf("second Lock() error = %v", err)
+	}
+	defer unlock()
+	close(resume)
+
+	if err := <-result; !errors.Is(err, ErrLocked) {
+		t.Fatalf("first Lock() error = %v, want ErrLocked while the second locker holds it", err)
+	}
+}
+
+func TestSchemaDocumentsEveryField(t *testing.T) {
+	buf, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(buf, &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+
+	objects := map[string]map[string]map[string]any{
+		"config":          schema.Properties,
+		"provider":        schema.Defs["provider"].Properties,
+		"custom_provider": schema.Defs["custom_provider"].Properties,
+	}
+	for object, props := range objects {
+		if len(props) == 0 {
+			t.Fatalf("%s has no properties", object)
+		}
+		for name, prop := range props {
+			if prop["description"] == nil || prop["description"] == "" {
+				t.Errorf("%s.%s has no description", object, name)
+			}
+		}
+	}
+	if schema.Properties["render_markdown"]["type"] != "boolean" || schema.Properties["retries"]["type"] != "integer" {
+		t.Fatalf("unexpected field types: %v, %v", schema.Properties["render_markdown"], schema.Properties["retries"])
+	}
+	names, _ := schema.Properties["providers"]["propertyNames"].(map[string]any)
+	enum, _ := names["enum"].([]any)
+	if len(enum) != len(BuiltinProviderNames()) {
+		t.Fatalf("providers enum = %v, want built-in names", enum)
+	}
+}
+
+func TestSchemaKeyRoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := []byte(`{"$schema":"./config.schema.json","version":1,"current_provider":"openai"}`)
+	if problems, err := UnknownKeys(doc); err != nil || len(problems) > 0 {
+		t.Fatalf("UnknownKeys() = %v, %v", problems, err)
+	}
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if buf, _ := os.ReadFile(path); !strings.Contains(string(buf), `"$schema": "./config.schema.json"`) {
+		t.Fatalf("$schema dropped on save: %s", buf)
+	}
+}
+
+func TestConfigGetSet(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.AddCustomProvider("proxy", OpenAICompatibleProvider{BaseURL: "https://llm.example.com/v1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sets := []struct{ key, value, want string }{
+		{"render_markdown", "false", "false"},
+		{"retries", "3", "3"},
+		{"ollama_host", "http://gpu-box:11434", "http://gpu-box:11434"},
+		{"current_provider", "Proxy", "proxy"},
+		{"providers.openai.model", "gpt-5", "gpt-5"},
+		{"providers.openai.timeout_seconds", "90", "90"},
+		{"custom_providers.proxy.base_url", "https://llm.internal/v1/", "https://llm.internal/v1"},
+		{"custom_providers.proxy.model", "m", "m"},
+	}
+	for _, tc := range sets {
+		if err := cfg.Set(tc.key, tc.value); err != nil {
+			t.Fatalf("Set(%s, %s) error = %v", tc.key, tc.value, err)
+		}
+		if got, err := cfg.Get(tc.key); err != nil || got != tc.want {
+			t.Fatalf("Get(%s) = %q, %v; want %q", tc.key, got, err, tc.want)
+		}
+	}
+	if cfg.RenderMarkdown || cfg.GetModel("openai") != "gpt-5" || cfg.GetTimeout("openai") != 90*time.Second {
+		t.Fatalf("setters not applied: %+v", cfg)
+	}
+
+	bad := []struct{ key, value, want string }{
+		{"render_markdown", "maybe", "want true or false"},
+		{"retries", "many", "want an integer"},
+		{"providers.openai.api_key", "sk-x", "ask key set"},
+		{"providers.openai.headers", "x", "not a scalar field"},
+		{"providers.nope.model", "m", "not a built-in provider"},
+		{"custom_providers.missing.model", "m", "does not exist"},
+		{"custom_providers.proxy.base_url", "", "cannot be empty"},
+		{"current_provider", "nope", "not configured"},
+		{"no_such_key", "1", "unknown config key"},
+		{"version", "2", "cannot be set"},
+	}
+	for _, tc := range bad {
+		if err := cfg.Set(tc.key, tc.value); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("Set(%s, %q) error = %v, want %q", tc.key, tc.value, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// secretFields are provider keys that config set refuses, so secrets are not
+// left in shell history; `ask key set` stores them instead.
+var secretFields = map[string]bool{"api_key": true, "basic_password": true}
+
+// IsSecretField reports whether key names a field holding a secret.
+func IsSecretField(key string) bool {
+	parts := strings.Split(key, ".")
+	return secretFields[parts[len(parts)-1]]
+}
+
+// Get returns the value of the scalar field at the dotted JSON path key, such
+// as render_markdown or providers.openai.model. Unset fields read as their
+// zero value.
+func (c *Config) Get(key string) (string, error) {
+	c.normalize()
+	var value string
+	err := c.withField(key, false, func(field reflect.Value) error {
+		switch field.Kind() {
+		case reflect.Bool:
+			value = strconv.FormatBool(field.Bool())
+		case reflect.Int:
+			value = strconv.FormatInt(field.Int(), 10)
+		default:
+			value = field.String()
+		}
+		return nil
+	})
+	return value, err
+}
+
+// Set parses value for the scalar field at the dotted JSON path key and stores
+// it. Provider models, base URLs and the current provider go through their
+// dedicated setters; secrets and non-scalar fields are rejected.
+func (c *Config) Set(key, value string) error {
+	c.normalize()
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if IsSecretField(key) {
+		return fmt.Errorf("%s holds a secret; use `ask key set` instead", key)
+	}
+	if key == "version" || key == "$schema" {
+		return fmt.Errorf("%s cannot be set", key)
+	}
+
+	// Resolve the key first so the dedicated setters below only see keys
+	// that name a real scalar field.
+	if err := c.withField(key, false, func(reflect.Value) error { return nil }); err != nil {
+		return err
+	}
+	parts := strings.Split(key, ".")
+	switch {
+	case key == "current_provider":
+		if value != "" && !c.ProviderExists(value) {
+			return fmt.Errorf("provider %q is not configured", value)
+		}
+		c.SetCurrentProvider(value)
+		return nil
+	case len(parts) == 3 && parts[2] == "model":
+		c.SetModel(parts[1], value)
+		return nil
+	case len(parts) == 3 && parts[2] == "base_url" && parts[0] == "providers":
+		c.SetBaseURL(parts[1], value)
+		return nil
+	case len(parts) == 3 && parts[2] == "base_url":
+		if value == "" {
+			return fmt.Errorf("%s cannot be empty", key)
+		}
+		value = strings.TrimRight(value, "/")
+	}
+	if err := c.withField(key, true, func(field reflect.Value) error { return setScalar(field, key, value) }); err != nil {
+		return err
+	}
+	c.normalize()
+	return nil
+}
+
+func setScalar(field reflect.Value, key, value string) error {
+	switch field.Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: want true or false", value, key)
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: want an integer", value, key)
+		}
+		field.SetInt(int64(parsed))
+	default:
+		field.SetString(value)
+	}
+	return nil
+}
+
+// withField resolves key to a scalar field and calls fn with it. Provider
+// entries are map values, so fn sees a copy that is stored back when write is
+// set and fn succeeds.
+func (c *Config) withField(key string, write bool, fn func(reflect.Value) error) error {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	switch {
+	case len(parts) == 1:
+		field, err := scalarField(reflect.ValueOf(c).Elem(), key, parts[0])
+		if err != nil {
+			return err
+		}
+		return fn(field)
+	case len(parts) == 2 && parts[0] == "defaults":
+		defaults := Defaults{}
+		if c.Defaults != nil {
+			defaults = *c.Defaults
+		}
+		field, err := scalarField(reflect.ValueOf(&defaults).Elem(), key, parts[1])
+		if err != nil {
+			return err
+		}
+		if err := fn(field); err != nil {
+			return err
+		}
+		if write {
+			c.Defaults = &defaults
+		}
+		return nil
+	case len(parts) == 3 && parts[0] == "providers":
+		name := strings.ToLower(parts[1])
+		if !IsBuiltinProvider(name) {
+			return fmt.Errorf("%s: %q is not a built-in provider", key, parts[1])
+		}
+		pc := c.Providers[name]
+		field, err := scalarField(reflect.ValueOf(&pc).Elem(), key, parts[2])
+		if err != nil {
+			return err
+		}
+		if err := fn(field); err != nil {
+			return err
+		}
+		if write {
+			c.Providers[name] = pc
+		}
+		return nil
+	case len(parts) == 3 && parts[0] == "custom_providers":
+		name := strings.ToLower(parts[1])
+		custom, ok := c.CustomProviders[name]
+		if !ok {
+			return fmt.Errorf("%s: custom provider %q does not exist", key, parts[1])
+		}
+		field, err := scalarField(reflect.ValueOf(&custom).Elem(), key, parts[2])
+		if err != nil {
+			return err
+		}
+		if err := fn(field); err != nil {
+			return err
+		}
+		if write {
+			c.CustomProviders[name] = custom
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown config key %q", key)
+	}
+}
+
+// scalarField returns the string, bool or int field of struct v whose JSON
+// name is name.
+func scalarField(v reflect.Value, key, name string) (reflect.Value, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag != name {
+			continue
+		}
+		switch v.Field(i).Kind() {
+		case reflect.String, reflect.Bool, reflect.Int:
+			return v.Field(i), nil
+		default:
+			return reflect.Value{}, fmt.Errorf("%s is not a scalar field; edit it with `ask config edit`", key)
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown config key %q", key)
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LockTimeout is how long Lock waits for another process to release the
+	// config lock.
+	LockTimeout = 5 * time.Second
+	// staleLockAge is how old a lock file must be before it is treated as
+	// left behind by a process that exited without releasing it.
+	staleLockAge     = 30 * time.Second
+	lockPollInterval = 20 * time.Millisecond
+)
+
+// ErrLocked indicates the config lock is held by another process.
+var ErrLocked = errors.New("config is locked by another ask process")
+
+// LockPathForConfig returns the advisory lock file path for configPath.
+func LockPathForConfig(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return path + ".lock"
+}
+
+// Lock acquires the advisory lock for the config at path, waiting up to
+// timeout. The lock is a file created with O_EXCL next to the config, so it
+// works the same on every platform. The returned func releases it.
+func Lock(path string, timeout time.Duration) (func(), error) {
+	lockPath := LockPathForConfig(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create config directory: %w", err)
+	}
+	token := strconv.Itoa(os.Getpid()) + " " + strconv.FormatInt(time.Now().UnixNano(), 10)
+	deadline := time.Now().Add(timeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_, writeErr := file.WriteString(token)
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				_ = os.Remove(lockPath)
+				return nil, fmt.Errorf("write config lock: %w", errors.Join(writeErr, closeErr))
+			}
+			return func() { releaseLock(lockPath, token) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create config lock: %w", err)
+		}
+		if isStaleLock(lockPath) {
+			staleLockSeen()
+			breakStaleLock(lockPath)
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w (%s)", ErrLocked, lockPath)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// staleLockSeen runs between spotting a stale lock and breaking it; tests use
+// it to interleave two lockers.
+var staleLockSeen = func() {}
+
+func isStaleLock(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > staleLockAge
+}
+
+// breakStaleLock removes the stale lock at lockPath. Several waiters can see
+// the same stale lock, and the first to break it may already hold a fresh
+// one by the time another gets round to it, so removal happens under a
+// separate guard file and only after checking again that the lock is stale.
+func breakStaleLock(lockPath string) {
+	guard := lockPath + ".break"
+	file, err := os.OpenFile(guard, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		// A guard only outlives its holder if that process died mid-break.
+		if isStaleLock(guard) {
+			_ = os.Remove(guard)
+		}
+		return
+	}
+	_ = file.Close()
+	defer os.Remove(guard)
+	if isStaleLock(lockPath) {
+		_ = os.Remove(lockPath)
+	}
+}
+
+// releaseLock removes the lock file only while it still holds token, so a
+// lock taken over as stale by another process is left alone.
+func releaseLock(lockPath, token string) {
+	buf, err := os.ReadFile(lockPath)
+	if err != nil || string(buf) != token {
+		return
+	}
+	_ = os.Remove(lockPath)
+}
+
+// SaveLocked saves cfg to path while holding the config lock.
+func SaveLocked(path string, cfg *Config) error {
+	unlock, err := Lock(path, LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return Save(path, cfg)
+}
+
+// Update loads the config at path, applies fn and saves the result, holding
+// the config lock throughout so concurrent updates are not lost. A missing
+// config starts from defaults.
+func Update(path string, fn func(*Config) error) error {
+	unlock, err := Lock(path, LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := Load(path)
+	if err != nil && !errors.Is(err, ErrConfigNotFound) {
+		return err
+	}
+	if err := fn(cfg); err != nil {
+		return err
+	}
+	return Save(path, cfg)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile names the profile stored in the base config file itself.
+const DefaultProfile = "default"
+
+const (
+	envProfile            = "ASK_PROFILE"
+	activeProfileFileName = "active_profile"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateProfileName reports whether name can be used as a profile name.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' or '_'", name)
+	}
+	if name == "template" {
+		return fmt.Errorf("profile name %q is reserved", name)
+	}
+	return nil
+}
+
+// ProfilePath returns the config file for profile next to basePath: basePath
+// itself for the default profile and config.<profile>.json otherwise.
+func ProfilePath(basePath, profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return basePath
+	}
+	ext := filepath.Ext(basePath)
+	stem := strings.TrimSuffix(filepath.Base(basePath), ext)
+	return filepath.Join(filepath.Dir(basePath), stem+"."+profile+ext)
+}
+
+// ResolveProfile returns the active profile: override (the --profile flag),
+// then ASK_PROFILE, then the profile saved by SetActiveProfile, then default.
+func ResolveProfile(basePath, override string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(override))
+	if name == "" {
+		name = strings.ToLower(strings.TrimSpace(os.Getenv(envProfile)))
+	}
+	if name == "" {
+		saved, err := savedProfile(basePath)
+		if err != nil {
+			return "", err
+		}
+		name = saved
+	}
+	if name == "" || name == DefaultProfile {
+		return DefaultProfile, nil
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// SetActiveProfile saves profile as the one used when neither --profile nor
+// ASK_PROFILE is set.
+func SetActiveProfile(basePath, profile string) error {
+	path := filepath.Join(filepath.Dir(basePath), activeProfileFileName)
+	if profile == DefaultProfile {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reset active profile: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(profile+"\n"), 0o600); err != nil {
+		return fmt.Errorf("save active profile: %w", err)
+	}
+	return nil
+}
+
+// ListProfiles returns the default profile followed by every profile with a
+// config file next to basePath, sorted by name.
+func ListProfiles(basePath string) ([]string, error) {
+	ext := filepath.Ext(basePath)
+	stem := strings.TrimSuffix(filepath.Base(basePath), ext)
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(basePath), stem+".*"+ext))
+	if err != nil {
+		return nil, fmt.Errorf("list profiles: %w", err)
+	}
+	var names []string
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), stem+"."), ext)
+		if ValidateProfileName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+func savedProfile(basePath string) (string, error) {
+	buf, err := os.ReadFile(filepath.Join(filepath.Dir(basePath), activeProfileFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read active profile: %w", err)
+	}
+	return strings.ToLower(strings.TrimSpace(string(buf))), nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromptPlaceholder marks where the question goes in a prompt preset.
+const PromptPlaceholder = "{question}"
+
+// SetPrompt stores template as the prompt preset name, replacing any
+// existing preset with that name.
+func (c *Config) SetPrompt(name, template string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	template = strings.TrimSpace(template)
+	if name == "" {
+		return fmt.Errorf("preset name cannot be empty")
+	}
+	if strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("preset name %q cannot contain whitespace", name)
+	}
+	if template == "" {
+		return fmt.Errorf("preset template cannot be empty")
+	}
+	if c.Prompts == nil {
+		c.Prompts = map[string]string{}
+	}
+	c.Prompts[name] = template
+	return nil
+}
+
+// RemovePrompt deletes the prompt preset name and reports whether it existed.
+func (c *Config) RemovePrompt(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := c.Prompts[name]; !ok {
+		return false
+	}
+	delete(c.Prompts, name)
+	c.normalize()
+	return true
+}
+
+// Prompt returns the template stored for the prompt preset name.
+func (c *Config) Prompt(name string) (string, bool) {
+	template, ok := c.Prompts[strings.ToLower(strings.TrimSpace(name))]
+	return template, ok
+}
+
+// ApplyPrompt wraps question with template. Every {question} placeholder is
+// replaced; a template without one is followed by the question on its own
+// paragraph.
+func ApplyPrompt(template, question string) string {
+	if strings.Contains(template, PromptPlaceholder) {
+		return strings.ReplaceAll(template, PromptPlaceholder, question)
+	}
+	return template + "\n\n" + question
+}
+
+func normalizePrompts(in map[string]string) map[string]string {
+	out := map[string]string{}
+	for name, template := range in {
+		name = strings.ToLower(strings.TrimSpace(name))
+		template = strings.TrimSpace(template)
+		if name == "" || template == "" {
+			continue
+		}
+		out[name] = template
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
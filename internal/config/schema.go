@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// fieldDocs describes config fields by JSON name for the generated schema.
+// Names shared by built-in and custom providers mean the same thing in both.
+var fieldDocs = map[string]string{
+	"$schema":                  "JSON Schema used by editors to validate this file.",
+	"version":                  "Config format version.",
+	"current_provider":         "Provider used when --provider is not given.",
+	"current_models":           "Legacy per-provider models; read for compatibility and never written.",
+	"providers":                "Settings for built-in providers, keyed by provider name.",
+	"custom_providers":         "OpenAI-compatible providers, keyed by a name that is not a built-in provider.",
+	"ollama_host":              "Ollama host or URL; OLLAMA_HOST wins when the base URL is unchanged.",
+	"openai_organization":      "Sent to OpenAI as the OpenAI-Organization header.",
+	"openai_project":           "Sent to OpenAI as the OpenAI-Project header.",
+	"user_agent":               "User-Agent header sent to every provider; defaults to ask/<version>.",
+	"defaults":                 "Settings for every provider unless the provider or a flag overrides them.",
+	"render_markdown":          "Render answers as terminal markdown.",
+	"markdown_theme":           "Markdown theme name or glamour JSON style file; empty picks one automatically.",
+	"strict_json":              "Ask providers that support it to enforce the {answer, command} JSON schema.",
+	"shell":                    "Shell commands target; empty uses $SHELL, or %COMSPEC% on Windows.",
+	"system_prompt":            "Replaces the built-in instructions; the JSON response contract is still appended.",
+	"git_context":              "Add the git branch and status counts to the prompt inside a repository.",
+	"allow_code_fences":        "Let markdown answers use fenced code blocks, rendered with syntax highlighting.",
+	"highlight_commands":       "Print a syntax-highlighted preview of a suggested command above the run prompt.",
+	"retries":                  "Retries for failed requests; 0 uses the default and a negative value disables them.",
+	"model_cache_ttl_seconds":  "How long model lists are cached; 0 uses the default and a negative value disables the cache.",
+	"answer_cache_ttl_seconds": "How long answers are cached; 0 turns the answer cache off.",
+	"fallback_providers":       "Providers tried in order when the current provider fails.",
+	"model_aliases":            "Short names for models, keyed by provider and then alias.",
+	"prompts":                  "Prompt presets keyed by name; {question} marks where the question goes.",
+	"aliases":                  "Command aliases: the first argument is replaced by the words of its value.",
+
+	"base_url":            "API base URL.",
+	"api_key":             "API key stored in plain text; prefer api_key_env or api_key_ref.",
+	"model":               "Default model.",
+	"api_key_env":         "Environment variable holding the API key.",
+	"api_key_ref":         "Reference to an API key stored in the OS keychain.",
+	"api_version":         "API version, such as the Azure OpenAI api-version.",
+	"max_tokens":          "Maximum tokens in each answer.",
+	"timeout_seconds":     "Request timeout in seconds.",
+	"proxy_url":           "HTTP proxy for requests to this provider.",
+	"headers":             "Extra HTTP headers sent with every request.",
+	"extra_body":          "Fields merged into every request payload.",
+	"project":             "Vertex AI project ID.",
+	"region":              "Cloud region for Vertex AI and Bedrock.",
+	"token_command":       "Command that prints a bearer token, e.g. gcloud auth print-access-token.",
+	"models_path":         "Path of the models endpoint relative to base_url.",
+	"chat_path":           "Path of the chat completions endpoint relative to base_url.",
+	"models_response_key": "Dot path to the model list in the models response.",
+	"model_id_field":      "ID field of each entry in the models response.",
+	"auth_header":         "Header that carries the API key.",
+	"auth_prefix":         "Prefix written before the API key in auth_header.",
+	"auth_query_param":    "Send the API key as this query parameter instead of a header.",
+	"basic_user":          "User name for HTTP Basic auth.",
+	"basic_password":      "Password for HTTP Basic auth.",
+	"basic_password_env":  "Environment variable with the Basic auth password; wins over basic_password.",
+}
+
+// Schema returns a JSON Schema for config.json generated from the config
+// struct tags, so editors can complete and validate hand-written configs.
+func Schema() ([]byte, error) {
+	provider, err := objectSchema(reflect.TypeOf(ProviderConfig{}))
+	if err != nil {
+		return nil, err
+	}
+	custom, err := objectSchema(reflect.TypeOf(OpenAICompatibleProvider{}))
+	if err != nil {
+		return nil, err
+	}
+	root, err := objectSchema(reflect.TypeOf(Config{}))
+	if err != nil {
+		return nil, err
+	}
+
+	props := root["properties"].(map[string]any)
+	props["providers"] = map[string]any{
+		"type":                 "object",
+		"description":          fieldDocs["providers"],
+		"propertyNames":        map[string]any{"enum": BuiltinProviderNames()},
+		"additionalProperties": map[string]any{"$ref": "#/$defs/provider"},
+	}
+	props["custom_providers"] = map[string]any{
+		"type":                 "object",
+		"description":          fieldDocs["custom_providers"],
+		"propertyNames":        map[string]any{"not": map[string]any{"enum": BuiltinProviderNames()}},
+		"additionalProperties": map[string]any{"$ref": "#/$defs/custom_provider"},
+	}
+	root["$schema"] = schemaDraft
+	root["title"] = "ask config"
+	root["$defs"] = map[string]any{
+		"provider":        provider,
+		"custom_provider": custom,
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// objectSchema describes the JSON-tagged fields of struct type t. Unknown
+// keys are rejected, matching UnknownKeys.
+func objectSchema(t reflect.Type) (map[string]any, error) {
+	props := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		prop, err := typeSchema(t.Field(i).Type)
+		if err != nil {
+			return nil, fmt.Errorf("schema for %s.%s: %w", t.Name(), name, err)
+		}
+		if doc := fieldDocs[name]; doc != "" {
+			prop["description"] = doc
+		}
+		props[name] = prop
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}, nil
+}
+
+func typeSchema(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	case reflect.Slice:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return objectSchema(t)
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
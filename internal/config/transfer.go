@@ -0,0 +1,274 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Export returns the persisted form of the config as indented JSON. When mask
+// is non-nil, stored API keys are replaced with mask(key).
+func (c *Config) Export(mask func(string) string) ([]byte, error) {
+	c.normalize()
+	out := c.compactForSave()
+	if mask != nil {
+		providers := make(map[string]ProviderConfig, len(out.Providers))
+		for name, pc := range out.Providers {
+			if pc.APIKey != "" {
+				pc.APIKey = mask(pc.APIKey)
+			}
+			providers[name] = pc
+		}
+		out.Providers = providers
+
+		customProviders := make(map[string]OpenAICompatibleProvider, len(out.CustomProviders))
+		for name, custom := range out.CustomProviders {
+			if custom.APIKey != "" {
+				custom.APIKey = mask(custom.APIKey)
+			}
+			if custom.BasicPassword != "" {
+				custom.BasicPassword = mask(custom.BasicPassword)
+			}
+			customProviders[name] = custom
+		}
+		out.CustomProviders = customProviders
+	}
+
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode config: %w", err)
+	}
+	return append(encoded, '\n'), nil
+}
+
+// Import merges the config document in buf into c. Non-empty imported values
+// replace current ones, except that existing API keys are never overwritten
+// and masked keys (as produced by a redacted export) are ignored. Entries under
+// providers must name built-in providers, and custom providers may not reuse a
+// built-in name. The merge runs on a copy, so c is unchanged when it fails.
+func (c *Config) Import(buf []byte) error {
+	merged, err := c.clone()
+	if err != nil {
+		return err
+	}
+	if err := merged.merge(buf); err != nil {
+		return err
+	}
+	*c = *merged
+	return nil
+}
+
+// clone returns a deep copy of c.
+func (c *Config) clone() (*Config, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("copy config: %w", err)
+	}
+	out := &Config{}
+	if err := json.Unmarshal(buf, out); err != nil {
+		return nil, fmt.Errorf("copy config: %w", err)
+	}
+	return out, nil
+}
+
+func (c *Config) merge(buf []byte) error {
+	incoming := &Config{RenderMarkdown: c.RenderMarkdown}
+	if err := json.Unmarshal(buf, incoming); err != nil {
+		return fmt.Errorf("decode config: %w", err)
+	}
+	incoming.normalize()
+
+	var invalid []string
+	for name := range incoming.Providers {
+		if !IsBuiltinProvider(name) {
+			invalid = append(invalid, fmt.Sprintf("providers.%s is not a built-in provider; define it under custom_providers", name))
+		}
+	}
+	for name := range incoming.CustomProviders {
+		if IsBuiltinProvider(name) {
+			invalid = append(invalid, fmt.Sprintf("custom_providers.%s would override the built-in provider %q", name, name))
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("invalid import: %s", strings.Join(invalid, "; "))
+	}
+
+	c.normalize()
+	for name, in := range incoming.Providers {
+		name = strings.ToLower(strings.TrimSpace(name))
+		pc := c.Providers[name]
+		pc.APIKey = mergeSecret(pc.APIKey, in.APIKey)
+		pc.Model = mergeString(pc.Model, in.Model)
+		pc.BaseURL = strings.TrimRight(mergeString(pc.BaseURL, in.BaseURL), "/")
+		pc.APIKeyEnv = mergeString(pc.APIKeyEnv, in.APIKeyEnv)
+		pc.APIKeyRef = mergeString(pc.APIKeyRef, in.APIKeyRef)
+		pc.APIVersion = mergeString(pc.APIVersion, in.APIVersion)
+		pc.ProxyURL = mergeString(pc.ProxyURL, in.ProxyURL)
+		pc.Project = mergeString(pc.Project, in.Project)
+		pc.Region = mergeString(pc.Region, in.Region)
+		pc.TokenCommand = mergeString(pc.TokenCommand, in.TokenCommand)
+		if in.MaxTokens > 0 {
+			pc.MaxTokens = in.MaxTokens
+		}
+		if in.Timeout > 0 {
+			pc.Timeout = in.Timeout
+		}
+		if len(in.Headers) > 0 {
+			if pc.Headers == nil {
+				pc.Headers = map[string]string{}
+			}
+			for key, value := range in.Headers {
+				pc.Headers[key] = value
+			}
+		}
+		if len(in.ExtraBody) > 0 {
+			if pc.ExtraBody == nil {
+				pc.ExtraBody = map[string]any{}
+			}
+			for key, value := range in.ExtraBody {
+				pc.ExtraBody[key] = value
+			}
+		}
+		c.Providers[name] = pc
+	}
+	for name, in := range incoming.CustomProviders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		current, exists := c.CustomProviders[name]
+		if !exists {
+			if isMaskedSecret(in.APIKey) {
+				in.APIKey = ""
+			}
+			if isMaskedSecret(in.BasicPassword) {
+				in.BasicPassword = ""
+			}
+			if err := c.AddCustomProvider(name, in); err != nil {
+				return fmt.Errorf("import custom provider %q: %w", name, err)
+			}
+			continue
+		}
+		current.BaseURL = strings.TrimRight(mergeString(current.BaseURL, in.BaseURL), "/")
+		current.APIKey = mergeSecret(current.APIKey, in.APIKey)
+		current.Model = mergeString(current.Model, in.Model)
+		current.APIKeyEnv = mergeString(current.APIKeyEnv, in.APIKeyEnv)
+		current.APIKeyRef = mergeString(current.APIKeyRef, in.APIKeyRef)
+		current.ModelsPath = mergeString(current.ModelsPath, in.ModelsPath)
+		current.ChatPath = mergeString(current.ChatPath, in.ChatPath)
+		current.ModelsResponseKey = mergeString(current.ModelsResponseKey, in.ModelsResponseKey)
+		current.ModelIDField = mergeString(current.ModelIDField, in.ModelIDField)
+		current.AuthHeader = mergeString(current.AuthHeader, in.AuthHeader)
+		current.AuthQueryParam = mergeString(current.AuthQueryParam, in.AuthQueryParam)
+		current.BasicUser = mergeString(current.BasicUser, in.BasicUser)
+		current.BasicPassword = mergeSecret(current.BasicPassword, in.BasicPassword)
+		current.BasicPasswordEnv = mergeString(current.BasicPasswordEnv, in.BasicPasswordEnv)
+		current.ProxyURL = mergeString(current.ProxyURL, in.ProxyURL)
+		if in.AuthPrefix != "" {
+			current.AuthPrefix = in.AuthPrefix
+		}
+		if in.MaxTokens > 0 {
+			current.MaxTokens = in.MaxTokens
+		}
+		if in.Timeout > 0 {
+			current.Timeout = in.Timeout
+		}
+		if len(in.Headers) > 0 {
+			if current.Headers == nil {
+				current.Headers = map[string]string{}
+			}
+			for key, value := range in.Headers {
+				current.Headers[key] = value
+			}
+		}
+		if len(in.ExtraBody) > 0 {
+			if current.ExtraBody == nil {
+				current.ExtraBody = map[string]any{}
+			}
+			for key, value := range in.ExtraBody {
+				current.ExtraBody[key] = value
+			}
+		}
+		c.CustomProviders[name] = current
+	}
+
+	if incoming.CurrentProvider != "" {
+		c.CurrentProvider = incoming.CurrentProvider
+	}
+	if incoming.OllamaHost != "" {
+		c.OllamaHost = incoming.OllamaHost
+	}
+	c.OpenAIOrg = mergeString(c.OpenAIOrg, incoming.OpenAIOrg)
+	c.OpenAIProject = mergeString(c.OpenAIProject, incoming.OpenAIProject)
+	c.UserAgent = mergeString(c.UserAgent, incoming.UserAgent)
+	if incoming.Retries != 0 {
+		c.Retries = incoming.Retries
+	}
+	if incoming.ModelCacheTTL != 0 {
+		c.ModelCacheTTL = incoming.ModelCacheTTL
+	}
+	if incoming.AnswerCacheTTL != 0 {
+		c.AnswerCacheTTL = incoming.AnswerCacheTTL
+	}
+	if incoming.Defaults != nil && incoming.Defaults.TimeoutSeconds != 0 {
+		if c.Defaults == nil {
+			c.Defaults = &Defaults{}
+		}
+		c.Defaults.TimeoutSeconds = incoming.Defaults.TimeoutSeconds
+	}
+	if len(incoming.Fallbacks) > 0 {
+		c.Fallbacks = incoming.Fallbacks
+	}
+	for provider, aliases := range incoming.ModelAliases {
+		for alias, model := range aliases {
+			if err := c.SetModelAlias(provider, alias, model); err != nil {
+				return fmt.Errorf("import model alias %q: %w", alias, err)
+			}
+		}
+	}
+	for name, template := range incoming.Prompts {
+		if err := c.SetPrompt(name, template); err != nil {
+			return fmt.Errorf("import prompt preset %q: %w", name, err)
+		}
+	}
+	for name, expansion := range incoming.Aliases {
+		if err := c.SetAlias(name, expansion); err != nil {
+			return fmt.Errorf("import alias %q: %w", name, err)
+		}
+	}
+	c.RenderMarkdown = incoming.RenderMarkdown
+	c.StrictJSON = c.StrictJSON || incoming.StrictJSON
+	c.GitContext = c.GitContext || incoming.GitContext
+	c.AllowCodeFences = c.AllowCodeFences || incoming.AllowCodeFences
+	c.HighlightCmds = c.HighlightCmds || incoming.HighlightCmds
+	c.MarkdownTheme = mergeString(c.MarkdownTheme, incoming.MarkdownTheme)
+	c.Shell = mergeString(c.Shell, incoming.Shell)
+	c.SystemPrompt = mergeString(c.SystemPrompt, incoming.SystemPrompt)
+	c.normalize()
+	return nil
+}
+
+func mergeString(current, incoming string) string {
+	if v := strings.TrimSpace(incoming); v != "" {
+		return v
+	}
+	return current
+}
+
+// mergeSecret keeps an existing secret and only fills an empty one with a
+// real (non-masked) incoming value.
+func mergeSecret(current, incoming string) string {
+	if strings.TrimSpace(current) != "" {
+		return current
+	}
+	incoming = strings.TrimSpace(incoming)
+	if isMaskedSecret(incoming) {
+		return current
+	}
+	return incoming
+}
+
+// isMaskedSecret reports whether v looks like a value produced by masking,
+// i.e. it starts with asterisks.
+func isMaskedSecret(v string) bool {
+	return strings.HasPrefix(strings.TrimSpace(v), "***")
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate reports configuration problems: custom providers without a
+// base_url, provider references (including model alias scopes) that do not
+// resolve, duplicate base URLs, and API key env vars that are unset for
+// providers in use. It returns nil when no problems are found.
+func (c *Config) Validate() []error {
+	c.normalize()
+	var problems []error
+
+	for _, name := range sortedKeys(c.Providers) {
+		if !IsBuiltinProvider(name) {
+			problems = append(problems, fmt.Errorf("providers.%s: not a built-in provider; define it under custom_providers", name))
+		}
+	}
+	for _, name := range sortedKeys(c.CustomProviders) {
+		if IsBuiltinProvider(name) {
+			problems = append(problems, fmt.Errorf("custom_providers.%s: name is reserved for a built-in provider", name))
+		}
+		if strings.TrimSpace(c.CustomProviders[name].BaseURL) == "" {
+			problems = append(problems, fmt.Errorf("custom_providers.%s: base_url is required", name))
+		}
+	}
+
+	if c.CurrentProvider != "" && !c.ProviderExists(c.CurrentProvider) {
+		problems = append(problems, fmt.Errorf("current_provider: provider %q does not exist", c.CurrentProvider))
+	}
+	for _, name := range c.Fallbacks {
+		if !c.ProviderExists(name) {
+			problems = append(problems, fmt.Errorf("fallback_providers: provider %q does not exist", name))
+		}
+	}
+
+	for _, name := range sortedKeys(c.ModelAliases) {
+		if !c.ProviderExists(name) {
+			problems = append(problems, fmt.Errorf("model_aliases.%s: provider does not exist", name))
+		}
+	}
+
+	for _, name := range sortedKeys(c.Aliases) {
+		if IsCommandName(name) {
+			problems = append(problems, fmt.Errorf("aliases.%s: shadows the %s command and is ignored", name, name))
+		}
+	}
+
+	seen := map[string]string{}
+	for _, name := range c.ProviderNames() {
+		base := normalizedBaseURL(c.configuredBaseURL(name))
+		if base == "" {
+			continue
+		}
+		if other, ok := seen[base]; ok {
+			problems = append(problems, fmt.Errorf("%s and %s use the same base_url %s", other, name, base))
+			continue
+		}
+		seen[base] = name
+	}
+
+	inUse := map[string]bool{}
+	if c.CurrentProvider != "" {
+		inUse[c.CurrentProvider] = true
+	}
+	for _, name := range c.Fallbacks {
+		inUse[name] = true
+	}
+	for name := range c.CustomProviders {
+		inUse[name] = true
+	}
+	for _, name := range sortedKeys(inUse) {
+		if !c.ProviderExists(name) {
+			continue
+		}
+		env, stored := c.keySources(name)
+		if env != "" && strings.TrimSpace(os.Getenv(env)) == "" && !stored {
+			problems = append(problems, fmt.Errorf("%s: api_key_env %s is not set and no api_key is stored", name, env))
+		}
+	}
+	return problems
+}
+
+// BaseURLConflicts returns the other providers, built-in or custom, whose
+// resolved base URL matches provider's, ignoring case and trailing slashes.
+func (c *Config) BaseURLConflicts(provider string) []string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	base := normalizedBaseURL(c.ResolveBaseURL(provider))
+	if base == "" {
+		return nil
+	}
+	var conflicts []string
+	for _, name := range c.ProviderNames() {
+		if name != provider && normalizedBaseURL(c.ResolveBaseURL(name)) == base {
+			conflicts = append(conflicts, name)
+		}
+	}
+	return conflicts
+}
+
+func normalizedBaseURL(u string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(u), "/"))
+}
+
+// configuredBaseURL returns the base URL explicitly set in config for
+// provider, ignoring built-in defaults.
+func (c *Config) configuredBaseURL(provider string) string {
+	if custom, ok := c.CustomProviders[provider]; ok {
+		return strings.TrimSpace(custom.BaseURL)
+	}
+	if provider == "ollama" && c.OllamaHost != "" {
+		return c.OllamaHost
+	}
+	return strings.TrimSpace(c.Providers[provider].BaseURL)
+}
+
+// keySources returns the API key env var configured for provider and whether
+// a key is stored in config or the keychain.
+func (c *Config) keySources(provider string) (string, bool) {
+	if custom, ok := c.CustomProviders[provider]; ok {
+		return strings.TrimSpace(custom.APIKeyEnv), strings.TrimSpace(custom.APIKey) != "" || strings.TrimSpace(custom.APIKeyRef) != ""
+	}
+	pc := c.Providers[provider]
+	return strings.TrimSpace(pc.APIKeyEnv), strings.TrimSpace(pc.APIKey) != "" || strings.TrimSpace(pc.APIKeyRef) != ""
+}
+
+// UnknownKeys reports JSON object keys in a config document that do not map
+// to a config field, including keys inside provider entries.
+func UnknownKeys(buf []byte) ([]error, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &root); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+
+	problems := unknownFields("", root, reflect.TypeOf(Config{}))
+	nested := []struct {
+		key string
+		typ reflect.Type
+	}{
+		{"providers", reflect.TypeOf(ProviderConfig{})},
+		{"custom_providers", reflect.TypeOf(OpenAICompatibleProvider{})},
+	}
+	var defaults map[string]json.RawMessage
+	if json.Unmarshal(root["defaults"], &defaults) == nil {
+		problems = append(problems, unknownFields("defaults.", defaults, reflect.TypeOf(Defaults{}))...)
+	}
+	for _, section := range nested {
+		var entries map[string]map[string]json.RawMessage
+		if json.Unmarshal(root[section.key], &entries) != nil {
+			continue
+		}
+		for _, name := range sortedKeys(entries) {
+			problems = append(problems, unknownFields(section.key+"."+name+".", entries[name], section.typ)...)
+		}
+	}
+	return problems, nil
+}
+
+func unknownFields(prefix string, obj map[string]json.RawMessage, typ reflect.Type) []error {
+	known := map[string]bool{}
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known[name] = true
+		}
+	}
+	var problems []error
+	for _, key := range sortedKeys(obj) {
+		if !known[key] {
+			problems = append(problems, fmt.Errorf("%s%s: unknown key", prefix, key))
+		}
+	}
+	return problems
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,3 @@
+// Package history persists conversation transcripts under the ask config
+// directory.
+package history
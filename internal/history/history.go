@@ -0,0 +1,206 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	dirName       = "history"
+	fileExtension = ".json"
+
+	// OneShotID names the transcript that collects one-shot asks, so they
+	// never grow a chat session's transcript.
+	OneShotID = "asks"
+	// MaxOneShotExchanges caps the one-shot transcript; the oldest
+	// question/answer pairs are dropped first.
+	MaxOneShotExchanges = 200
+)
+
+// ErrNotFound indicates the requested transcript does not exist.
+var ErrNotFound = errors.New("transcript not found")
+
+// Message is a single recorded conversation turn.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Transcript is a persisted conversation with provider metadata.
+type Transcript struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	Messages  []Message `json:"messages"`
+}
+
+// DirForConfig returns the history directory next to the given config path.
+func DirForConfig(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), dirName)
+}
+
+// New returns an empty transcript with a timestamp-based ID.
+func New(provider, model string) *Transcript {
+	now := time.Now().UTC()
+	return &Transcript{
+		ID:        fmt.Sprintf("%s-%06d", now.Format("20060102-150405"), now.Nanosecond()/1000),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Provider:  provider,
+		Model:     model,
+	}
+}
+
+// Append adds messages to the transcript and refreshes UpdatedAt.
+func (t *Transcript) Append(messages ...Message) {
+	t.Messages = append(t.Messages, messages...)
+	t.UpdatedAt = time.Now().UTC()
+}
+
+// AppendOneShot appends a question and answer to the one-shot transcript in
+// dir, creating it on first use and trimming it to MaxOneShotExchanges. Only
+// that transcript is read, and its provider and model stay as created.
+func AppendOneShot(dir, provider, model, question, answer string) error {
+	t, err := Load(dir, OneShotID)
+	if errors.Is(err, ErrNotFound) {
+		t, err = New(provider, model), nil
+		t.ID = OneShotID
+	}
+	if err != nil {
+		return err
+	}
+	t.Append(Message{Role: "user", Content: question}, Message{Role: "assistant", Content: answer})
+	if excess := len(t.Messages) - 2*MaxOneShotExchanges; excess > 0 {
+		t.Messages = append([]Message(nil), t.Messages[excess:]...)
+	}
+	return Save(dir, t)
+}
+
+// Save writes transcript to dir using owner-only permissions.
+func Save(dir string, t *Transcript) error {
+	if t == nil || strings.TrimSpace(t.ID) == "" {
+		return fmt.Errorf("transcript id is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return fmt.Errorf("set history directory permissions: %w", err)
+	}
+
+	encoded, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode transcript: %w", err)
+	}
+
+	path := filepath.Join(dir, t.ID+fileExtension)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
+		return fmt.Errorf("write temp transcript: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace transcript: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("set transcript permissions: %w", err)
+	}
+	return nil
+}
+
+// Load reads the transcript with id from dir.
+func Load(dir, id string) (*Transcript, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid transcript id %q", id)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, id+fileExtension))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+	var t Transcript
+	if err := json.Unmarshal(buf, &t); err != nil {
+		return nil, fmt.Errorf("decode transcript %s: %w", id, err)
+	}
+	if t.ID == "" {
+		t.ID = id
+	}
+	return &t, nil
+}
+
+// List returns all transcripts in dir, most recently updated first.
+// A missing directory yields an empty list.
+func List(dir string) ([]*Transcript, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history directory: %w", err)
+	}
+
+	transcripts := make([]*Transcript, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fileExtension) {
+			continue
+		}
+		t, err := Load(dir, strings.TrimSuffix(name, fileExtension))
+		if err != nil {
+			continue
+		}
+		transcripts = append(transcripts, t)
+	}
+	sort.Slice(transcripts, func(i, j int) bool {
+		if transcripts[i].UpdatedAt.Equal(transcripts[j].UpdatedAt) {
+			return transcripts[i].ID > transcripts[j].ID
+		}
+		return transcripts[i].UpdatedAt.After(transcripts[j].UpdatedAt)
+	})
+	return transcripts, nil
+}
+
+// Latest returns the most recently updated transcript, or nil when none exist.
+func Latest(dir string) (*Transcript, error) {
+	transcripts, err := List(dir)
+	if err != nil || len(transcripts) == 0 {
+		return nil, err
+	}
+	return transcripts[0], nil
+}
+
+// Clear removes all transcripts from dir and returns how many were deleted.
+func Clear(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read history directory: %w", err)
+	}
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("remove transcript: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	tr := New("openai", "gpt-5-nano")
+	tr.Append(Message{Role: "user", Content: "hi"}, Message{Role: "assistant", Content: "hello"})
+
+	if err := Save(dir, tr); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(dir, tr.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Provider != "openai" || loaded.Model != "gpt-5-nano" || len(loaded.Messages) != 2 {
+		t.Fatalf("unexpected transcript: %+v", loaded)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, tr.ID+".json"))
+		if err != nil {
+			t.Fatalf("stat transcript: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Fatalf("mode = %o, want 600", info.Mode().Perm())
+		}
+	}
+}
+
+func TestListOrdersByUpdatedAndClear(t *testing.T) {
+	dir := t.TempDir()
+	older := New("openai", "a")
+	older.ID = "older"
+	older.UpdatedAt = time.Now().Add(-time.Hour)
+	newer := New("ollama", "b")
+	newer.ID = "newer"
+
+	for _, tr := range []*Transcript{older, newer} {
+		if err := Save(dir, tr); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	latest, err := Latest(dir)
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if latest == nil || latest.ID != "newer" {
+		t.Fatalf("Latest() = %+v, want newer", latest)
+	}
+
+	removed, err := Clear(dir)
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("Clear() removed %d, want 2", removed)
+	}
+	list, err := List(dir)
+	if err != nil || len(list) != 0 {
+		t.Fatalf("List() after clear = %v, %v", list, err)
+	}
+}
+
+func TestAppendOneShotCapsTranscript(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < MaxOneShotExchanges+3; i++ {
+		provider := "openai"
+		if i > 0 {
+			provider = "anthropic"
+		}
+		if err := AppendOneShot(dir, provider, "m", fmt.Sprintf("q%d", i), "a"); err != nil {
+			t.Fatalf("AppendOneShot() error = %v", err)
+		}
+	}
+	tr, err := Load(dir, OneShotID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(tr.Messages) != 2*MaxOneShotExchanges || tr.Messages[0].Content != "q3" {
+		t.Fatalf("messages = %d, first = %q; want the oldest asks dropped", len(tr.Messages), tr.Messages[0].Content)
+	}
+	if tr.Provider != "openai" {
+		t.Fatalf("provider = %q; appending must not rewrite transcript metadata", tr.Provider)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, err := Load(t.TempDir(), "nope"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLastCommandRoundTrip(t *testing.T) {
+	path := LastCommandPath(filepath.Join(t.TempDir(), "config.json"))
+	if _, err := LoadLastCommand(path); !errors.Is(err, ErrNoLastCommand) {
+		t.Fatalf("LoadLastCommand() error = %v, want ErrNoLastCommand", err)
+	}
+	if err := SaveLastCommand(path, LastCommand{Question: "list files", Command: "ls -la"}); err != nil {
+		t.Fatalf("SaveLastCommand() error = %v", err)
+	}
+	if err := SaveLastCommand(path, LastCommand{Question: "disk usage", Command: "du -sh ."}); err != nil {
+		t.Fatalf("SaveLastCommand() error = %v", err)
+	}
+	last, err := LoadLastCommand(path)
+	if err != nil {
+		t.Fatalf("LoadLastCommand() error = %v", err)
+	}
+	if last.Command != "du -sh ." || last.Question != "disk usage" || last.CreatedAt.IsZero() {
+		t.Fatalf("last = %+v", last)
+	}
+}
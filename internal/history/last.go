@@ -0,0 +1,112 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const lastCommandFile = "last_command.json"
+
+var (
+	// ErrNoLastCommand indicates no command has been recorded yet.
+	ErrNoLastCommand = errors.New("no command recorded yet")
+	// ErrNoLastQuestion indicates no question has been recorded yet.
+	ErrNoLastQuestion = errors.New("no question recorded yet")
+)
+
+// LastCommand records the most recent one-shot question and the most recent
+// command returned. Command survives later answers that return no command,
+// so CommandQuestion names the question that produced it.
+type LastCommand struct {
+	Question        string    `json:"question"`
+	Command         string    `json:"command,omitempty"`
+	CommandQuestion string    `json:"command_question,omitempty"`
+	Provider        string    `json:"provider,omitempty"`
+	Model           string    `json:"model,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// LastCommandPath returns the last-command state file next to the given
+// config path.
+func LastCommandPath(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), lastCommandFile)
+}
+
+// SaveLastCommand replaces the state file at path with last, using
+// owner-only permissions.
+func SaveLastCommand(path string, last LastCommand) error {
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("last command path is required")
+	}
+	if last.CreatedAt.IsZero() {
+		last.CreatedAt = time.Now().UTC()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	encoded, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode last command: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
+		return fmt.Errorf("write temp last command: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace last command: %w", err)
+	}
+	return nil
+}
+
+// LoadLastCommand reads the state file at path. It returns ErrNoLastCommand
+// when no command has been recorded.
+func LoadLastCommand(path string) (LastCommand, error) {
+	last, err := loadLast(path)
+	if err == nil && strings.TrimSpace(last.Command) == "" {
+		err = ErrNoLastCommand
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrNoLastCommand
+	}
+	if err == nil && last.CommandQuestion == "" {
+		last.CommandQuestion = last.Question
+	}
+	return last, err
+}
+
+// LoadLastQuestion reads the state file at path. It returns ErrNoLastQuestion
+// when no question has been recorded.
+func LoadLastQuestion(path string) (LastCommand, error) {
+	last, err := loadLast(path)
+	if err == nil && strings.TrimSpace(last.Question) == "" {
+		err = ErrNoLastQuestion
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrNoLastQuestion
+	}
+	return last, err
+}
+
+func loadLast(path string) (LastCommand, error) {
+	var last LastCommand
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return last, err
+		}
+		return last, fmt.Errorf("read last command: %w", err)
+	}
+	if err := json.Unmarshal(buf, &last); err != nil {
+		return last, fmt.Errorf("decode last command: %w", err)
+	}
+	return last, nil
+}
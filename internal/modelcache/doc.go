@@ -0,0 +1,3 @@
+// Package modelcache stores provider model lists under the ask config
+// directory so repeated listings avoid the network.
+package modelcache
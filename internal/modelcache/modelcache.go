@@ -0,0 +1,127 @@
+package modelcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+const (
+	dirName          = "models"
+	parentDirName    = "cache"
+	fileExtension    = ".json"
+	capabilitiesFile = "capabilities.json"
+)
+
+// DefaultTTL is how long a cached model list stays fresh when no TTL is
+// configured.
+const DefaultTTL = time.Hour
+
+type entry struct {
+	Provider  string            `json:"provider"`
+	BaseURL   string            `json:"base_url,omitempty"`
+	FetchedAt time.Time         `json:"fetched_at"`
+	Models    []providers.Model `json:"models"`
+}
+
+// DirForConfig returns the model cache directory next to the given config path.
+func DirForConfig(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), parentDirName, dirName)
+}
+
+// CapabilitiesPath returns the file next to the model cache where provider
+// clients remember which response formats each model rejected.
+func CapabilitiesPath(configPath string) string {
+	path := strings.TrimSpace(configPath)
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), parentDirName, capabilitiesFile)
+}
+
+// Load returns the cached models for provider when they were fetched from
+// baseURL within ttl. The boolean is false when the cache is missing, stale,
+// unreadable, or was filled from a different endpoint.
+func Load(dir, provider, baseURL string, ttl time.Duration) ([]providers.Model, bool) {
+	path, err := entryPath(dir, provider)
+	if err != nil || ttl <= 0 {
+		return nil, false
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return nil, false
+	}
+	if e.BaseURL != baseURL || e.FetchedAt.IsZero() || time.Since(e.FetchedAt) > ttl {
+		return nil, false
+	}
+	return e.Models, true
+}
+
+// Save writes models for provider, as listed by baseURL, to dir using
+// owner-only permissions.
+func Save(dir, provider, baseURL string, models []providers.Model) error {
+	path, err := entryPath(dir, provider)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create model cache directory: %w", err)
+	}
+
+	encoded, err := json.MarshalIndent(entry{
+		Provider:  provider,
+		BaseURL:   baseURL,
+		FetchedAt: time.Now().UTC(),
+		Models:    models,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode model cache: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
+		return fmt.Errorf("write temp model cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace model cache: %w", err)
+	}
+	return nil
+}
+
+// Invalidate removes the cached models for provider. A missing cache entry is
+// not an error.
+func Invalidate(dir, provider string) error {
+	path, err := entryPath(dir, provider)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove model cache: %w", err)
+	}
+	return nil
+}
+
+func entryPath(dir, provider string) (string, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("model cache directory is not set")
+	}
+	if provider == "" || strings.ContainsAny(provider, `/\`) || provider == "." || provider == ".." {
+		return "", fmt.Errorf("invalid provider name %q", provider)
+	}
+	return filepath.Join(dir, provider+fileExtension), nil
+}
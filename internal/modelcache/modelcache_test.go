@@ -0,0 +1,84 @@
+package modelcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sasanktumpati/ask/internal/providers"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache", "models")
+	models := []providers.Model{{ID: "a", DisplayName: "A", ContextLength: 1000}}
+	if err := Save(dir, "openrouter", "https://example.test", models); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+
+	got, ok := Load(dir, "openrouter", "https://example.test", time.Hour)
+	if !ok {
+		t.Fatalf("Load returned no cache")
+	}
+	if len(got) != 1 || got[0] != models[0] {
+		t.Fatalf("Load = %+v, want %+v", got, models)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "openrouter.json"))
+	if err != nil {
+		t.Fatalf("stat cache file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("cache file perm = %o, want 600", perm)
+	}
+}
+
+func TestLoadIgnoresStaleEntries(t *testing.T) {
+	dir := t.TempDir()
+	stale := `{"provider":"openai","base_url":"https://example.test","fetched_at":"2020-01-02T03:04:05Z","models":[{"ID":"m"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "openai.json"), []byte(stale), 0o600); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+	if _, ok := Load(dir, "openai", "https://example.test", time.Hour); ok {
+		t.Fatalf("stale cache should not load")
+	}
+
+	if err := Save(dir, "openai", "https://example.test", []providers.Model{{ID: "m"}}); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	if _, ok := Load(dir, "openai", "https://example.test", 0); ok {
+		t.Fatalf("zero ttl should disable cache")
+	}
+}
+
+func TestLoadIgnoresOtherBaseURL(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, "ollama", "http://localhost:11434", []providers.Model{{ID: "llama3"}}); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	if _, ok := Load(dir, "ollama", "http://gpu-box:11434", time.Hour); ok {
+		t.Fatalf("models listed by another base URL should not load")
+	}
+	if _, ok := Load(dir, "ollama", "http://localhost:11434", time.Hour); !ok {
+		t.Fatalf("models for the same base URL should load")
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	if err := Invalidate(dir, "missing"); err != nil {
+		t.Fatalf("Invalidate missing error = %v", err)
+	}
+	if err := Save(dir, "gemini", "https://example.test", []providers.Model{{ID: "m"}}); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	if err := Invalidate(dir, "gemini"); err != nil {
+		t.Fatalf("Invalidate error = %v", err)
+	}
+	if _, ok := Load(dir, "gemini", "https://example.test", time.Hour); ok {
+		t.Fatalf("cache should be gone after Invalidate")
+	}
+	if err := Save(dir, "../escape", "https://example.test", nil); err == nil {
+		t.Fatalf("expected error for invalid provider name")
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"strings"
 )
 
+const defaultAnthropicMaxTokens = 2048
+
 type anthropicClient struct {
-	apiKey  string
-	base    string
-	http    *http.Client
-	headers map[string]string
+	apiKey    string
+	base      string
+	http      *requester
+	headers   map[string]string
+	extraBody map[string]any
 }
 
 func newAnthropicClient(opts ClientOptions) Client {
@@ -25,10 +28,11 @@ func newAnthropicClient(opts ClientOptions) Client {
 		headers[k] = v
 	}
 	return &anthropicClient{
-		apiKey:  strings.TrimSpace(opts.APIKey),
-		base:    strings.TrimRight(strings.TrimSpace(base), "/"),
-		http:    defaultHTTPClient(opts.HTTPClient),
-		headers: headers,
+		apiKey:    strings.TrimSpace(opts.APIKey),
+		base:      strings.TrimRight(strings.TrimSpace(base), "/"),
+		http:      newRequester(opts),
+		headers:   headers,
+		extraBody: opts.ExtraBody,
 	}
 }
 
@@ -38,33 +42,55 @@ func (c *anthropicClient) ListModels(ctx context.Context) ([]Model, error) {
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("ANTHROPIC_API_KEY not configured")
 	}
-	req, err := http.NewRequest(http.MethodGet, joinURL(c.base, "/v1/models"), nil)
-	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
-	}
-	c.setHeaders(req)
+	endpoint := joinURL(c.base, "/v1/models")
 
-	var resp struct {
-		Data []struct {
-			ID          string `json:"id"`
-			DisplayName string `json:"display_name"`
-		} `json:"data"`
-	}
-	if err := doJSON(ctx, c.http, req, nil, &resp); err != nil {
-		return nil, err
-	}
+	var models []Model
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 0; page < maxModelPages; page++ {
+		pageURL := endpoint
+		if cursor != "" {
+			var err error
+			if pageURL, err = withQuery(endpoint, "after_id", cursor); err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		c.setHeaders(req)
 
-	models := make([]Model, 0, len(resp.Data))
-	for _, m := range resp.Data {
-		id := strings.TrimSpace(m.ID)
-		if id == "" {
-			continue
+		var resp struct {
+			Data []struct {
+				ID          string `json:"id"`
+				DisplayName string `json:"display_name"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := doJSON(ctx, c.http, req, nil, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, m := range resp.Data {
+			id := strings.TrimSpace(m.ID)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			name := strings.TrimSpace(m.DisplayName)
+			if name == "" {
+				name = id
+			}
+			models = append(models, Model{ID: id, DisplayName: name})
 		}
-		name := strings.TrimSpace(m.DisplayName)
-		if name == "" {
-			name = id
+
+		next := strings.TrimSpace(resp.LastID)
+		if !resp.HasMore || next == "" || next == cursor {
+			break
 		}
-		models = append(models, Model{ID: id, DisplayName: name})
+		cursor = next
 	}
 	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
 	return models, nil
@@ -74,6 +100,9 @@ func (c *anthropicClient) Ask(ctx context.Context, reqBody AskRequest) (AskRespo
 	if err := validateAskRequest(reqBody); err != nil {
 		return AskResponse{}, err
 	}
+	if err := rejectImages("anthropic", reqBody); err != nil {
+		return AskResponse{}, err
+	}
 	if c.apiKey == "" {
 		return AskResponse{}, fmt.Errorf("ANTHROPIC_API_KEY not configured")
 	}
@@ -83,28 +112,60 @@ func (c *anthropicClient) Ask(ctx context.Context, reqBody AskRequest) (AskRespo
 	}
 	c.setHeaders(req)
 
-	payload := map[string]any{
-		"model":      reqBody.Model,
-		"max_tokens": 2048,
-		"system":     reqBody.Prompt,
-		"messages": []map[string]any{
-			{
-				"role":    "user",
-				"content": []map[string]string{{"type": "text", "text": reqBody.Question}},
-			},
-		},
-	}
+	payload := anthropicPayload(reqBody)
+	payload["model"] = reqBody.Model
+	mergeParams(payload, c.extraBody)
+	mergeParams(payload, reqBody.Extra)
 
-	var resp struct {
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content"`
-	}
+	var resp anthropicResponse
 	if err := doJSON(ctx, c.http, req, payload, &resp); err != nil {
 		return AskResponse{}, err
 	}
+	return resp.askResponse()
+}
+
+// anthropicPayload builds a Messages API payload for reqBody without the
+// model, which Bedrock carries in the URL instead.
+func anthropicPayload(reqBody AskRequest) map[string]any {
+	conversation := conversationMessages(reqBody)
+	messages := make([]map[string]any, 0, len(conversation))
+	for _, m := range conversation {
+		messages = append(messages, map[string]any{
+			"role":    m.Role,
+			"content": []map[string]string{{"type": "text", "text": m.Content}},
+		})
+	}
+
+	maxTokens := reqBody.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+
+	payload := map[string]any{
+		"max_tokens": maxTokens,
+		"system":     reqBody.Prompt,
+		"messages":   messages,
+	}
+	if reqBody.Temperature != nil {
+		payload["temperature"] = *reqBody.Temperature
+	}
+	return payload
+}
+
+// anthropicResponse is the Messages API response body.
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
 
+func (resp anthropicResponse) askResponse() (AskResponse, error) {
 	parts := make([]string, 0, len(resp.Content))
 	for _, block := range resp.Content {
 		if block.Type == "text" && strings.TrimSpace(block.Text) != "" {
@@ -114,7 +175,10 @@ func (c *anthropicClient) Ask(ctx context.Context, reqBody AskRequest) (AskRespo
 	if len(parts) == 0 {
 		return AskResponse{}, fmt.Errorf("no text content returned by Anthropic")
 	}
-	return AskResponse{Text: strings.Join(parts, "\n")}, nil
+	return AskResponse{Text: strings.Join(parts, "\n"), Usage: Usage{
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+	}.withTotal(), FinishReason: resp.StopReason, Truncated: resp.StopReason == "max_tokens"}, nil
 }
 
 func (c *anthropicClient) setHeaders(req *http.Request) {
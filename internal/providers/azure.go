@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const defaultAzureAPIVersion = "2024-06-01"
+
+// azureClient talks to Azure OpenAI, where the model is a deployment name
+// embedded in the request path and auth uses the api-key header.
+type azureClient struct {
+	inner      *openAICompatibleClient
+	apiVersion string
+}
+
+func newAzureClient(opts ClientOptions) Client {
+	apiVersion := strings.TrimSpace(opts.APIVersion)
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+	inner := newOpenAICompatibleClient(OpenAICompatibleSettings{
+		Name:       "azure",
+		AuthHeader: "api-key",
+	}, opts)
+	inner.authPrefix = ""
+	return &azureClient{inner: inner, apiVersion: apiVersion}
+}
+
+func (c *azureClient) Name() string { return "azure" }
+
+func (c *azureClient) ListModels(ctx context.Context) ([]Model, error) {
+	return nil, fmt.Errorf("azure: %w; set a deployment with `ask models set <deployment> --provider azure`", ErrListUnsupported)
+}
+
+func (c *azureClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse, error) {
+	if err := validateAskRequest(reqBody); err != nil {
+		return AskResponse{}, err
+	}
+	if c.inner.base == "" {
+		return AskResponse{}, fmt.Errorf("base URL not configured for azure; set providers.azure.base_url to https://<resource>.openai.azure.com")
+	}
+	if c.inner.apiKey == "" {
+		return AskResponse{}, fmt.Errorf("AZURE_OPENAI_API_KEY not configured")
+	}
+	return c.inner.askURL(ctx, c.chatURL(reqBody.Model), reqBody)
+}
+
+func (c *azureClient) chatURL(deployment string) string {
+	path := fmt.Sprintf("/openai/deployments/%s/chat/completions", url.PathEscape(strings.TrimSpace(deployment)))
+	return joinURL(c.inner.base, path) + "?api-version=" + url.QueryEscape(c.apiVersion)
+}
@@ -0,0 +1,363 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bedrockSigningName is the SigV4 service name for both the Bedrock control
+// plane and bedrock-runtime.
+const bedrockSigningName = "bedrock"
+
+// bedrockClient invokes models on AWS Bedrock. Requests are signed with SigV4
+// using credentials from the environment or the shared AWS config files, or
+// carry a Bedrock API key as a bearer token when one is configured. The
+// payload shape follows the model family: Anthropic models use the Messages
+// API and Amazon Titan models use Titan's text generation body.
+type bedrockClient struct {
+	apiKey    string
+	base      string
+	region    string
+	http      *requester
+	headers   map[string]string
+	extraBody map[string]any
+	now       func() time.Time
+}
+
+func newBedrockClient(opts ClientOptions) Client {
+	headers := map[string]string{}
+	for k, v := range opts.Headers {
+		headers[k] = v
+	}
+	return &bedrockClient{
+		apiKey:    strings.TrimSpace(opts.APIKey),
+		base:      strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/"),
+		region:    strings.TrimSpace(opts.Region),
+		http:      newRequester(opts),
+		headers:   headers,
+		extraBody: opts.ExtraBody,
+		now:       time.Now,
+	}
+}
+
+func (c *bedrockClient) Name() string { return "bedrock" }
+
+func (c *bedrockClient) ListModels(ctx context.Context) ([]Model, error) {
+	region, creds, err := c.resolveAWS()
+	if err != nil {
+		return nil, err
+	}
+	base := c.base
+	if base == "" {
+		base = fmt.Sprintf("https://bedrock.%s.amazonaws.com", region)
+	}
+	endpoint, err := withQuery(joinURL(base, "/foundation-models"), "byOutputModality", "TEXT")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	c.authorize(req, nil, region, creds)
+
+	var resp struct {
+		ModelSummaries []struct {
+			ModelID   string `json:"modelId"`
+			ModelName string `json:"modelName"`
+		} `json:"modelSummaries"`
+	}
+	if err := doJSON(ctx, c.http, req, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	models := make([]Model, 0, len(resp.ModelSummaries))
+	for _, m := range resp.ModelSummaries {
+		id := strings.TrimSpace(m.ModelID)
+		if id == "" || bedrockFamily(id) == "" {
+			continue
+		}
+		name := strings.TrimSpace(m.ModelName)
+		if name == "" {
+			name = id
+		}
+		models = append(models, Model{ID: id, DisplayName: name})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+func (c *bedrockClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse, error) {
+	if err := validateAskRequest(reqBody); err != nil {
+		return AskResponse{}, err
+	}
+	if err := rejectImages("bedrock", reqBody); err != nil {
+		return AskResponse{}, err
+	}
+	model := strings.TrimSpace(reqBody.Model)
+	family := bedrockFamily(model)
+	if family == "" {
+		return AskResponse{}, fmt.Errorf("unsupported bedrock model %q; only Anthropic (anthropic.*) and Amazon Titan text (amazon.titan-text-*) models are supported", model)
+	}
+	region, creds, err := c.resolveAWS()
+	if err != nil {
+		return AskResponse{}, err
+	}
+
+	var payload map[string]any
+	if family == "anthropic" {
+		payload = anthropicPayload(reqBody)
+		payload["anthropic_version"] = "bedrock-2023-05-31"
+	} else {
+		payload = titanPayload(reqBody)
+	}
+	mergeParams(payload, c.extraBody)
+	mergeParams(payload, reqBody.Extra)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return AskResponse{}, fmt.Errorf("encode request JSON: %w", err)
+	}
+
+	base := c.base
+	if base == "" {
+		base = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+	// Model IDs contain ':' (e.g. anthropic.claude-v2:1), which must reach
+	// AWS percent-encoded for the signature to match.
+	req, err := http.NewRequest(http.MethodPost, joinURL(base, "/model/"+awsURIEncode(model)+"/invoke"), nil)
+	if err != nil {
+		return AskResponse{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.authorize(req, body, region, creds)
+
+	if family == "anthropic" {
+		var resp anthropicResponse
+		if err := doJSON(ctx, c.http, req, json.RawMessage(body), &resp); err != nil {
+			return AskResponse{}, err
+		}
+		return resp.askResponse()
+	}
+
+	var resp struct {
+		InputTextTokenCount int `json:"inputTextTokenCount"`
+		Results             []struct {
+			TokenCount       int    `json:"tokenCount"`
+			OutputText       string `json:"outputText"`
+			CompletionReason string `json:"completionReason"`
+		} `json:"results"`
+	}
+	if err := doJSON(ctx, c.http, req, json.RawMessage(body), &resp); err != nil {
+		return AskResponse{}, err
+	}
+	if len(resp.Results) == 0 || strings.TrimSpace(resp.Results[0].OutputText) == "" {
+		return AskResponse{}, fmt.Errorf("no text returned by Titan")
+	}
+	reason := resp.Results[0].CompletionReason
+	return AskResponse{Text: strings.TrimSpace(resp.Results[0].OutputText), Usage: Usage{
+		PromptTokens:     resp.InputTextTokenCount,
+		CompletionTokens: resp.Results[0].TokenCount,
+	}.withTotal(), FinishReason: reason, Truncated: reason == "LENGTH"}, nil
+}
+
+// authorize sets the custom headers and then either the API key or a SigV4
+// signature over body, so the signature covers the final header set.
+func (c *bedrockClient) authorize(req *http.Request, body []byte, region string, creds awsCredentials) {
+	for k, v := range c.headers {
+		if strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		return
+	}
+	signV4(req, body, creds, region, bedrockSigningName, c.now())
+}
+
+// resolveAWS returns the region and, unless an API key is configured, the
+// credentials for a request, reading the shared AWS config files when the
+// environment does not provide them.
+func (c *bedrockClient) resolveAWS() (string, awsCredentials, error) {
+	profile := awsProfile()
+	region := c.region
+	if region == "" {
+		region = awsSharedConfigValue(profile, "region")
+	}
+	if region == "" {
+		return "", awsCredentials{}, fmt.Errorf("region not configured for bedrock; set providers.bedrock.region or AWS_REGION")
+	}
+	if c.apiKey != "" {
+		return region, awsCredentials{}, nil
+	}
+	creds, err := loadAWSCredentials(profile)
+	if err != nil {
+		return "", awsCredentials{}, err
+	}
+	return region, creds, nil
+}
+
+// bedrockFamily returns the payload family for a Bedrock model ID ("anthropic"
+// or "titan"), or "" when the model is not supported. Cross-region inference
+// profile prefixes such as "us." are ignored.
+func bedrockFamily(model string) string {
+	id := strings.ToLower(model)
+	for _, prefix := range []string{"us.", "eu.", "apac.", "us-gov.", "global."} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	switch {
+	case strings.HasPrefix(id, "anthropic."):
+		return "anthropic"
+	case strings.HasPrefix(id, "amazon.titan-text"):
+		return "titan"
+	default:
+		return ""
+	}
+}
+
+// titanPayload flattens the conversation into Titan's single inputText
+// prompt, using the User:/Bot: turn labels Titan is tuned on.
+func titanPayload(reqBody AskRequest) map[string]any {
+	var b strings.Builder
+	b.WriteString(reqBody.Prompt)
+	for _, m := range conversationMessages(reqBody) {
+		label := "User"
+		if m.Role == RoleAssistant {
+			label = "Bot"
+		}
+		fmt.Fprintf(&b, "\n\n%s: %s", label, m.Content)
+	}
+	b.WriteString("\n\nBot:")
+
+	config := map[string]any{}
+	if reqBody.MaxTokens > 0 {
+		config["maxTokenCount"] = reqBody.MaxTokens
+	}
+	if reqBody.Temperature != nil {
+		config["temperature"] = *reqBody.Temperature
+	}
+	return map[string]any{
+		"inputText":            strings.TrimSpace(b.String()),
+		"textGenerationConfig": config,
+	}
+}
+
+func awsProfile() string {
+	if profile := strings.TrimSpace(os.Getenv("AWS_PROFILE")); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// loadAWSCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN, falling back to profile in the shared credentials file
+// and then the shared config file.
+func loadAWSCredentials(profile string) (awsCredentials, error) {
+	creds := awsCredentials{
+		AccessKeyID:     strings.TrimSpace(os.Getenv("AWS_ACCESS_KEY_ID")),
+		SecretAccessKey: strings.TrimSpace(os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		SessionToken:    strings.TrimSpace(os.Getenv("AWS_SESSION_TOKEN")),
+	}
+	if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+		return creds, nil
+	}
+
+	for _, file := range []struct{ path, section string }{
+		{awsSharedFile("AWS_SHARED_CREDENTIALS_FILE", "credentials"), profile},
+		{awsSharedFile("AWS_CONFIG_FILE", "config"), awsConfigSection(profile)},
+	} {
+		values, err := readINISection(file.path, file.section)
+		if err != nil {
+			return awsCredentials{}, err
+		}
+		if values["aws_access_key_id"] != "" && values["aws_secret_access_key"] != "" {
+			return awsCredentials{
+				AccessKeyID:     values["aws_access_key_id"],
+				SecretAccessKey: values["aws_secret_access_key"],
+				SessionToken:    values["aws_session_token"],
+			}, nil
+		}
+	}
+	return awsCredentials{}, fmt.Errorf("AWS credentials not configured for bedrock; set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, add profile %q to ~/.aws/credentials, or set AWS_BEARER_TOKEN_BEDROCK", profile)
+}
+
+// awsSharedConfigValue returns key from profile in the shared config file, or
+// "" when it is missing or unreadable.
+func awsSharedConfigValue(profile, key string) string {
+	values, err := readINISection(awsSharedFile("AWS_CONFIG_FILE", "config"), awsConfigSection(profile))
+	if err != nil {
+		return ""
+	}
+	return values[key]
+}
+
+// awsConfigSection names profile's section in ~/.aws/config, where profiles
+// other than default are written as [profile name].
+func awsConfigSection(profile string) string {
+	if profile == "default" {
+		return profile
+	}
+	return "profile " + profile
+}
+
+func awsSharedFile(env, name string) string {
+	if path := strings.TrimSpace(os.Getenv(env)); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// readINISection returns the key/value pairs of section in the INI file at
+// path. A missing file yields no values and no error.
+func readINISection(path, section string) (map[string]string, error) {
+	values := map[string]string{}
+	if path == "" {
+		return values, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBedrockAnthropicSignsRequest(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/model/anthropic.claude-3-haiku-20240307-v1%3A0/invoke"; r.URL.EscapedPath() != want {
+			t.Errorf("path = %q, want %q", r.URL.EscapedPath(), want)
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		// Re-sign what the server received; a mismatch means the client
+		// signed different bytes or headers than it sent.
+		verify, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+		verify.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+		signV4(verify, body, creds, "us-west-2", "bedrock", now)
+		if got, want := r.Header.Get("Authorization"), verify.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if payload["anthropic_version"] != "bedrock-2023-05-31" || payload["model"] != nil || payload["system"] != "sys" {
+			t.Errorf("payload = %v", payload)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": []map[string]string{{"type": "text", "text": "hi"}},
+			"usage":   map[string]int{"input_tokens": 3, "output_tokens": 1},
+		})
+	}))
+	defer server.Close()
+
+	client := newBedrockClient(ClientOptions{BaseURL: server.URL, Region: "us-west-2", Retries: -1}).(*bedrockClient)
+	client.now = func() time.Time { return now }
+	resp, err := client.Ask(context.Background(), AskRequest{Model: "anthropic.claude-3-haiku-20240307-v1:0", Prompt: "sys", Question: "q"})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if resp.Text != "hi" || resp.Usage.TotalTokens != 4 {
+		t.Fatalf("resp = %+v", resp)
+	}
+}
+
+func TestBedrockTitanUsesSharedConfig(t *testing.T) {
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	if err := os.WriteFile(credsFile, []byte("[default]\naws_access_key_id = WRONG\n\n[work]\naws_access_key_id = AKIDWORK\naws_secret_access_key = worksecret\naws_session_token = tok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configFile, []byte("[profile work]\nregion = eu-central-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "work")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if want := "Credential=AKIDWORK/"; !strings.Contains(auth, want) || !strings.Contains(auth, "/eu-central-1/bedrock/aws4_request") {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "tok" {
+			t.Errorf("missing session token")
+		}
+		var payload struct {
+			InputText            string         `json:"inputText"`
+			TextGenerationConfig map[string]any `json:"textGenerationConfig"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if payload.InputText != "sys\n\nUser: q\n\nBot:" || payload.TextGenerationConfig["maxTokenCount"] != float64(64) {
+			t.Errorf("payload = %+v", payload)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"inputTextTokenCount": 5,
+			"results":             []map[string]any{{"tokenCount": 2, "outputText": " hello "}},
+		})
+	}))
+	defer server.Close()
+
+	client := newBedrockClient(ClientOptions{BaseURL: server.URL, Retries: -1})
+	resp, err := client.Ask(context.Background(), AskRequest{Model: "amazon.titan-text-express-v1", Prompt: "sys", Question: "q", MaxTokens: 64})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if resp.Text != "hello" || resp.Usage.TotalTokens != 7 {
+		t.Fatalf("resp = %+v", resp)
+	}
+
+	if _, err := client.Ask(context.Background(), AskRequest{Model: "meta.llama3-8b-instruct-v1:0", Prompt: "sys", Question: "q"}); err == nil {
+		t.Fatal("expected unsupported model family error")
+	}
+}
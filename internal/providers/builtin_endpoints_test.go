@@ -3,14 +3,20 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/sasanktumpati/ask/internal/buildinfo"
 )
 
 func TestSupportedProviders(t *testing.T) {
 	got := SupportedProviders()
-	want := []string{"anthropic", "gemini", "ollama", "openai", "openrouter"}
+	want := []string{"anthropic", "azure", "bedrock", "deepseek", "gemini", "groq", "mistral", "ollama", "openai", "openrouter", "vertex"}
 	if len(got) != len(want) {
 		t.Fatalf("SupportedProviders len = %d, want %d (%v)", len(got), len(want), got)
 	}
@@ -95,7 +101,11 @@ func TestOpenRouterEndpoints(t *testing.T) {
 				t.Fatalf("Authorization header = %q", got)
 			}
 			_ = json.NewEncoder(w).Encode(map[string]any{
-				"data": []map[string]any{{"id": "openrouter/model"}},
+				"data": []map[string]any{{
+					"id":             "openrouter/model",
+					"context_length": 128000,
+					"pricing":        map[string]any{"prompt": "0.000003", "completion": "0.000015"},
+				}},
 			})
 		case "/api/v1/chat/completions":
 			if got := r.Header.Get("Authorization"); got != "Bearer sk-or" {
@@ -127,6 +137,9 @@ func TestOpenRouterEndpoints(t *testing.T) {
 	if len(models) != 1 || models[0].ID != "openrouter/model" {
 		t.Fatalf("unexpected models: %+v", models)
 	}
+	if models[0].ContextLength != 128000 || models[0].PromptPrice != 0.000003 || models[0].CompletionPrice != 0.000015 {
+		t.Fatalf("unexpected model metadata: %+v", models[0])
+	}
 
 	if _, err := client.Ask(context.Background(), AskRequest{
 		Model:      "openrouter/model",
@@ -138,6 +151,65 @@ func TestOpenRouterEndpoints(t *testing.T) {
 	}
 }
 
+func TestDeepSeekAndMistralEndpoints(t *testing.T) {
+	for _, tc := range []struct {
+		provider string
+		apiKey   string
+		model    string
+	}{
+		{provider: "deepseek", apiKey: "sk-deepseek", model: "deepseek-chat"},
+		{provider: "mistral", apiKey: "sk-mistral", model: "mistral-small-latest"},
+	} {
+		t.Run(tc.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer "+tc.apiKey {
+					t.Fatalf("Authorization header = %q", got)
+				}
+				switch r.URL.Path {
+				case "/v1/models":
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"data": []map[string]any{{"id": tc.model}},
+					})
+				case "/v1/chat/completions":
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"choices": []map[string]any{{
+							"message": map[string]any{"content": "{\"answer\":\"ok\",\"command\":\"\"}"},
+						}},
+					})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client, err := New(tc.provider, ClientOptions{
+				APIKey:  tc.apiKey,
+				BaseURL: server.URL + "/v1",
+			})
+			if err != nil {
+				t.Fatalf("New(%s) error = %v", tc.provider, err)
+			}
+
+			models, err := client.ListModels(context.Background())
+			if err != nil {
+				t.Fatalf("ListModels error = %v", err)
+			}
+			if len(models) != 1 || models[0].ID != tc.model {
+				t.Fatalf("unexpected models: %+v", models)
+			}
+
+			if _, err := client.Ask(context.Background(), AskRequest{
+				Model:      tc.model,
+				Prompt:     "sys",
+				Question:   "q",
+				ExpectJSON: true,
+			}); err != nil {
+				t.Fatalf("Ask error = %v", err)
+			}
+		})
+	}
+}
+
 func TestAnthropicEndpointsAndHeaders(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -176,6 +248,7 @@ func TestAnthropicEndpointsAndHeaders(t *testing.T) {
 					"type": "text",
 					"text": "{\"answer\":\"ok\",\"command\":\"\"}",
 				}},
+				"usage": map[string]any{"input_tokens": 12, "output_tokens": 5},
 			})
 		default:
 			w.WriteHeader(http.StatusNotFound)
@@ -199,14 +272,18 @@ func TestAnthropicEndpointsAndHeaders(t *testing.T) {
 		t.Fatalf("unexpected models: %+v", models)
 	}
 
-	if _, err := client.Ask(context.Background(), AskRequest{
+	resp, err := client.Ask(context.Background(), AskRequest{
 		Model:      "claude-3-5-sonnet-latest",
 		Prompt:     "system prompt",
 		Question:   "question",
 		ExpectJSON: true,
-	}); err != nil {
+	})
+	if err != nil {
 		t.Fatalf("Ask error = %v", err)
 	}
+	if want := (Usage{PromptTokens: 12, CompletionTokens: 5, TotalTokens: 17}); resp.Usage != want {
+		t.Fatalf("usage = %+v, want %+v", resp.Usage, want)
+	}
 }
 
 func TestGeminiEndpointsAndHeaders(t *testing.T) {
@@ -222,6 +299,8 @@ func TestGeminiEndpointsAndHeaders(t *testing.T) {
 						"name":                       "models/gemini-2.0-flash",
 						"displayName":                "Gemini 2.0 Flash",
 						"supportedGenerationMethods": []string{"generateContent"},
+						"inputTokenLimit":            1048576,
+						"outputTokenLimit":           8192,
 					},
 					{
 						"name":                       "models/text-embedding-004",
@@ -250,6 +329,11 @@ func TestGeminiEndpointsAndHeaders(t *testing.T) {
 						},
 					},
 				}},
+				"usageMetadata": map[string]any{
+					"promptTokenCount":     20,
+					"candidatesTokenCount": 7,
+					"totalTokenCount":      30,
+				},
 			})
 		default:
 			w.WriteHeader(http.StatusNotFound)
@@ -272,19 +356,29 @@ func TestGeminiEndpointsAndHeaders(t *testing.T) {
 	if len(models) != 1 || models[0].ID != "gemini-2.0-flash" {
 		t.Fatalf("unexpected models: %+v", models)
 	}
+	if models[0].ContextLength != 1048576 || models[0].MaxOutputTokens != 8192 {
+		t.Fatalf("unexpected model metadata: %+v", models[0])
+	}
 
-	if _, err := client.Ask(context.Background(), AskRequest{
+	resp, err := client.Ask(context.Background(), AskRequest{
 		Model:      "gemini-2.0-flash",
 		Prompt:     "system prompt",
 		Question:   "question",
 		ExpectJSON: true,
-	}); err != nil {
+	})
+	if err != nil {
 		t.Fatalf("Ask error = %v", err)
 	}
+	if want := (Usage{PromptTokens: 20, CompletionTokens: 7, TotalTokens: 30}); resp.Usage != want {
+		t.Fatalf("usage = %+v, want %+v", resp.Usage, want)
+	}
 }
 
 func TestOllamaEndpoints(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer proxy-token" {
+			t.Errorf("%s Authorization = %q", r.URL.Path, got)
+		}
 		switch r.URL.Path {
 		case "/api/tags":
 			_ = json.NewEncoder(w).Encode(map[string]any{
@@ -308,6 +402,8 @@ func TestOllamaEndpoints(t *testing.T) {
 				"message": map[string]any{
 					"content": "{\"answer\":\"ok\",\"command\":\"\"}",
 				},
+				"prompt_eval_count": 9,
+				"eval_count":        4,
 			})
 		default:
 			w.WriteHeader(http.StatusNotFound)
@@ -315,7 +411,7 @@ func TestOllamaEndpoints(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client, err := New("ollama", ClientOptions{BaseURL: server.URL})
+	client, err := New("ollama", ClientOptions{BaseURL: server.URL, Headers: map[string]string{"Authorization": "Bearer proxy-token"}})
 	if err != nil {
 		t.Fatalf("New(ollama) error = %v", err)
 	}
@@ -328,12 +424,450 @@ func TestOllamaEndpoints(t *testing.T) {
 		t.Fatalf("unexpected models: %+v", models)
 	}
 
-	if _, err := client.Ask(context.Background(), AskRequest{
+	resp, err := client.Ask(context.Background(), AskRequest{
 		Model:      "llama3.2",
 		Prompt:     "system",
 		Question:   "question",
 		ExpectJSON: true,
+	})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if want := (Usage{PromptTokens: 9, CompletionTokens: 4, TotalTokens: 13}); resp.Usage != want {
+		t.Fatalf("usage = %+v, want %+v", resp.Usage, want)
+	}
+}
+
+func TestOllamaOptionsPassthrough(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+		_ = json.NewEncoder(w).Encode(map[string]any{"message": map[string]any{"content": "ok"}})
+	}))
+	defer server.Close()
+
+	plain, _ := New("ollama", ClientOptions{BaseURL: server.URL})
+	tuned, _ := New("ollama", ClientOptions{BaseURL: server.URL, ExtraBody: map[string]any{
+		"options":    map[string]any{"num_ctx": 8192, "num_predict": 256},
+		"keep_alive": "10m",
+	}})
+	temp := 0.2
+	if _, err := plain.Ask(context.Background(), AskRequest{Model: "llama3.2", Prompt: "p", Question: "q"}); err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if _, err := tuned.Ask(context.Background(), AskRequest{
+		Model: "llama3.2", Prompt: "p", Question: "q", Temperature: &temp,
+		Extra: map[string]any{"options": map[string]any{"num_predict": 512}},
+	}); err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+
+	if _, ok := payloads[0]["options"]; ok {
+		t.Fatalf("options sent without configuration: %v", payloads[0])
+	}
+	if _, ok := payloads[0]["keep_alive"]; ok {
+		t.Fatalf("keep_alive sent without configuration: %v", payloads[0])
+	}
+	want := map[string]any{"num_ctx": float64(8192), "num_predict": float64(512), "temperature": 0.2}
+	if !reflect.DeepEqual(payloads[1]["options"], want) || payloads[1]["keep_alive"] != "10m" {
+		t.Fatalf("payload = %v", payloads[1])
+	}
+}
+
+func TestAskReportsTruncation(t *testing.T) {
+	tests := []struct {
+		provider string
+		body     string
+		reason   string
+	}{
+		{"openai", `{"choices":[{"message":{"content":"partial"},"finish_reason":"length"}]}`, "length"},
+		{"anthropic", `{"content":[{"type":"text","text":"partial"}],"stop_reason":"max_tokens"}`, "max_tokens"},
+		{"gemini", `{"candidates":[{"content":{"parts":[{"text":"partial"}]},"finishReason":"MAX_TOKENS"}]}`, "MAX_TOKENS"},
+		{"ollama", `{"message":{"content":"partial"},"done_reason":"length"}`, "length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := New(tt.provider, ClientOptions{BaseURL: server.URL, APIKey: "k"})
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			resp, err := client.Ask(context.Background(), AskRequest{Model: "m", Prompt: "p", Question: "q"})
+			if err != nil {
+				t.Fatalf("Ask error = %v", err)
+			}
+			if !resp.Truncated || resp.FinishReason != tt.reason {
+				t.Fatalf("Truncated = %v, FinishReason = %q", resp.Truncated, resp.FinishReason)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"done"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+	client, _ := New("openai", ClientOptions{BaseURL: server.URL, APIKey: "k"})
+	resp, err := client.Ask(context.Background(), AskRequest{Model: "m", Prompt: "p", Question: "q"})
+	if err != nil || resp.Truncated {
+		t.Fatalf("complete response: Truncated = %v, err = %v", resp.Truncated, err)
+	}
+}
+
+func TestAzureEndpointsAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/my-gpt/chat/completions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("api-version"); got != "2024-10-21" {
+			t.Fatalf("api-version = %q", got)
+		}
+		if got := r.Header.Get("api-key"); got != "az-key" {
+			t.Fatalf("api-key header = %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Fatalf("Authorization header should be empty, got %q", got)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{
+				"message": map[string]any{"content": "{\"answer\":\"ok\",\"command\":\"\"}"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := New("azure", ClientOptions{
+		APIKey:     "az-key",
+		BaseURL:    server.URL,
+		APIVersion: "2024-10-21",
+	})
+	if err != nil {
+		t.Fatalf("New(azure) error = %v", err)
+	}
+
+	if _, err := client.Ask(context.Background(), AskRequest{
+		Model:      "my-gpt",
+		Prompt:     "sys",
+		Question:   "q",
+		ExpectJSON: true,
 	}); err != nil {
 		t.Fatalf("Ask error = %v", err)
 	}
 }
+
+func TestEmbedEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		switch r.URL.Path {
+		case "/v1/embeddings":
+			if payload["model"] != "text-embedding-3-small" {
+				t.Errorf("openai payload = %v", payload)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+				{"index": 1, "embedding": []float32{0.3, 0.4}},
+				{"index": 0, "embedding": []float32{0.1, 0.2}},
+			}})
+		case "/v1beta/models/text-embedding-004:embedContent":
+			if r.Header.Get("x-goog-api-key") != "g-test" {
+				t.Errorf("x-goog-api-key = %q", r.Header.Get("x-goog-api-key"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"embedding": map[string]any{"values": []float32{0.5}}})
+		case "/api/embeddings":
+			if payload["prompt"] != "hello" {
+				t.Errorf("ollama payload = %v", payload)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"embedding": []float32{0.6}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	openai, _ := New("openai", ClientOptions{APIKey: "sk", BaseURL: server.URL + "/v1"})
+	vectors, err := Embed(context.Background(), openai, "text-embedding-3-small", []string{"a", "b"})
+	if err != nil || len(vectors) != 2 || vectors[0][0] != 0.1 || vectors[1][0] != 0.3 {
+		t.Fatalf("openai vectors = %v, err = %v", vectors, err)
+	}
+
+	gemini, _ := New("gemini", ClientOptions{APIKey: "g-test", BaseURL: server.URL + "/v1beta"})
+	vectors, err = Embed(context.Background(), gemini, "models/text-embedding-004", []string{"hello"})
+	if err != nil || len(vectors) != 1 || vectors[0][0] != 0.5 {
+		t.Fatalf("gemini vectors = %v, err = %v", vectors, err)
+	}
+
+	ollama, _ := New("ollama", ClientOptions{BaseURL: server.URL})
+	vectors, err = Embed(context.Background(), ollama, "nomic-embed-text", []string{"hello"})
+	if err != nil || len(vectors) != 1 || vectors[0][0] != 0.6 {
+		t.Fatalf("ollama vectors = %v, err = %v", vectors, err)
+	}
+
+	anthropic, _ := New("anthropic", ClientOptions{APIKey: "a"})
+	if _, err := Embed(context.Background(), anthropic, "m", []string{"x"}); !errors.Is(err, ErrEmbeddingsUnsupported) {
+		t.Fatalf("anthropic err = %v, want ErrEmbeddingsUnsupported", err)
+	}
+}
+
+func TestListModelsPaginates(t *testing.T) {
+	tests := []struct {
+		provider string
+		base     string
+		cursor   string
+		pages    map[string]string
+		want     []string
+	}{
+		{
+			provider: "anthropic",
+			cursor:   "after_id",
+			pages: map[string]string{
+				"":         `{"data":[{"id":"claude-a"},{"id":"claude-b"}],"has_more":true,"last_id":"claude-b"}`,
+				"claude-b": `{"data":[{"id":"claude-b"},{"id":"claude-c"}],"has_more":false,"last_id":"claude-c"}`,
+			},
+			want: []string{"claude-a", "claude-b", "claude-c"},
+		},
+		{
+			provider: "gemini",
+			base:     "/v1beta",
+			cursor:   "pageToken",
+			pages: map[string]string{
+				"":   `{"models":[{"name":"models/gemini-a","supportedGenerationMethods":["generateContent"]}],"nextPageToken":"p2"}`,
+				"p2": `{"models":[{"name":"models/gemini-b","supportedGenerationMethods":["generateContent"]}]}`,
+			},
+			want: []string{"gemini-a", "gemini-b"},
+		},
+		{
+			provider: "openai",
+			cursor:   "after",
+			pages: map[string]string{
+				"":      `{"data":[{"id":"gpt-a"},{"id":"gpt-b"}],"has_more":true}`,
+				"gpt-b": `{"data":[{"id":"gpt-c"}],"has_more":false}`,
+			},
+			want: []string{"gpt-a", "gpt-b", "gpt-c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tt.pages[r.URL.Query().Get(tt.cursor)]
+				if !ok {
+					t.Errorf("unexpected page request %s", r.URL)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client, err := New(tt.provider, ClientOptions{APIKey: "k", BaseURL: server.URL + tt.base})
+			if err != nil {
+				t.Fatalf("New(%s) error = %v", tt.provider, err)
+			}
+			models, err := client.ListModels(context.Background())
+			if err != nil {
+				t.Fatalf("ListModels error = %v", err)
+			}
+			var ids []string
+			for _, m := range models {
+				ids = append(ids, m.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Fatalf("models = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestListModelsStopsAtPageCap(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"models":        []map[string]any{{"name": fmt.Sprintf("models/m%d", requests), "supportedGenerationMethods": []string{"generateContent"}}},
+			"nextPageToken": fmt.Sprintf("p%d", requests),
+		})
+	}))
+	defer server.Close()
+
+	client, err := New("gemini", ClientOptions{APIKey: "k", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("New(gemini) error = %v", err)
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels error = %v", err)
+	}
+	if requests != maxModelPages || len(models) != maxModelPages {
+		t.Fatalf("requests = %d, models = %d, want %d", requests, len(models), maxModelPages)
+	}
+}
+
+func TestOpenRouterAttributionHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{}})
+	}))
+	defer server.Close()
+
+	list := func(provider string, headers map[string]string) {
+		t.Helper()
+		client, err := New(provider, ClientOptions{APIKey: "k", BaseURL: server.URL, Headers: headers})
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", provider, err)
+		}
+		if _, err := client.ListModels(context.Background()); err != nil {
+			t.Fatalf("ListModels error = %v", err)
+		}
+	}
+
+	list("openrouter", nil)
+	if got.Get("X-Title") != "ask" || got.Get("HTTP-Referer") == "" {
+		t.Fatalf("default headers missing: %v", got)
+	}
+
+	list("openrouter", map[string]string{"x-title": "my-app"})
+	if got.Get("X-Title") != "my-app" {
+		t.Fatalf("X-Title = %q, want the configured override", got.Get("X-Title"))
+	}
+
+	list("openai", nil)
+	if got.Get("X-Title") != "" || got.Get("HTTP-Referer") != "" {
+		t.Fatalf("openrouter headers leaked to openai: %v", got)
+	}
+}
+
+func TestOpenAIOrganizationAndProjectHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"ok","command":""}`}}},
+		})
+	}))
+	defer server.Close()
+
+	for _, provider := range []string{"openai", "groq"} {
+		client, err := New(provider, ClientOptions{APIKey: "k", BaseURL: server.URL, Organization: "org-1", Project: "proj-1"})
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", provider, err)
+		}
+		if _, err := client.Ask(context.Background(), AskRequest{Model: "m", Prompt: "sys", Question: "q"}); err != nil {
+			t.Fatalf("Ask(%s) error = %v", provider, err)
+		}
+		wantOrg, wantProject := "org-1", "proj-1"
+		if provider != "openai" {
+			wantOrg, wantProject = "", ""
+		}
+		if got.Get("OpenAI-Organization") != wantOrg || got.Get("OpenAI-Project") != wantProject {
+			t.Fatalf("%s headers = %v, want organization %q and project %q", provider, got, wantOrg, wantProject)
+		}
+	}
+}
+
+func TestUserAgentHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "m"}}})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		opts ClientOptions
+		want string
+	}{
+		{ClientOptions{}, "ask/" + buildinfo.Version},
+		{ClientOptions{UserAgent: "corp-proxy/1"}, "corp-proxy/1"},
+		{ClientOptions{UserAgent: "corp-proxy/1", Headers: map[string]string{"User-Agent": "from-headers"}}, "from-headers"},
+	}
+	for _, tt := range tests {
+		tt.opts.APIKey, tt.opts.BaseURL = "k", server.URL
+		client, err := New("openai", tt.opts)
+		if err != nil {
+			t.Fatalf("New error = %v", err)
+		}
+		if _, err := client.ListModels(context.Background()); err != nil {
+			t.Fatalf("ListModels error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("User-Agent = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestOllamaPullModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pull" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload["stream"] != true {
+			t.Errorf("payload = %v", payload)
+		}
+		if payload["model"] == "missing" {
+			_, _ = w.Write([]byte(`{"error":"pull model manifest: file does not exist"}` + "\n"))
+			return
+		}
+		fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+		fmt.Fprintln(w, `{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":40}`)
+		fmt.Fprintln(w, `{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":100}`)
+		fmt.Fprintln(w, `{"status":"success"}`)
+	}))
+	defer server.Close()
+
+	client, _ := New("ollama", ClientOptions{BaseURL: server.URL})
+	var updates []PullProgress
+	if err := PullModel(context.Background(), client, "llama3.2", func(p PullProgress) { updates = append(updates, p) }); err != nil {
+		t.Fatalf("PullModel error = %v", err)
+	}
+	if len(updates) != 4 || updates[2].Completed != 100 || updates[2].Total != 100 || updates[3].Status != "success" {
+		t.Fatalf("updates = %+v", updates)
+	}
+
+	if err := PullModel(context.Background(), client, "missing", nil); err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Fatalf("err = %v", err)
+	}
+
+	openai, _ := New("openai", ClientOptions{APIKey: "sk"})
+	if err := PullModel(context.Background(), openai, "gpt-4o", nil); !errors.Is(err, ErrPullUnsupported) {
+		t.Fatalf("err = %v, want ErrPullUnsupported", err)
+	}
+}
+
+func TestRequiresAPIKeyMatchesClients(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	for _, name := range SupportedProviders() {
+		if name == "vertex" || name == "bedrock" {
+			// Both authenticate without an API key and need credentials
+			// this test cannot provide.
+			continue
+		}
+		hits = 0
+		client, err := New(name, ClientOptions{BaseURL: server.URL})
+		if err != nil {
+			t.Fatalf("New(%q): %v", name, err)
+		}
+		_, _ = client.Ask(context.Background(), AskRequest{Model: "m", Question: "hi"})
+		if sent := hits > 0; sent == RequiresAPIKey(name) {
+			t.Errorf("%s: request sent = %v without a key, but RequiresAPIKey = %v", name, sent, RequiresAPIKey(name))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// capabilityCache remembers, per provider and model, the strictest response
+// format a model has accepted, so repeated requests skip formats the model
+// already rejected. With a path set, rejections are also read from and
+// written to that file so they carry over between runs.
+type capabilityCache struct {
+	mu      sync.Mutex
+	path    string
+	formats map[string]responseFormat
+}
+
+// newCapabilityCache returns a cache seeded from path, if set. A missing or
+// unreadable file starts the cache empty.
+func newCapabilityCache(path string) *capabilityCache {
+	path = strings.TrimSpace(path)
+	return &capabilityCache{path: path, formats: readCapabilities(path)}
+}
+
+func capabilityKey(provider, model string) string {
+	return strings.ToLower(strings.TrimSpace(provider)) + "/" + strings.TrimSpace(model)
+}
+
+// responseFormats returns the formats to try for req on provider, dropping
+// any stricter than a format the model previously rejected.
+func (c *capabilityCache) responseFormats(provider string, req AskRequest) []responseFormat {
+	formats := responseFormats(req)
+	if c == nil {
+		return formats
+	}
+	c.mu.Lock()
+	limit, ok := c.formats[capabilityKey(provider, req.Model)]
+	c.mu.Unlock()
+	if !ok {
+		return formats
+	}
+	for i, format := range formats {
+		if format <= limit {
+			return formats[i:]
+		}
+	}
+	return formats[len(formats)-1:]
+}
+
+// rejectFormat records that model on provider does not support format, so
+// later requests start from the next weaker one.
+func (c *capabilityCache) rejectFormat(provider, model string, format responseFormat) {
+	if c == nil || format == formatNone {
+		return
+	}
+	key := capabilityKey(provider, model)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit, ok := c.formats[key]; ok && format-1 >= limit {
+		return
+	}
+	c.formats[key] = format - 1
+	c.save()
+}
+
+// save merges the in-memory limits into the file at c.path, keeping the
+// stricter limit where another process recorded one too. The cache is an
+// optimization, so write failures are ignored.
+func (c *capabilityCache) save() {
+	if c.path == "" {
+		return
+	}
+	for key, limit := range readCapabilities(c.path) {
+		if current, ok := c.formats[key]; !ok || limit < current {
+			c.formats[key] = limit
+		}
+	}
+	encoded, err := json.MarshalIndent(c.formats, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+func readCapabilities(path string) map[string]responseFormat {
+	formats := map[string]responseFormat{}
+	if path == "" {
+		return formats
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return formats
+	}
+	var stored map[string]responseFormat
+	if err := json.Unmarshal(buf, &stored); err != nil {
+		return formats
+	}
+	for key, limit := range stored {
+		if limit >= formatNone && limit < formatJSONSchema {
+			formats[key] = limit
+		}
+	}
+	return formats
+}
@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCapabilityCache(t *testing.T) {
+	cache := newCapabilityCache("")
+	schemaReq := AskRequest{Model: "m", ExpectJSON: true, JSONSchema: &JSONSchema{Name: "reply"}}
+	objectReq := AskRequest{Model: "m", ExpectJSON: true}
+
+	if got, want := cache.responseFormats("p", schemaReq), []responseFormat{formatJSONSchema, formatJSONObject, formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("fresh formats = %v, want %v", got, want)
+	}
+
+	cache.rejectFormat("p", "m", formatJSONSchema)
+	if got, want := cache.responseFormats("p", schemaReq), []responseFormat{formatJSONObject, formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after schema rejection = %v, want %v", got, want)
+	}
+	if got, want := cache.responseFormats("other", schemaReq), []responseFormat{formatJSONSchema, formatJSONObject, formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("other provider = %v, want %v", got, want)
+	}
+
+	cache.rejectFormat("p", "m", formatJSONObject)
+	if got, want := cache.responseFormats("p", objectReq), []responseFormat{formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after object rejection = %v, want %v", got, want)
+	}
+
+	// A later, weaker rejection never loosens the limit again.
+	cache.rejectFormat("p", "m", formatJSONSchema)
+	if got, want := cache.responseFormats("p", schemaReq), []responseFormat{formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("limit loosened: %v, want %v", got, want)
+	}
+}
+
+func TestCapabilityCachePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "capabilities.json")
+	schemaReq := AskRequest{Model: "m", ExpectJSON: true, JSONSchema: &JSONSchema{Name: "reply"}}
+
+	newCapabilityCache(path).rejectFormat("p", "m", formatJSONSchema)
+	if got, want := newCapabilityCache(path).responseFormats("p", schemaReq), []responseFormat{formatJSONObject, formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("reloaded formats = %v, want %v", got, want)
+	}
+
+	// A second client keeps rejections another client recorded meanwhile.
+	first, second := newCapabilityCache(path), newCapabilityCache(path)
+	first.rejectFormat("p", "other", formatJSONObject)
+	second.rejectFormat("q", "m", formatJSONSchema)
+	reloaded := newCapabilityCache(path)
+	if got, want := reloaded.responseFormats("p", AskRequest{Model: "other", ExpectJSON: true}), []responseFormat{formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("merged formats = %v, want %v", got, want)
+	}
+	if got, want := reloaded.responseFormats("q", schemaReq), []responseFormat{formatJSONObject, formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("merged formats = %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := newCapabilityCache(path).responseFormats("p", schemaReq), []responseFormat{formatJSONSchema, formatJSONObject, formatNone}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("corrupt file formats = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,8 @@
+package providers
+
+func newDeepSeekClient(opts ClientOptions) Client {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.deepseek.com/v1"
+	}
+	return newOpenAICompatibleClient(OpenAICompatibleSettings{Name: "deepseek"}, opts)
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrEmbeddingsUnsupported reports that a provider has no embeddings API.
+var ErrEmbeddingsUnsupported = errors.New("embeddings are not supported")
+
+// Embedder is implemented by clients that can generate embeddings.
+type Embedder interface {
+	Embed(ctx context.Context, model string, input []string) ([][]float32, error)
+}
+
+// Embed returns one vector per input using client. Clients that do not
+// implement Embedder yield an error wrapping ErrEmbeddingsUnsupported.
+func Embed(ctx context.Context, client Client, model string, input []string) ([][]float32, error) {
+	embedder, ok := client.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", client.Name(), ErrEmbeddingsUnsupported)
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if len(input) == 0 {
+		return nil, fmt.Errorf("input is required")
+	}
+	return embedder.Embed(ctx, strings.TrimSpace(model), input)
+}
+
+func (c *openAICompatibleClient) Embed(ctx context.Context, model string, input []string) ([][]float32, error) {
+	if c.requiresAPIKey() && c.apiKey == "" {
+		return nil, fmt.Errorf("API key not configured for %s", c.name)
+	}
+	return c.embedURL(ctx, joinURL(c.base, "/embeddings"), model, input)
+}
+
+// embedURL posts an OpenAI-style embeddings request to url and returns the
+// vectors in input order.
+func (c *openAICompatibleClient) embedURL(ctx context.Context, url, model string, input []string) ([][]float32, error) {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	c.setHeaders(req)
+
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := doJSON(ctx, c.http, req, map[string]any{"model": model, "input": input}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(input) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d inputs", c.name, len(resp.Data), len(input))
+	}
+	sort.Slice(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+	vectors := make([][]float32, len(resp.Data))
+	for i, d := range resp.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
+
+func (c *azureClient) Embed(ctx context.Context, model string, input []string) ([][]float32, error) {
+	if c.inner.base == "" {
+		return nil, fmt.Errorf("base URL not configured for azure; set providers.azure.base_url to https://<resource>.openai.azure.com")
+	}
+	if c.inner.apiKey == "" {
+		return nil, fmt.Errorf("AZURE_OPENAI_API_KEY not configured")
+	}
+	path := fmt.Sprintf("/openai/deployments/%s/embeddings", url.PathEscape(model))
+	return c.inner.embedURL(ctx, joinURL(c.inner.base, path)+"?api-version="+url.QueryEscape(c.apiVersion), model, input)
+}
+
+func (c *geminiClient) Embed(ctx context.Context, model string, input []string) ([][]float32, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY not configured")
+	}
+	model = strings.TrimPrefix(model, "models/")
+	vectors := make([][]float32, 0, len(input))
+	for _, text := range input {
+		req, err := http.NewRequest(http.MethodPost, joinURL(c.base, fmt.Sprintf("/models/%s:embedContent", model)), nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		c.setHeaders(req)
+
+		payload := map[string]any{
+			"content": map[string]any{"parts": []map[string]string{{"text": text}}},
+		}
+		var resp struct {
+			Embedding struct {
+				Values []float32 `json:"values"`
+			} `json:"embedding"`
+		}
+		if err := doJSON(ctx, c.http, req, payload, &resp); err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, resp.Embedding.Values)
+	}
+	return vectors, nil
+}
+
+func (c *ollamaClient) Embed(ctx context.Context, model string, input []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(input))
+	for _, text := range input {
+		req, err := http.NewRequest(http.MethodPost, joinURL(c.base, "/api/embeddings"), nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		c.setHeaders(req)
+
+		var resp struct {
+			Embedding []float32 `json:"embedding"`
+		}
+		if err := doJSON(ctx, c.http, req, map[string]any{"model": model, "prompt": text}, &resp); err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, resp.Embedding)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,185 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// HTTPError is returned when a provider responds with an error status.
+// Provider names the provider that responded, when known. RetryAfter is zero
+// unless the provider sent a wait hint.
+type HTTPError struct {
+	Provider   string
+	StatusCode int
+	Status     string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	if e.IsAuth() {
+		// Auth failure bodies rarely say more than the status; --verbose
+		// traces them when needed.
+		name := e.Provider
+		if name == "" {
+			name = "provider"
+		}
+		return fmt.Sprintf("authentication failed for %s (%s)", name, e.Status)
+	}
+	hint := ""
+	if e.RetryAfter > 0 {
+		hint = fmt.Sprintf(" (retry after %s)", e.RetryAfter.Round(time.Second))
+	}
+	if msg, ok := providerMessage(e.Body); ok {
+		return fmt.Sprintf("provider returned %s%s: %s", e.Status, hint, msg)
+	}
+	return fmt.Sprintf("provider returned %s%s: %s", e.Status, hint, truncate(e.Body, 700))
+}
+
+// providerMessage extracts "message (code)" from the error bodies used by
+// OpenAI-compatible APIs ({"error":{"message","type","code"}}), Anthropic
+// ({"error":{"type","message"}}), Gemini ({"error":{"code","message","status"}})
+// Ollama ({"error":"..."}) and AWS ({"message":"..."}). It reports false for
+// unrecognized bodies.
+func providerMessage(body string) (string, bool) {
+	var envelope struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		return "", false
+	}
+	if len(envelope.Error) == 0 {
+		msg := strings.TrimSpace(envelope.Message)
+		return truncate(msg, 700), msg != ""
+	}
+	var text string
+	if err := json.Unmarshal(envelope.Error, &text); err == nil {
+		text = strings.TrimSpace(text)
+		return text, text != ""
+	}
+	var detail struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+		Status  string `json:"status"`
+	}
+	if err := json.Unmarshal(envelope.Error, &detail); err != nil {
+		return "", false
+	}
+	msg := strings.TrimSpace(detail.Message)
+	if msg == "" {
+		return "", false
+	}
+	// Numeric codes (Gemini) repeat the HTTP status, so prefer a symbolic one.
+	code, _ := detail.Code.(string)
+	for _, candidate := range []string{code, detail.Status, detail.Type} {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			return fmt.Sprintf("%s (%s)", truncate(msg, 700), candidate), true
+		}
+	}
+	return truncate(msg, 700), true
+}
+
+// IsAuth reports whether the provider rejected the request's credentials.
+// Every 401 counts; a 403 counts only when its body says the key, token or
+// permissions were at fault, since providers also answer 403 for blocked
+// regions, moderation and other policy refusals.
+func (e *HTTPError) IsAuth() bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusForbidden:
+		return authFailureBody(e.Body)
+	default:
+		return false
+	}
+}
+
+// authCodes are error codes and words that on their own mean the
+// credentials were rejected.
+var authCodes = []string{
+	"unauthorized", "unauthenticated", "authentication", "authentication_error", "invalid_api_key",
+	"incorrect_api_key", "api_key_invalid", "invalid_token", "expired_token", "token_expired",
+}
+
+// credentialTerms name a credential, and credentialFaults say what went wrong
+// with one. A 403 naming a credential together with a fault is an auth
+// failure; either alone is not, so PERMISSION_DENIED for a blocked region
+// still shows the provider's message.
+var (
+	credentialTerms = []string{
+		"api key", "api_key", "apikey", "access key", "security token", "access token", "bearer token",
+		"credential", "credentials",
+	}
+	credentialFaults = []string{
+		"permission", "permission_denied", "permission_error", "permissiondenied",
+		"invalid", "expired", "incorrect", "missing", "revoked", "match",
+	}
+)
+
+var wordPattern = regexp.MustCompile(`[a-z0-9_]+`)
+
+// authFailureBody reports whether an error response body, in any of the
+// shapes providerMessage understands, blames the request's credentials.
+// Terms match whole words, so "author" is not "auth".
+func authFailureBody(body string) bool {
+	var envelope struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		return false
+	}
+	fields := []string{envelope.Message}
+	var text string
+	if err := json.Unmarshal(envelope.Error, &text); err == nil {
+		fields = append(fields, text)
+	} else {
+		var detail struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Code    any    `json:"code"`
+			Status  string `json:"status"`
+		}
+		if err := json.Unmarshal(envelope.Error, &detail); err == nil {
+			code, _ := detail.Code.(string)
+			fields = append(fields, detail.Message, detail.Type, code, detail.Status)
+		}
+	}
+	words := " " + strings.Join(wordPattern.FindAllString(strings.ToLower(strings.Join(fields, " ")), -1), " ") + " "
+	has := func(terms []string) bool {
+		for _, term := range terms {
+			if strings.Contains(words, " "+term+" ") {
+				return true
+			}
+		}
+		return false
+	}
+	return has(authCodes) || (has(credentialTerms) && has(credentialFaults))
+}
+
+// IsTransient reports whether err indicates the provider was unavailable
+// rather than the request being invalid: network failures, timeouts, rate
+// limits, and 5xx responses. User cancellation is not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
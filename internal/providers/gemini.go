@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"sort"
@@ -9,13 +10,15 @@ import (
 )
 
 type geminiClient struct {
-	apiKey  string
-	base    string
-	http    *http.Client
-	headers map[string]string
+	apiKey       string
+	base         string
+	http         *requester
+	headers      map[string]string
+	capabilities *capabilityCache
+	extraBody    map[string]any
 }
 
-func newGeminiClient(opts ClientOptions) Client {
+func newGeminiClient(opts ClientOptions) *geminiClient {
 	base := opts.BaseURL
 	if strings.TrimSpace(base) == "" {
 		base = "https://generativelanguage.googleapis.com/v1beta"
@@ -25,10 +28,12 @@ func newGeminiClient(opts ClientOptions) Client {
 		headers[k] = v
 	}
 	return &geminiClient{
-		apiKey:  strings.TrimSpace(opts.APIKey),
-		base:    strings.TrimRight(strings.TrimSpace(base), "/"),
-		http:    defaultHTTPClient(opts.HTTPClient),
-		headers: headers,
+		apiKey:       strings.TrimSpace(opts.APIKey),
+		base:         strings.TrimRight(strings.TrimSpace(base), "/"),
+		http:         newRequester(opts),
+		headers:      headers,
+		capabilities: newCapabilityCache(opts.CapabilityCache),
+		extraBody:    opts.ExtraBody,
 	}
 }
 
@@ -39,37 +44,65 @@ func (c *geminiClient) ListModels(ctx context.Context) ([]Model, error) {
 		return nil, fmt.Errorf("GEMINI_API_KEY not configured")
 	}
 
-	req, err := http.NewRequest(http.MethodGet, joinURL(c.base, "/models"), nil)
-	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
-	}
-	c.setHeaders(req)
+	endpoint := joinURL(c.base, "/models")
 
-	var resp struct {
-		Models []struct {
-			Name                       string   `json:"name"`
-			DisplayName                string   `json:"displayName"`
-			SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
-		} `json:"models"`
-	}
-	if err := doJSON(ctx, c.http, req, nil, &resp); err != nil {
-		return nil, err
-	}
+	var models []Model
+	seen := map[string]bool{}
+	token := ""
+	for page := 0; page < maxModelPages; page++ {
+		pageURL := endpoint
+		if token != "" {
+			var err error
+			if pageURL, err = withQuery(endpoint, "pageToken", token); err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		c.setHeaders(req)
 
-	models := make([]Model, 0, len(resp.Models))
-	for _, m := range resp.Models {
-		if !supportsGenerateContent(m.SupportedGenerationMethods) {
-			continue
+		var resp struct {
+			Models []struct {
+				Name                       string   `json:"name"`
+				DisplayName                string   `json:"displayName"`
+				SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+				InputTokenLimit            int      `json:"inputTokenLimit"`
+				OutputTokenLimit           int      `json:"outputTokenLimit"`
+			} `json:"models"`
+			NextPageToken string `json:"nextPageToken"`
 		}
-		id := strings.TrimPrefix(strings.TrimSpace(m.Name), "models/")
-		if id == "" {
-			continue
+		if err := doJSON(ctx, c.http, req, nil, &resp); err != nil {
+			return nil, err
 		}
-		display := strings.TrimSpace(m.DisplayName)
-		if display == "" {
-			display = id
+
+		for _, m := range resp.Models {
+			if !supportsGenerateContent(m.SupportedGenerationMethods) {
+				continue
+			}
+			id := strings.TrimPrefix(strings.TrimSpace(m.Name), "models/")
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			display := strings.TrimSpace(m.DisplayName)
+			if display == "" {
+				display = id
+			}
+			models = append(models, Model{
+				ID:              id,
+				DisplayName:     display,
+				ContextLength:   m.InputTokenLimit,
+				MaxOutputTokens: m.OutputTokenLimit,
+			})
+		}
+
+		next := strings.TrimSpace(resp.NextPageToken)
+		if next == "" || next == token {
+			break
 		}
-		models = append(models, Model{ID: id, DisplayName: display})
+		token = next
 	}
 	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
 	return models, nil
@@ -90,28 +123,42 @@ func (c *geminiClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse
 	}
 
 	path := fmt.Sprintf("/models/%s:generateContent", model)
-	req, err := http.NewRequest(http.MethodPost, joinURL(c.base, path), nil)
+	return c.generateContent(ctx, c.Name(), joinURL(c.base, path), reqBody, c.setHeaders)
+}
+
+// generateContent sends reqBody in Gemini's generateContent shape to url,
+// using setHeaders for authentication. name labels errors and the capability
+// cache, so Vertex AI can share the payload handling.
+func (c *geminiClient) generateContent(ctx context.Context, name, url string, reqBody AskRequest, setHeaders func(*http.Request)) (AskResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
 	if err != nil {
 		return AskResponse{}, fmt.Errorf("build request: %w", err)
 	}
-	c.setHeaders(req)
+	setHeaders(req)
 
-	payload := map[string]any{
-		"systemInstruction": map[string]any{
-			"parts": []map[string]string{{"text": reqBody.Prompt}},
-		},
-		"contents": []map[string]any{
-			{
-				"role":  "user",
-				"parts": []map[string]string{{"text": reqBody.Question}},
-			},
-		},
-		"generationConfig": map[string]any{
-			"temperature": 0.2,
-		},
-	}
-	if reqBody.ExpectJSON {
-		payload["generationConfig"].(map[string]any)["responseMimeType"] = "application/json"
+	conversation := conversationMessages(reqBody)
+	contents := make([]map[string]any, 0, len(conversation))
+	for i, m := range conversation {
+		role := "user"
+		if m.Role == RoleAssistant {
+			role = "model"
+		}
+		parts := []map[string]any{{"text": m.Content}}
+		if i == len(conversation)-1 {
+			for _, img := range reqBody.Images {
+				if img.URL != "" {
+					return AskResponse{}, fmt.Errorf("%s does not accept image URLs; download %s and pass a local file", name, img.URL)
+				}
+				parts = append(parts, map[string]any{"inlineData": map[string]string{
+					"mimeType": img.MIMEType,
+					"data":     base64.StdEncoding.EncodeToString(img.Data),
+				}})
+			}
+		}
+		contents = append(contents, map[string]any{
+			"role":  role,
+			"parts": parts,
+		})
 	}
 
 	var resp struct {
@@ -121,29 +168,36 @@ func (c *geminiClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse
 					Text string `json:"text"`
 				} `json:"parts"`
 			} `json:"content"`
+			FinishReason string `json:"finishReason"`
 		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			TotalTokenCount      int `json:"totalTokenCount"`
+		} `json:"usageMetadata"`
 	}
-	if err := doJSON(ctx, c.http, req, payload, &resp); err != nil {
-		if reqBody.ExpectJSON && responseFormatLikelyUnsupported(err) {
-			payloadNoFormat := map[string]any{
-				"systemInstruction": payload["systemInstruction"],
-				"contents":          payload["contents"],
-				"generationConfig": map[string]any{
-					"temperature": 0.2,
-				},
-			}
-			retryReq, buildErr := http.NewRequest(http.MethodPost, joinURL(c.base, path), nil)
-			if buildErr != nil {
-				return AskResponse{}, fmt.Errorf("build retry request: %w", buildErr)
-			}
-			c.setHeaders(retryReq)
-			if retryErr := doJSON(ctx, c.http, retryReq, payloadNoFormat, &resp); retryErr != nil {
-				return AskResponse{}, retryErr
-			}
-		} else {
-			return AskResponse{}, err
+	formats := c.capabilities.responseFormats(name, reqBody)
+	for i, format := range formats {
+		payload := map[string]any{
+			"systemInstruction": map[string]any{
+				"parts": []map[string]string{{"text": reqBody.Prompt}},
+			},
+			"contents":         contents,
+			"generationConfig": geminiGenerationConfig(reqBody, format),
+		}
+		mergeParams(payload, c.extraBody)
+		mergeParams(payload, reqBody.Extra)
+		err = doJSON(ctx, c.http, req, payload, &resp)
+		if err == nil || !responseFormatLikelyUnsupported(err) {
+			break
+		}
+		if i < len(formats)-1 {
+			c.capabilities.rejectFormat(name, reqBody.Model, format)
 		}
 	}
+	if err != nil {
+		return AskResponse{}, err
+	}
 	if len(resp.Candidates) == 0 {
 		return AskResponse{}, fmt.Errorf("no candidates returned by Gemini")
 	}
@@ -157,7 +211,12 @@ func (c *geminiClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse
 	if len(parts) == 0 {
 		return AskResponse{}, fmt.Errorf("Gemini response had no text parts")
 	}
-	return AskResponse{Text: strings.Join(parts, "\n")}, nil
+	reason := resp.Candidates[0].FinishReason
+	return AskResponse{Text: strings.Join(parts, "\n"), Usage: Usage{
+		PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+	}.withTotal(), FinishReason: reason, Truncated: reason == "MAX_TOKENS"}, nil
 }
 
 func (c *geminiClient) setHeaders(req *http.Request) {
@@ -170,6 +229,58 @@ func (c *geminiClient) setHeaders(req *http.Request) {
 	}
 }
 
+// geminiGenerationConfig builds generationConfig for reqBody, requesting a
+// JSON response MIME type and schema according to format.
+func geminiGenerationConfig(reqBody AskRequest, format responseFormat) map[string]any {
+	config := map[string]any{}
+	if reqBody.Temperature != nil {
+		config["temperature"] = *reqBody.Temperature
+	}
+	if reqBody.MaxTokens > 0 {
+		config["maxOutputTokens"] = reqBody.MaxTokens
+	}
+	if format >= formatJSONObject {
+		config["responseMimeType"] = "application/json"
+	}
+	if format == formatJSONSchema {
+		config["responseSchema"] = geminiSchema(reqBody.JSONSchema.Schema)
+	}
+	return config
+}
+
+// geminiSchema converts a JSON Schema object to Gemini's OpenAPI-style schema,
+// which uses upper-case type names and rejects additionalProperties.
+func geminiSchema(schema map[string]any) map[string]any {
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "additionalProperties":
+			continue
+		case "type":
+			if name, ok := value.(string); ok {
+				value = strings.ToUpper(name)
+			}
+		case "properties":
+			if props, ok := value.(map[string]any); ok {
+				converted := make(map[string]any, len(props))
+				for name, prop := range props {
+					if nested, ok := prop.(map[string]any); ok {
+						prop = geminiSchema(nested)
+					}
+					converted[name] = prop
+				}
+				value = converted
+			}
+		case "items":
+			if nested, ok := value.(map[string]any); ok {
+				value = geminiSchema(nested)
+			}
+		}
+		out[key] = value
+	}
+	return out
+}
+
 func supportsGenerateContent(methods []string) bool {
 	for _, method := range methods {
 		if strings.EqualFold(strings.TrimSpace(method), "generateContent") {
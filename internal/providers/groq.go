@@ -0,0 +1,8 @@
+package providers
+
+func newGroqClient(opts ClientOptions) Client {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.groq.com/openai/v1"
+	}
+	return newOpenAICompatibleClient(OpenAICompatibleSettings{Name: "groq"}, opts)
+}
@@ -4,56 +4,322 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/sasanktumpati/ask/internal/buildinfo"
+)
+
+const (
+	defaultHTTPTimeout    = 60 * time.Second
+	defaultRetries        = 2
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay         = 10 * time.Second
+	maxRetryAfter         = 60 * time.Second
 )
 
-func defaultHTTPClient(input *http.Client) *http.Client {
-	if input != nil {
-		return input
+// requester sends JSON requests over an HTTP client with retry behavior for
+// transient failures. secretParams names query parameters that carry
+// credentials and must be redacted from traces and errors.
+type requester struct {
+	client       *http.Client
+	err          error
+	provider     string
+	userAgent    string
+	logger       *slog.Logger
+	retries      int
+	baseDelay    time.Duration
+	secretParams []string
+}
+
+func newRequester(opts ClientOptions) *requester {
+	retries := opts.Retries
+	if retries == 0 {
+		retries = defaultRetries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	baseDelay := opts.RetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	userAgent := strings.TrimSpace(opts.UserAgent)
+	if userAgent == "" {
+		userAgent = buildinfo.UserAgent
+	}
+	client, err := defaultHTTPClient(opts)
+	return &requester{
+		client:    client,
+		err:       err,
+		provider:  opts.provider,
+		userAgent: userAgent,
+		logger:    opts.Logger,
+		retries:   retries,
+		baseDelay: baseDelay,
+	}
+}
+
+// defaultHTTPClient returns opts.HTTPClient when set, otherwise a client with
+// opts.Timeout and, when opts.ProxyURL is set, a transport that routes every
+// request through that proxy.
+func defaultHTTPClient(opts ClientOptions) (*http.Client, error) {
+	if opts.HTTPClient != nil {
+		return opts.HTTPClient, nil
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	if strings.TrimSpace(opts.ProxyURL) == "" {
+		return client, nil
+	}
+	proxy, err := parseProxyURL(opts.ProxyURL)
+	if err != nil {
+		return client, err
 	}
-	return &http.Client{Timeout: 60 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxy)
+	client.Transport = transport
+	return client, nil
 }
 
-func doJSON(ctx context.Context, client *http.Client, req *http.Request, payload any, out any) error {
+// parseProxyURL validates a proxy_url value. net/http dials socks5 and
+// socks5h proxies natively, so no extra dialer is needed.
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy_url %q: scheme must be http, https, socks5, or socks5h", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy_url %q: missing host", raw)
+	}
+	return u, nil
+}
+
+func doJSON(ctx context.Context, client *requester, req *http.Request, payload any, out any) error {
+	if client.err != nil {
+		return client.err
+	}
+	var buf []byte
 	if payload != nil {
-		buf, err := json.Marshal(payload)
+		encoded, err := json.Marshal(payload)
 		if err != nil {
 			return fmt.Errorf("encode request JSON: %w", err)
 		}
-		req.Body = io.NopCloser(bytes.NewReader(buf))
-		req.ContentLength = int64(len(buf))
+		buf = encoded
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := client.Do(req.WithContext(ctx))
+	var body []byte
+	for attempt := 0; ; attempt++ {
+		resp, respBody, err := client.send(ctx, req, buf)
+		status := 0
+		wait := client.backoff(attempt)
+		if err == nil {
+			status = resp.StatusCode
+			if status < 400 {
+				body = respBody
+				break
+			}
+			httpErr := &HTTPError{Provider: client.provider, StatusCode: status, Status: resp.Status, Body: string(respBody)}
+			if delay, ok := retryAfter(resp.Header, respBody, time.Now()); ok {
+				httpErr.RetryAfter = delay
+				wait = delay
+			}
+			err = httpErr
+		}
+		if attempt >= client.retries || !retryable(ctx, status, err) || wait > maxRetryAfter {
+			return err
+		}
+		if !sleepContext(ctx, wait) {
+			return err
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if strings.TrimSpace(string(body)) == "" {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response JSON: %w; body=%s", err, truncate(string(body), 700))
+	}
+	return nil
+}
+
+// send performs a single attempt and returns the closed response with its
+// fully read body.
+func (r *requester) send(ctx context.Context, req *http.Request, payload []byte) (*http.Response, []byte, error) {
+	attemptReq := req.Clone(ctx)
+	if payload != nil {
+		attemptReq.Body = io.NopCloser(bytes.NewReader(payload))
+		attemptReq.ContentLength = int64(len(payload))
+	}
+	r.setUserAgent(attemptReq)
+
+	r.traceRequest(attemptReq, payload)
+	start := time.Now()
+	resp, err := r.client.Do(attemptReq)
 	if err != nil {
-		return fmt.Errorf("http request failed: %w", err)
+		// url.Error includes the request URL, which may carry a key.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(attemptReq.URL, r.secretParams...)
+		}
+		r.traceResponse(nil, nil, time.Since(start), err)
+		return nil, nil, fmt.Errorf("http request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("read response: %w", err)
+		r.traceResponse(nil, nil, time.Since(start), err)
+		return nil, nil, fmt.Errorf("read response: %w", err)
+	}
+	r.traceResponse(resp, body, time.Since(start), nil)
+	return resp, body, nil
+}
+
+// stream sends req once with a JSON payload and returns the open response for
+// the caller to read and close. The client timeout is lifted because streams
+// such as model downloads outlive it; ctx bounds the request instead. Error
+// statuses are returned as *HTTPError.
+func (r *requester) stream(ctx context.Context, req *http.Request, payload any) (*http.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode request JSON: %w", err)
 	}
+	attemptReq := req.Clone(ctx)
+	attemptReq.Header.Set("Content-Type", "application/json")
+	attemptReq.Body = io.NopCloser(bytes.NewReader(encoded))
+	attemptReq.ContentLength = int64(len(encoded))
+	r.setUserAgent(attemptReq)
 
+	client := *r.client
+	client.Timeout = 0
+	r.traceRequest(attemptReq, encoded)
+	start := time.Now()
+	resp, err := client.Do(attemptReq)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(attemptReq.URL, r.secretParams...)
+		}
+		r.traceResponse(nil, nil, time.Since(start), err)
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("provider returned %s: %s", resp.Status, truncate(string(body), 700))
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		r.traceResponse(resp, body, time.Since(start), nil)
+		return nil, &HTTPError{Provider: r.provider, StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
 	}
+	r.traceResponse(resp, nil, time.Since(start), nil)
+	return resp, nil
+}
 
-	if out == nil {
-		return nil
+// setUserAgent identifies ask to the provider unless a configured header
+// already set User-Agent.
+func (r *requester) setUserAgent(req *http.Request) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", r.userAgent)
 	}
-	if strings.TrimSpace(string(body)) == "" {
-		return nil
+}
+
+// backoff returns an exponential delay with jitter for the given attempt.
+func (r *requester) backoff(attempt int) time.Duration {
+	delay := r.baseDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
 	}
-	if err := json.Unmarshal(body, out); err != nil {
-		return fmt.Errorf("decode response JSON: %w; body=%s", err, truncate(string(body), 700))
+	return delay/2 + time.Duration(rand.Int64N(int64(delay/2)+1))
+}
+
+// retryAfter extracts a server-provided wait hint from the retry-after-ms or
+// Retry-After headers (seconds or HTTP date), or a retry_after_ms body field.
+func retryAfter(header http.Header, body []byte, now time.Time) (time.Duration, bool) {
+	if raw := strings.TrimSpace(header.Get("retry-after-ms")); raw != "" {
+		if ms, err := strconv.ParseFloat(raw, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+	if raw := strings.TrimSpace(header.Get("Retry-After")); raw != "" {
+		if seconds, err := strconv.ParseFloat(raw, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		if at, err := http.ParseTime(raw); err == nil {
+			if d := at.Sub(now); d > 0 {
+				return d, true
+			}
+			return 0, true
+		}
+	}
+
+	var payload struct {
+		RetryAfterMS *float64 `json:"retry_after_ms"`
+		Error        struct {
+			RetryAfterMS *float64 `json:"retry_after_ms"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		for _, ms := range []*float64{payload.RetryAfterMS, payload.Error.RetryAfterMS} {
+			if ms != nil && *ms >= 0 {
+				return time.Duration(*ms * float64(time.Millisecond)), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// retryable reports whether a failed attempt should be retried: connection
+// errors, 429, and 502/503/504 are transient; context cancellation is not.
+func retryable(ctx context.Context, status int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status {
+	case 0:
+		return err != nil
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepContext waits for d and reports false if ctx ends first or its
+// deadline would pass before d elapses.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
-	return nil
 }
 
 func validateAskRequest(req AskRequest) error {
@@ -63,15 +329,136 @@ func validateAskRequest(req AskRequest) error {
 	if strings.TrimSpace(req.Question) == "" {
 		return fmt.Errorf("question is required")
 	}
+	for key := range req.Extra {
+		if reservedParams[key] {
+			return fmt.Errorf("parameter %q cannot be overridden", key)
+		}
+	}
+	return nil
+}
+
+func validateExtraBody(extra map[string]any) error {
+	for key := range extra {
+		if reservedParams[key] {
+			return fmt.Errorf("extra_body cannot set %q", key)
+		}
+	}
 	return nil
 }
 
+// reservedParams are payload keys that AskRequest.Extra cannot set because ask
+// builds the conversation and reads the response itself.
+var reservedParams = map[string]bool{
+	"model":             true,
+	"messages":          true,
+	"contents":          true,
+	"system":            true,
+	"systemInstruction": true,
+	"stream":            true,
+}
+
+// mergeParams merges extra into payload. Nested objects are merged key by key,
+// so a Gemini generationConfig or Ollama options keeps the fields ask set.
+func mergeParams(payload, extra map[string]any) {
+	for key, value := range extra {
+		nested, ok := value.(map[string]any)
+		existing, exists := payload[key].(map[string]any)
+		if !ok || !exists {
+			payload[key] = value
+			continue
+		}
+		merged := make(map[string]any, len(existing)+len(nested))
+		for k, v := range existing {
+			merged[k] = v
+		}
+		mergeParams(merged, nested)
+		payload[key] = merged
+	}
+}
+
+// rejectImages returns an error when req carries images for a provider
+// without vision support, rather than silently dropping them.
+func rejectImages(provider string, req AskRequest) error {
+	if len(req.Images) > 0 {
+		return fmt.Errorf("%s does not support image input", provider)
+	}
+	return nil
+}
+
+// conversationMessages returns the history followed by the current question
+// as a user message. An exchange with an empty turn is dropped whole, and any
+// remaining adjacent turns with the same role are merged, because Anthropic,
+// Bedrock and Gemini require user and assistant turns to alternate.
+func conversationMessages(req AskRequest) []Message {
+	messages := make([]Message, 0, len(req.History)+1)
+	add := func(role, content string) {
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content += "\n\n" + content
+			return
+		}
+		messages = append(messages, Message{Role: role, Content: content})
+	}
+	skipReply := false
+	for _, m := range req.History {
+		role := strings.ToLower(strings.TrimSpace(m.Role))
+		if role != RoleAssistant {
+			role = RoleUser
+		}
+		empty := strings.TrimSpace(m.Content) == ""
+		switch {
+		case role == RoleUser && empty:
+			skipReply = true
+		case role == RoleAssistant && (empty || skipReply):
+			// Drop the question an empty reply answered, or the reply to an
+			// empty question.
+			if empty && !skipReply && len(messages) > 0 && messages[len(messages)-1].Role == RoleUser {
+				messages = messages[:len(messages)-1]
+			}
+			skipReply = false
+		default:
+			skipReply = false
+			add(role, m.Content)
+		}
+	}
+	add(RoleUser, req.Question)
+	return messages
+}
+
+// responseFormat is the structured-output level requested from a provider.
+type responseFormat int
+
+const (
+	formatNone responseFormat = iota
+	formatJSONObject
+	formatJSONSchema
+)
+
+// responseFormats lists the formats to try for req, strictest first.
+func responseFormats(req AskRequest) []responseFormat {
+	switch {
+	case !req.ExpectJSON:
+		return []responseFormat{formatNone}
+	case req.JSONSchema != nil:
+		return []responseFormat{formatJSONSchema, formatJSONObject, formatNone}
+	default:
+		return []responseFormat{formatJSONObject, formatNone}
+	}
+}
+
 func responseFormatLikelyUnsupported(err error) bool {
 	if err == nil {
 		return false
 	}
 	msg := strings.ToLower(err.Error())
+	// Parsed error messages can omit the parameter name, so check the raw body.
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		msg += " " + strings.ToLower(httpErr.Body)
+	}
 	return strings.Contains(msg, "response_format") ||
+		strings.Contains(msg, "json_schema") ||
+		strings.Contains(msg, "responseschema") ||
+		strings.Contains(msg, "response_schema") ||
 		strings.Contains(msg, "responsemimetype") ||
 		strings.Contains(msg, "response_mime_type")
 }
@@ -83,6 +470,22 @@ func truncate(s string, max int) string {
 	return s[:max] + "..."
 }
 
+// maxModelPages bounds ListModels pagination so an endpoint that keeps
+// returning cursors cannot loop forever.
+const maxModelPages = 50
+
+// withQuery returns rawURL with the query parameter key set to value.
+func withQuery(rawURL, key, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse URL %q: %w", rawURL, err)
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 func joinURL(base, path string) string {
 	base = strings.TrimRight(strings.TrimSpace(base), "/")
 	path = strings.TrimSpace(path)
@@ -0,0 +1,381 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoJSONRetriesTransientStatus(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload["n"] != float64(1) {
+			t.Errorf("attempt %d payload = %v, err = %v", calls.Load()+1, payload, err)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer server.Close()
+
+	client := newRequester(ClientOptions{RetryBaseDelay: time.Millisecond})
+	req, _ := http.NewRequest(http.MethodPost, server.URL, nil)
+	var out struct {
+		OK bool `json:"ok"`
+	}
+	if err := doJSON(context.Background(), client, req, map[string]any{"n": 1}, &out); err != nil {
+		t.Fatalf("doJSON error = %v", err)
+	}
+	if !out.OK || calls.Load() != 3 {
+		t.Fatalf("ok = %v, calls = %d", out.OK, calls.Load())
+	}
+}
+
+func TestDoJSONDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := newRequester(ClientOptions{RetryBaseDelay: time.Millisecond})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err := doJSON(context.Background(), client, req, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestDoJSONRetriesDisabled(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := newRequester(ClientOptions{Retries: -1})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err := doJSON(context.Background(), client, req, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestDoJSONHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// A large base delay proves the Retry-After hint replaced the backoff.
+	client := newRequester(ClientOptions{RetryBaseDelay: time.Hour})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err := doJSON(context.Background(), client, req, nil, nil); err != nil {
+		t.Fatalf("doJSON error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestDoJSONRetryAfterHintInError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newRequester(ClientOptions{Retries: -1})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	err := doJSON(context.Background(), client, req, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "retry after 2m0s") {
+		t.Fatalf("error = %v, want retry hint", err)
+	}
+}
+
+func TestRetryAfterParsing(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		body   string
+		want   time.Duration
+		ok     bool
+	}{
+		{name: "seconds", header: http.Header{"Retry-After": {"7"}}, want: 7 * time.Second, ok: true},
+		{name: "http date", header: http.Header{"Retry-After": {now.Add(30 * time.Second).Format(http.TimeFormat)}}, want: 30 * time.Second, ok: true},
+		{name: "ms header", header: http.Header{"Retry-After-Ms": {"1500"}}, want: 1500 * time.Millisecond, ok: true},
+		{name: "ms body", header: http.Header{}, body: `{"error":{"retry_after_ms":250}}`, want: 250 * time.Millisecond, ok: true},
+		{name: "missing", header: http.Header{}, body: `{}`, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.header, []byte(tt.body), now)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("retryAfter() = %s, %v; want %s, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{&HTTPError{StatusCode: http.StatusInternalServerError}, true},
+		{&HTTPError{StatusCode: http.StatusBadRequest}, false},
+		{&HTTPError{StatusCode: http.StatusUnauthorized}, false},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true},
+		{context.Canceled, false},
+		{errors.New("question is required"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPErrorIsAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{"401 without body", http.StatusUnauthorized, "", true},
+		{"403 anthropic permission", http.StatusForbidden, `{"type":"error","error":{"type":"permission_error","message":"Your API key does not have permission to use the specified resource."}}`, true},
+		{"403 gemini", http.StatusForbidden, `{"error":{"code":403,"message":"Method doesn't allow unregistered callers (callers without established identity). Please use API Key or other form of API consumer identity to call this API.","status":"PERMISSION_DENIED"}}`, true},
+		{"403 azure", http.StatusForbidden, `{"error":{"code":"AuthenticationTypeDisabled","message":"Key based authentication is disabled for this resource."}}`, true},
+		{"403 bedrock token", http.StatusForbidden, `{"message":"The security token included in the request is invalid."}`, true},
+		{"403 region", http.StatusForbidden, `{"error":{"code":"unsupported_country_region_territory","message":"Country, region, or territory not supported","type":"request_forbidden"}}`, false},
+		{"403 moderation", http.StatusForbidden, `{"error":{"message":"Input flagged by moderation","code":403}}`, false},
+		{"403 moderation naming an author", http.StatusForbidden, `{"error":{"message":"Content authored by the user was flagged by moderation","type":"moderation"}}`, false},
+		{"403 google region", http.StatusForbidden, `{"error":{"code":403,"message":"User location is not supported for the API use.","status":"PERMISSION_DENIED"}}`, false},
+		{"403 permission without credentials", http.StatusForbidden, `{"error":{"type":"permission_error","message":"This organization has been disabled."}}`, false},
+		{"403 html", http.StatusForbidden, "<html>Forbidden</html>", false},
+		{"400 mentioning a key", http.StatusBadRequest, `{"error":{"message":"API key not valid"}}`, false},
+	}
+	for _, tt := range tests {
+		err := &HTTPError{StatusCode: tt.status, Body: tt.body}
+		if got := err.IsAuth(); got != tt.want {
+			t.Errorf("%s: IsAuth() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthErrorNamesProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided: sk-bad"}}`))
+	}))
+	defer server.Close()
+
+	client, err := New("groq", ClientOptions{APIKey: "sk-bad", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ListModels(context.Background())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || !httpErr.IsAuth() || httpErr.Provider != "groq" {
+		t.Fatalf("err = %#v", err)
+	}
+	if msg := err.Error(); msg != "authentication failed for groq (401 Unauthorized)" {
+		t.Fatalf("message = %q", msg)
+	}
+	if !strings.Contains(httpErr.Body, "Incorrect API key") {
+		t.Fatalf("body = %q", httpErr.Body)
+	}
+}
+
+func TestProxyURLRoutesRequests(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer proxy.Close()
+
+	client := newRequester(ClientOptions{ProxyURL: proxy.URL, Retries: -1})
+	req, _ := http.NewRequest(http.MethodGet, "http://provider.invalid/v1/models", nil)
+	var out struct {
+		OK bool `json:"ok"`
+	}
+	if err := doJSON(context.Background(), client, req, nil, &out); err != nil {
+		t.Fatalf("doJSON error = %v", err)
+	}
+	if !out.OK || proxied.Load() != "http://provider.invalid/v1/models" {
+		t.Fatalf("ok = %v, proxied = %v", out.OK, proxied.Load())
+	}
+}
+
+func TestProxyURLValidation(t *testing.T) {
+	for _, raw := range []string{"socks5://127.0.0.1:1080", "socks5h://proxy.local:1080", "https://proxy.local:8443"} {
+		client, err := defaultHTTPClient(ClientOptions{ProxyURL: raw})
+		if err != nil {
+			t.Fatalf("%s: error = %v", raw, err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+		got, err := client.Transport.(*http.Transport).Proxy(req)
+		if err != nil || got.String() != raw {
+			t.Fatalf("%s: proxy = %v, err = %v", raw, got, err)
+		}
+	}
+	for _, raw := range []string{"ftp://proxy.local", "socks5://", "not a url"} {
+		client := newRequester(ClientOptions{ProxyURL: raw})
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+		if err := doJSON(context.Background(), client, req, nil, nil); err == nil || !strings.Contains(err.Error(), "proxy_url") {
+			t.Fatalf("%s: err = %v", raw, err)
+		}
+	}
+}
+
+func TestTraceRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	var buf strings.Builder
+	client := newRequester(ClientOptions{Logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))})
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat?key=sk-query-secret&alt=json", nil)
+	req.Header.Set("Authorization", "Bearer sk-header-secret")
+	req.Header.Set("x-goog-api-key", "sk-goog-secret")
+	if err := doJSON(context.Background(), client, req, map[string]any{"model": "m"}, nil); err != nil {
+		t.Fatalf("doJSON error = %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "secret") {
+		t.Fatalf("trace leaked a credential:\n%s", out)
+	}
+	for _, want := range []string{"msg=request", "method=POST", "alt=json", `{\"model\":\"m\"}`, "msg=response", "status=200", `{\"ok\":true}`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("trace missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTTPErrorParsesProviderMessages(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"error":{"message":"The model 'gpt-9' does not exist","type":"invalid_request_error","code":"model_not_found"}}`, "The model 'gpt-9' does not exist (model_not_found)"},
+		{`{"type":"error","error":{"type":"not_found_error","message":"model: claude-9"}}`, "model: claude-9 (not_found_error)"},
+		{`{"error":{"code":404,"message":"models/gemini-9 is not found","status":"NOT_FOUND"}}`, "models/gemini-9 is not found (NOT_FOUND)"},
+		{`{"error":"model \"llama9\" not found, try pulling it first"}`, `model "llama9" not found, try pulling it first`},
+		{`{"message":"Could not resolve the foundation model from the provided model identifier."}`, "Could not resolve the foundation model from the provided model identifier."},
+		{`<html>bad gateway</html>`, `<html>bad gateway</html>`},
+		{`{"detail":"nope"}`, `{"detail":"nope"}`},
+	}
+	for _, tt := range tests {
+		err := &HTTPError{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: tt.body}
+		if got, want := err.Error(), "provider returned 404 Not Found: "+tt.want; got != want {
+			t.Errorf("Error() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestMergeParams(t *testing.T) {
+	payload := map[string]any{
+		"model":            "m",
+		"generationConfig": map[string]any{"responseMimeType": "application/json", "temperature": 0.2},
+	}
+	mergeParams(payload, map[string]any{
+		"generationConfig": map[string]any{"topP": 0.9, "temperature": 0.5},
+		"seed":             7,
+	})
+	config := payload["generationConfig"].(map[string]any)
+	if config["responseMimeType"] != "application/json" || config["topP"] != 0.9 || config["temperature"] != 0.5 || payload["seed"] != 7 {
+		t.Fatalf("payload = %v", payload)
+	}
+
+	err := validateAskRequest(AskRequest{Model: "m", Question: "q", Extra: map[string]any{"messages": []any{}}})
+	if err == nil || !strings.Contains(err.Error(), `"messages"`) {
+		t.Fatalf("validateAskRequest error = %v", err)
+	}
+}
+
+func TestConversationMessagesAlternateRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []Message
+		want    []Message
+	}{
+		{
+			name: "empty reply drops its question",
+			history: []Message{
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleAssistant, Content: "one"},
+				{Role: RoleUser, Content: "unanswered"},
+				{Role: RoleAssistant, Content: "  "},
+			},
+			want: []Message{
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleAssistant, Content: "one"},
+				{Role: RoleUser, Content: "q"},
+			},
+		},
+		{
+			name: "empty question drops its reply",
+			history: []Message{
+				{Role: RoleUser, Content: ""},
+				{Role: RoleAssistant, Content: "orphan"},
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleAssistant, Content: "one"},
+			},
+			want: []Message{
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleAssistant, Content: "one"},
+				{Role: RoleUser, Content: "q"},
+			},
+		},
+		{
+			name: "adjacent user turns merge",
+			history: []Message{
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleAssistant, Content: "one"},
+				{Role: RoleUser, Content: "dangling"},
+			},
+			want: []Message{
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleAssistant, Content: "one"},
+				{Role: RoleUser, Content: "dangling\n\nq"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := conversationMessages(AskRequest{History: tt.history, Question: "q"})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: messages = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package providers
+
+func newMistralClient(opts ClientOptions) Client {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.mistral.ai/v1"
+	}
+	return newOpenAICompatibleClient(OpenAICompatibleSettings{Name: "mistral"}, opts)
+}
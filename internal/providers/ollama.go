@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"sort"
@@ -9,8 +10,10 @@ import (
 )
 
 type ollamaClient struct {
-	base string
-	http *http.Client
+	base      string
+	http      *requester
+	headers   map[string]string
+	extraBody map[string]any
 }
 
 func newOllamaClient(opts ClientOptions) Client {
@@ -18,9 +21,15 @@ func newOllamaClient(opts ClientOptions) Client {
 	if strings.TrimSpace(base) == "" {
 		base = "http://127.0.0.1:11434"
 	}
+	headers := map[string]string{}
+	for k, v := range opts.Headers {
+		headers[k] = v
+	}
 	return &ollamaClient{
-		base: strings.TrimRight(strings.TrimSpace(base), "/"),
-		http: defaultHTTPClient(opts.HTTPClient),
+		base:      strings.TrimRight(strings.TrimSpace(base), "/"),
+		http:      newRequester(opts),
+		headers:   headers,
+		extraBody: opts.ExtraBody,
 	}
 }
 
@@ -31,6 +40,7 @@ func (c *ollamaClient) ListModels(ctx context.Context) ([]Model, error) {
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
 	}
+	c.setHeaders(req)
 
 	var resp struct {
 		Models []struct {
@@ -57,27 +67,58 @@ func (c *ollamaClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse
 	if err := validateAskRequest(reqBody); err != nil {
 		return AskResponse{}, err
 	}
+
 	req, err := http.NewRequest(http.MethodPost, joinURL(c.base, "/api/chat"), nil)
 	if err != nil {
 		return AskResponse{}, fmt.Errorf("build request: %w", err)
 	}
+	c.setHeaders(req)
+
+	conversation := conversationMessages(reqBody)
+	messages := []map[string]any{{"role": "system", "content": reqBody.Prompt}}
+	for i, m := range conversation {
+		message := map[string]any{"role": m.Role, "content": m.Content}
+		if i == len(conversation)-1 && len(reqBody.Images) > 0 {
+			images := make([]string, 0, len(reqBody.Images))
+			for _, img := range reqBody.Images {
+				if img.URL != "" {
+					return AskResponse{}, fmt.Errorf("ollama does not accept image URLs; download %s and pass a local file", img.URL)
+				}
+				images = append(images, base64.StdEncoding.EncodeToString(img.Data))
+			}
+			message["images"] = images
+		}
+		messages = append(messages, message)
+	}
 
 	payload := map[string]any{
-		"model": reqBody.Model,
-		"messages": []map[string]string{
-			{"role": "system", "content": reqBody.Prompt},
-			{"role": "user", "content": reqBody.Question},
-		},
-		"stream": false,
+		"model":    reqBody.Model,
+		"messages": messages,
+		"stream":   false,
 	}
 	if reqBody.ExpectJSON {
 		payload["format"] = "json"
 	}
+	options := map[string]any{}
+	if reqBody.Temperature != nil {
+		options["temperature"] = *reqBody.Temperature
+	}
+	if reqBody.MaxTokens > 0 {
+		options["num_predict"] = reqBody.MaxTokens
+	}
+	if len(options) > 0 {
+		payload["options"] = options
+	}
+	mergeParams(payload, c.extraBody)
+	mergeParams(payload, reqBody.Extra)
 
 	var resp struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		DoneReason      string `json:"done_reason"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
 	}
 	if err := doJSON(ctx, c.http, req, payload, &resp); err != nil {
 		return AskResponse{}, err
@@ -85,5 +126,19 @@ func (c *ollamaClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse
 	if strings.TrimSpace(resp.Message.Content) == "" {
 		return AskResponse{}, fmt.Errorf("ollama response had empty content")
 	}
-	return AskResponse{Text: resp.Message.Content}, nil
+	return AskResponse{Text: resp.Message.Content, Usage: Usage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+	}.withTotal(), FinishReason: resp.DoneReason, Truncated: resp.DoneReason == "length"}, nil
+}
+
+// setHeaders adds the configured headers, such as a token for an Ollama
+// server behind an authenticating reverse proxy.
+func (c *ollamaClient) setHeaders(req *http.Request) {
+	for k, v := range c.headers {
+		if strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
 }
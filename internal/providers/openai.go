@@ -1,8 +1,24 @@
 package providers
 
+import "strings"
+
 func newOpenAIClient(opts ClientOptions) Client {
 	if opts.BaseURL == "" {
 		opts.BaseURL = "https://api.openai.com/v1"
 	}
-	return newOpenAICompatibleClient(OpenAICompatibleSettings{Name: "openai", RequireAPIKey: true}, opts)
+	headers := make(map[string]string, len(opts.Headers)+2)
+	if org := strings.TrimSpace(opts.Organization); org != "" {
+		headers["OpenAI-Organization"] = org
+	}
+	if project := strings.TrimSpace(opts.Project); project != "" {
+		headers["OpenAI-Project"] = project
+	}
+	for k, v := range opts.Headers {
+		headers[k] = v
+	}
+	opts.Headers = headers
+	return newOpenAICompatibleClient(OpenAICompatibleSettings{
+		Name:           "openai",
+		MaxTokensField: "max_completion_tokens",
+	}, opts)
 }
@@ -2,26 +2,37 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 type openAICompatibleClient struct {
-	name          string
-	apiKey        string
-	base          string
-	http          *http.Client
-	modelsPath    string
-	chatPath      string
-	authHeader    string
-	authPrefix    string
-	requireAPIKey bool
-	headers       map[string]string
-}
-
-func newOpenAICompatibleClient(settings OpenAICompatibleSettings, opts ClientOptions) Client {
+	name              string
+	apiKey            string
+	base              string
+	http              *requester
+	modelsPath        string
+	chatPath          string
+	authHeader        string
+	authPrefix        string
+	authQueryParam    string
+	modelsResponseKey string
+	modelIDField      string
+	basicUser         string
+	basicPassword     string
+	maxTokensField    string
+	requireAPIKey     bool
+	headers           map[string]string
+	capabilities      *capabilityCache
+	extraBody         map[string]any
+}
+
+func newOpenAICompatibleClient(settings OpenAICompatibleSettings, opts ClientOptions) *openAICompatibleClient {
 	modelsPath := settings.ModelsPath
 	if strings.TrimSpace(modelsPath) == "" {
 		modelsPath = "/models"
@@ -38,23 +49,41 @@ func newOpenAICompatibleClient(settings OpenAICompatibleSettings, opts ClientOpt
 	if authPrefix == "" {
 		authPrefix = "Bearer "
 	}
+	maxTokensField := strings.TrimSpace(settings.MaxTokensField)
+	if maxTokensField == "" {
+		maxTokensField = "max_tokens"
+	}
 
 	headers := map[string]string{}
 	for k, v := range opts.Headers {
 		headers[k] = v
 	}
 
+	authQueryParam := strings.TrimSpace(settings.AuthQueryParam)
+	client := newRequester(opts)
+	if authQueryParam != "" {
+		client.secretParams = []string{authQueryParam}
+	}
+
 	return &openAICompatibleClient{
-		name:          normalize(settings.Name),
-		apiKey:        strings.TrimSpace(opts.APIKey),
-		base:          strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/"),
-		http:          defaultHTTPClient(opts.HTTPClient),
-		modelsPath:    ensureLeadingSlash(modelsPath),
-		chatPath:      ensureLeadingSlash(chatPath),
-		authHeader:    authHeader,
-		authPrefix:    authPrefix,
-		requireAPIKey: settings.RequireAPIKey,
-		headers:       headers,
+		name:              normalize(settings.Name),
+		apiKey:            strings.TrimSpace(opts.APIKey),
+		base:              strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/"),
+		http:              client,
+		modelsPath:        ensureLeadingSlash(modelsPath),
+		chatPath:          ensureLeadingSlash(chatPath),
+		authHeader:        authHeader,
+		authPrefix:        authPrefix,
+		authQueryParam:    authQueryParam,
+		modelsResponseKey: strings.TrimSpace(settings.ModelsResponseKey),
+		modelIDField:      strings.TrimSpace(settings.ModelIDField),
+		basicUser:         strings.TrimSpace(settings.BasicUser),
+		basicPassword:     settings.BasicPassword,
+		maxTokensField:    maxTokensField,
+		requireAPIKey:     settings.RequireAPIKey || apiKeyProviders[settings.Name],
+		headers:           headers,
+		capabilities:      newCapabilityCache(opts.CapabilityCache),
+		extraBody:         opts.ExtraBody,
 	}
 }
 
@@ -66,32 +95,167 @@ func (c *openAICompatibleClient) ListModels(ctx context.Context) ([]Model, error
 	if c.requiresAPIKey() && c.apiKey == "" {
 		return nil, fmt.Errorf("API key not configured for %s", c.name)
 	}
-	url := joinURL(c.base, c.modelsPath)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
+	endpoint := joinURL(c.base, c.modelsPath)
+
+	// OpenAI returns a single page, but proxies may paginate with the
+	// has_more/last_id cursor used by OpenAI's other list endpoints.
+	var models []Model
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 0; page < maxModelPages; page++ {
+		pageURL := endpoint
+		if cursor != "" {
+			var err error
+			if pageURL, err = withQuery(endpoint, "after", cursor); err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		c.setHeaders(req)
+
+		var raw json.RawMessage
+		if err := doJSON(ctx, c.http, req, nil, &raw); err != nil {
+			return nil, err
+		}
+		resp, err := decodeModelsPage(raw, c.modelsResponseKey, c.modelIDField)
+		if err != nil {
+			return nil, fmt.Errorf("%s models response: %w", c.name, err)
+		}
+
+		last := ""
+		for _, m := range resp.Data {
+			id := strings.TrimSpace(m.ID)
+			if id == "" {
+				continue
+			}
+			last = id
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			models = append(models, Model{
+				ID:              id,
+				DisplayName:     id,
+				ContextLength:   m.ContextLength,
+				MaxOutputTokens: m.TopProvider.MaxCompletionTokens,
+				PromptPrice:     parsePrice(m.Pricing.Prompt),
+				CompletionPrice: parsePrice(m.Pricing.Completion),
+			})
+		}
+
+		next := strings.TrimSpace(resp.LastID)
+		if next == "" {
+			next = last
+		}
+		if !resp.HasMore || next == "" || next == cursor {
+			break
+		}
+		cursor = next
 	}
-	c.setHeaders(req)
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// modelsPage is one page of a models list, normalized to the OpenAI shape.
+type modelsPage struct {
+	Data    []modelEntry
+	HasMore bool
+	LastID  string
+}
+
+type modelEntry struct {
+	ID            string `json:"-"`
+	ContextLength int    `json:"context_length"`
+	Pricing       struct {
+		Prompt     any `json:"prompt"`
+		Completion any `json:"completion"`
+	} `json:"pricing"`
+	TopProvider struct {
+		MaxCompletionTokens int `json:"max_completion_tokens"`
+	} `json:"top_provider"`
+}
 
-	var resp struct {
-		Data []struct {
-			ID string `json:"id"`
-		} `json:"data"`
+// decodeModelsPage reads a models list from raw. key is a dot-separated path
+// to the list; when empty the list is the body itself if it is an array,
+// otherwise its "data" or "models" field. Entries may be objects, whose ID is
+// read from idField (default "id", falling back to "name"), or bare strings.
+func decodeModelsPage(raw []byte, key, idField string) (modelsPage, error) {
+	var page modelsPage
+	var list []json.RawMessage
+	if key == "" && json.Unmarshal(raw, &list) == nil {
+		return page, decodeModelEntries(&page, list, idField)
 	}
-	if err := doJSON(ctx, c.http, req, nil, &resp); err != nil {
-		return nil, err
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return page, fmt.Errorf("expected a JSON object or array: %w", err)
 	}
+	_ = json.Unmarshal(body["has_more"], &page.HasMore)
+	_ = json.Unmarshal(body["last_id"], &page.LastID)
 
-	models := make([]Model, 0, len(resp.Data))
-	for _, m := range resp.Data {
-		id := strings.TrimSpace(m.ID)
-		if id == "" {
+	var field json.RawMessage
+	if key != "" {
+		parts := strings.Split(key, ".")
+		current := body
+		for i, part := range parts {
+			value, ok := current[part]
+			if !ok {
+				return page, fmt.Errorf("no %q field", key)
+			}
+			if i == len(parts)-1 {
+				field = value
+				break
+			}
+			if err := json.Unmarshal(value, &current); err != nil {
+				return page, fmt.Errorf("%q is not an object", strings.Join(parts[:i+1], "."))
+			}
+		}
+	} else {
+		for _, name := range []string{"data", "models"} {
+			if value, ok := body[name]; ok {
+				field = value
+				break
+			}
+		}
+		if field == nil {
+			return page, fmt.Errorf("no \"data\" or \"models\" list; set models_response_key")
+		}
+	}
+	if err := json.Unmarshal(field, &list); err != nil {
+		return page, fmt.Errorf("models list is not an array: %w", err)
+	}
+	return page, decodeModelEntries(&page, list, idField)
+}
+
+func decodeModelEntries(page *modelsPage, list []json.RawMessage, idField string) error {
+	for _, item := range list {
+		var id string
+		if json.Unmarshal(item, &id) == nil {
+			page.Data = append(page.Data, modelEntry{ID: id})
 			continue
 		}
-		models = append(models, Model{ID: id, DisplayName: id})
+		var entry modelEntry
+		var fields map[string]any
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return fmt.Errorf("model entry is neither an object nor a string")
+		}
+		_ = json.Unmarshal(item, &entry)
+		names := []string{"id", "name"}
+		if idField != "" {
+			names = []string{idField}
+		}
+		for _, name := range names {
+			if v, ok := fields[name].(string); ok && strings.TrimSpace(v) != "" {
+				entry.ID = v
+				break
+			}
+		}
+		page.Data = append(page.Data, entry)
 	}
-	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
-	return models, nil
+	return nil
 }
 
 func (c *openAICompatibleClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse, error) {
@@ -101,10 +265,48 @@ func (c *openAICompatibleClient) Ask(ctx context.Context, reqBody AskRequest) (A
 	if c.requiresAPIKey() && c.apiKey == "" {
 		return AskResponse{}, fmt.Errorf("API key not configured for %s", c.name)
 	}
-	url := joinURL(c.base, c.chatPath)
-	resp, err := c.askWithPayload(ctx, url, reqBody, true)
-	if err != nil && reqBody.ExpectJSON && responseFormatLikelyUnsupported(err) {
-		resp, err = c.askWithPayload(ctx, url, reqBody, false)
+	return c.askURL(ctx, joinURL(c.base, c.chatPath), reqBody)
+}
+
+// openAIPayloadOptions are the per-attempt choices askWithPayload makes that
+// a model may reject.
+type openAIPayloadOptions struct {
+	format          responseFormat
+	omitTemperature bool
+	maxTokensField  string
+}
+
+// maxPayloadAttempts bounds askURL retries: each response format plus one
+// retry per rejected parameter.
+const maxPayloadAttempts = 5
+
+// askURL sends a chat completion to url, downgrading from a JSON schema to a
+// plain JSON object and then to no response_format when the endpoint rejects
+// the requested format, and dropping parameters that reasoning models reject.
+func (c *openAICompatibleClient) askURL(ctx context.Context, url string, reqBody AskRequest) (AskResponse, error) {
+	var (
+		resp openAIChatResponse
+		err  error
+	)
+	formats := c.capabilities.responseFormats(c.name, reqBody)
+	opts := openAIPayloadOptions{
+		omitTemperature: isReasoningModel(reqBody.Model),
+		maxTokensField:  c.maxTokensField,
+	}
+	for attempt := 0; attempt < maxPayloadAttempts; attempt++ {
+		opts.format = formats[0]
+		resp, err = c.askWithPayload(ctx, url, reqBody, opts)
+		if err == nil {
+			break
+		}
+		if len(formats) > 1 && responseFormatLikelyUnsupported(err) {
+			c.capabilities.rejectFormat(c.name, reqBody.Model, formats[0])
+			formats = formats[1:]
+			continue
+		}
+		if !unsupportedParamRetry(err, reqBody, &opts) {
+			break
+		}
 	}
 	if err != nil {
 		return AskResponse{}, err
@@ -113,60 +315,159 @@ func (c *openAICompatibleClient) Ask(ctx context.Context, reqBody AskRequest) (A
 		return AskResponse{}, fmt.Errorf("no choices returned by %s", c.name)
 	}
 
-	text, err := extractMessageContent(resp.Choices[0].Message.Content)
+	text, err := extractMessageContent(resp.Choices[0].Message.Content, resp.Choices[0].Message.Refusal)
 	if err != nil {
 		return AskResponse{}, fmt.Errorf("decode %s response content: %w", c.name, err)
 	}
-	return AskResponse{Text: text}, nil
+	reason := resp.Choices[0].FinishReason
+	return AskResponse{Text: text, Usage: Usage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}.withTotal(), FinishReason: reason, Truncated: reason == "length"}, nil
 }
 
-func (c *openAICompatibleClient) askWithPayload(ctx context.Context, url string, reqBody AskRequest, includeResponseFormat bool) (struct {
+// parsePrice reads a per-token price that may be encoded as a JSON number or
+// a numeric string. Missing or malformed values yield 0.
+func parsePrice(v any) float64 {
+	switch price := v.(type) {
+	case float64:
+		return price
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	default:
+		return 0
+	}
+}
+
+type openAIChatResponse struct {
 	Choices []struct {
 		Message struct {
-			Content any `json:"content"`
+			Content any    `json:"content"`
+			Refusal string `json:"refusal"`
 		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
-}, error) {
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (c *openAICompatibleClient) askWithPayload(ctx context.Context, url string, reqBody AskRequest, opts openAIPayloadOptions) (openAIChatResponse, error) {
 	req, err := http.NewRequest(http.MethodPost, url, nil)
 	if err != nil {
-		return struct {
-			Choices []struct {
-				Message struct {
-					Content any `json:"content"`
-				} `json:"message"`
-			} `json:"choices"`
-		}{}, fmt.Errorf("build request: %w", err)
+		return openAIChatResponse{}, fmt.Errorf("build request: %w", err)
 	}
 	c.setHeaders(req)
 
+	conversation := conversationMessages(reqBody)
+	messages := []map[string]any{{"role": "system", "content": reqBody.Prompt}}
+	for i, m := range conversation {
+		var content any = m.Content
+		if i == len(conversation)-1 && len(reqBody.Images) > 0 {
+			parts := []map[string]any{{"type": "text", "text": m.Content}}
+			for _, img := range reqBody.Images {
+				parts = append(parts, map[string]any{"type": "image_url", "image_url": map[string]string{"url": img.DataURL()}})
+			}
+			content = parts
+		}
+		messages = append(messages, map[string]any{"role": m.Role, "content": content})
+	}
+
 	payload := map[string]any{
-		"model": reqBody.Model,
-		"messages": []map[string]string{
-			{"role": "system", "content": reqBody.Prompt},
-			{"role": "user", "content": reqBody.Question},
-		},
-		"temperature": 0.2,
-	}
-	if reqBody.ExpectJSON && includeResponseFormat {
+		"model":    reqBody.Model,
+		"messages": messages,
+	}
+	if reqBody.Temperature != nil && !opts.omitTemperature {
+		payload["temperature"] = *reqBody.Temperature
+	}
+	if reqBody.MaxTokens > 0 {
+		payload[opts.maxTokensField] = reqBody.MaxTokens
+	}
+	switch opts.format {
+	case formatJSONSchema:
+		payload["response_format"] = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   reqBody.JSONSchema.Name,
+				"schema": reqBody.JSONSchema.Schema,
+				"strict": true,
+			},
+		}
+	case formatJSONObject:
 		payload["response_format"] = map[string]string{"type": "json_object"}
 	}
+	mergeParams(payload, c.extraBody)
+	mergeParams(payload, reqBody.Extra)
 
-	var resp struct {
-		Choices []struct {
-			Message struct {
-				Content any `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
+	var resp openAIChatResponse
 	if err := doJSON(ctx, c.http, req, payload, &resp); err != nil {
 		return resp, err
 	}
 	return resp, nil
 }
 
+// isReasoningModel reports whether model looks like an OpenAI reasoning model
+// (o1, o3, o4, gpt-5), which rejects a custom temperature. Proxy-style names
+// such as "openai/o3-mini" are matched on the part after the last slash.
+func isReasoningModel(model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	for _, prefix := range []string{"o1", "o3", "o4", "gpt-5"} {
+		if model == prefix || strings.HasPrefix(model, prefix+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// unsupportedParamRetry adjusts opts after a 400 response that rejects
+// temperature or max_tokens, reporting whether the request should be retried.
+func unsupportedParamRetry(err error, reqBody AskRequest, opts *openAIPayloadOptions) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	body := strings.ToLower(httpErr.Body)
+	if !strings.Contains(body, "unsupported") && !strings.Contains(body, "not supported") && !strings.Contains(body, "does not support") {
+		return false
+	}
+	switch {
+	case reqBody.Temperature != nil && !opts.omitTemperature && strings.Contains(body, "temperature"):
+		opts.omitTemperature = true
+		return true
+	case reqBody.MaxTokens > 0 && opts.maxTokensField == "max_tokens" && strings.Contains(body, "max_tokens"):
+		opts.maxTokensField = "max_completion_tokens"
+		return true
+	}
+	return false
+}
+
+// setHeaders authenticates req and adds the configured headers. A resolved
+// key is sent whenever one is configured, including for custom providers that
+// do not require one, since the key can only reach a proxy this way. With an
+// auth query parameter the key is added to the URL instead of the auth header,
+// and Basic credentials are set last so they win over an Authorization key.
 func (c *openAICompatibleClient) setHeaders(req *http.Request) {
-	if c.requiresAPIKey() && c.apiKey != "" {
-		req.Header.Set(c.authHeader, c.authPrefix+c.apiKey)
+	if c.apiKey != "" {
+		if c.authQueryParam != "" {
+			query := req.URL.Query()
+			query.Set(c.authQueryParam, c.apiKey)
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.Header.Set(c.authHeader, c.authPrefix+c.apiKey)
+		}
+	}
+	if c.basicUser != "" {
+		req.SetBasicAuth(c.basicUser, c.basicPassword)
 	}
 	for k, v := range c.headers {
 		if strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
@@ -180,9 +481,20 @@ func (c *openAICompatibleClient) requiresAPIKey() bool {
 	return c.requireAPIKey
 }
 
-func extractMessageContent(content any) (string, error) {
+// extractMessageContent returns the text of a chat completion message.
+// Models that decline a request leave content empty and explain why in
+// refusal, which is returned as the text so the user sees the reason.
+func extractMessageContent(content any, refusal string) (string, error) {
 	switch value := content.(type) {
+	case nil:
+		if refusal = strings.TrimSpace(refusal); refusal != "" {
+			return refusal, nil
+		}
+		return "", fmt.Errorf("message had no content")
 	case string:
+		if strings.TrimSpace(value) == "" && strings.TrimSpace(refusal) != "" {
+			return strings.TrimSpace(refusal), nil
+		}
 		return strings.TrimSpace(value), nil
 	case []any:
 		parts := make([]string, 0, len(value))
@@ -192,10 +504,16 @@ func extractMessageContent(content any) (string, error) {
 				continue
 			}
 			text, _ := obj["text"].(string)
+			if obj["type"] == "refusal" {
+				text, _ = obj["refusal"].(string)
+			}
 			if strings.TrimSpace(text) != "" {
 				parts = append(parts, text)
 			}
 		}
+		if len(parts) == 0 && strings.TrimSpace(refusal) != "" {
+			return strings.TrimSpace(refusal), nil
+		}
 		if len(parts) == 0 {
 			return "", fmt.Errorf("array content had no text parts")
 		}
@@ -3,8 +3,10 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -132,3 +134,563 @@ func TestOpenAICompatible_CustomProviderWithoutAPIKey(t *testing.T) {
 		t.Fatalf("Authorization header should be empty for custom provider without API key, got %q", gotAuth)
 	}
 }
+
+func TestOpenAICompatible_SendsHistoryBeforeQuestion(t *testing.T) {
+	var roles []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		for _, m := range payload.Messages {
+			roles = append(roles, m.Role+":"+m.Content)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{
+				"message": map[string]any{"content": "ok"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+
+	_, err = client.Ask(context.Background(), AskRequest{
+		Model:    "m",
+		Prompt:   "sys",
+		Question: "second",
+		History: []Message{
+			{Role: RoleUser, Content: "first"},
+			{Role: RoleAssistant, Content: "reply"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	want := []string{"system:sys", "user:first", "assistant:reply", "user:second"}
+	if len(roles) != len(want) {
+		t.Fatalf("messages = %v, want %v", roles, want)
+	}
+	for i := range want {
+		if roles[i] != want[i] {
+			t.Fatalf("messages[%d] = %q, want %q", i, roles[i], want[i])
+		}
+	}
+}
+
+func TestOpenAICompatible_TemperatureOmittedUnlessSet(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "ok"}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	temp := 1.1
+	for _, req := range []AskRequest{
+		{Model: "m", Prompt: "p", Question: "q"},
+		{Model: "m", Prompt: "p", Question: "q", Temperature: &temp},
+	} {
+		if _, err := client.Ask(context.Background(), req); err != nil {
+			t.Fatalf("Ask error = %v", err)
+		}
+	}
+	if _, ok := payloads[0]["temperature"]; ok {
+		t.Fatalf("temperature should be omitted, payload = %v", payloads[0])
+	}
+	if payloads[1]["temperature"] != 1.1 {
+		t.Fatalf("temperature = %v, want 1.1", payloads[1]["temperature"])
+	}
+}
+
+func TestOpenAICompatible_MaxTokensField(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "ok"}}},
+		})
+	}))
+	defer server.Close()
+
+	proxy, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	openai := newOpenAIClient(ClientOptions{APIKey: "k", BaseURL: server.URL})
+	req := AskRequest{Model: "m", Prompt: "p", Question: "q", MaxTokens: 64}
+	for _, client := range []Client{proxy, openai} {
+		if _, err := client.Ask(context.Background(), req); err != nil {
+			t.Fatalf("Ask error = %v", err)
+		}
+	}
+	if payloads[0]["max_tokens"] != float64(64) {
+		t.Fatalf("proxy max_tokens = %v, want 64", payloads[0]["max_tokens"])
+	}
+	if payloads[1]["max_completion_tokens"] != float64(64) {
+		t.Fatalf("openai max_completion_tokens = %v, want 64", payloads[1]["max_completion_tokens"])
+	}
+	if _, ok := payloads[1]["max_tokens"]; ok {
+		t.Fatalf("openai payload should not include max_tokens: %v", payloads[1])
+	}
+}
+
+func TestOpenAICompatible_JSONSchemaDegrades(t *testing.T) {
+	var formats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			ResponseFormat struct {
+				Type       string `json:"type"`
+				JSONSchema struct {
+					Name   string         `json:"name"`
+					Schema map[string]any `json:"schema"`
+					Strict bool           `json:"strict"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		formats = append(formats, payload.ResponseFormat.Type)
+		if payload.ResponseFormat.Type == "json_schema" {
+			if payload.ResponseFormat.JSONSchema.Name != "reply" || !payload.ResponseFormat.JSONSchema.Strict || payload.ResponseFormat.JSONSchema.Schema["type"] != "object" {
+				t.Errorf("json_schema = %+v", payload.ResponseFormat.JSONSchema)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Invalid parameter: 'response_format' of type 'json_schema' is not supported with this model."}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"ok","command":""}`}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	_, err = client.Ask(context.Background(), AskRequest{
+		Model:      "m",
+		Prompt:     "system",
+		Question:   "hello",
+		ExpectJSON: true,
+		JSONSchema: &JSONSchema{Name: "reply", Schema: map[string]any{"type": "object"}},
+	})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if len(formats) != 2 || formats[0] != "json_schema" || formats[1] != "json_object" {
+		t.Fatalf("formats = %v", formats)
+	}
+
+	// The rejection is remembered, so the next call goes straight to json_object.
+	formats = nil
+	_, err = client.Ask(context.Background(), AskRequest{
+		Model:      "m",
+		Prompt:     "system",
+		Question:   "again",
+		ExpectJSON: true,
+		JSONSchema: &JSONSchema{Name: "reply", Schema: map[string]any{"type": "object"}},
+	})
+	if err != nil {
+		t.Fatalf("second Ask error = %v", err)
+	}
+	if len(formats) != 1 || formats[0] != "json_object" {
+		t.Fatalf("second call formats = %v, want a single json_object request", formats)
+	}
+}
+
+func TestGeminiSchemaConversion(t *testing.T) {
+	got := geminiSchema(map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"answer": map[string]any{"type": "string"},
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required": []string{"answer"},
+	})
+	props := got["properties"].(map[string]any)
+	tags := props["tags"].(map[string]any)
+	if got["type"] != "OBJECT" || props["answer"].(map[string]any)["type"] != "STRING" || tags["items"].(map[string]any)["type"] != "STRING" {
+		t.Fatalf("schema = %#v", got)
+	}
+	if _, ok := got["additionalProperties"]; ok {
+		t.Fatalf("additionalProperties should be dropped: %#v", got)
+	}
+}
+
+func TestImagesInPayloads(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nfake")
+	var openAIContent, geminiParts any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct {
+				Content any `json:"content"`
+			} `json:"messages"`
+			Contents []struct {
+				Parts any `json:"parts"`
+			} `json:"contents"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if r.URL.Path == "/chat/completions" {
+			openAIContent = payload.Messages[len(payload.Messages)-1].Content
+			_ = json.NewEncoder(w).Encode(map[string]any{"choices": []map[string]any{{"message": map[string]any{"content": "ok"}}}})
+			return
+		}
+		geminiParts = payload.Contents[len(payload.Contents)-1].Parts
+		_ = json.NewEncoder(w).Encode(map[string]any{"candidates": []map[string]any{{"content": map[string]any{"parts": []map[string]any{{"text": "ok"}}}}}})
+	}))
+	defer server.Close()
+
+	req := AskRequest{Model: "m", Prompt: "system", Question: "what is this?", Images: []ImageInput{{MIMEType: "image/png", Data: png}}}
+	openai, _ := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{BaseURL: server.URL})
+	if _, err := openai.Ask(context.Background(), req); err != nil {
+		t.Fatalf("openai Ask error = %v", err)
+	}
+	parts, _ := openAIContent.([]any)
+	if len(parts) != 2 {
+		t.Fatalf("openai content = %#v", openAIContent)
+	}
+	imagePart := parts[1].(map[string]any)
+	if imagePart["type"] != "image_url" || imagePart["image_url"].(map[string]any)["url"] != "data:image/png;base64,iVBORw0KGgpmYWtl" {
+		t.Fatalf("openai image part = %#v", imagePart)
+	}
+
+	gemini, _ := New("gemini", ClientOptions{APIKey: "g", BaseURL: server.URL})
+	if _, err := gemini.Ask(context.Background(), req); err != nil {
+		t.Fatalf("gemini Ask error = %v", err)
+	}
+	gparts, _ := geminiParts.([]any)
+	if len(gparts) != 2 || gparts[1].(map[string]any)["inlineData"].(map[string]any)["mimeType"] != "image/png" {
+		t.Fatalf("gemini parts = %#v", geminiParts)
+	}
+
+	anthropic, _ := New("anthropic", ClientOptions{APIKey: "a", BaseURL: server.URL})
+	if _, err := anthropic.Ask(context.Background(), req); err == nil || !strings.Contains(err.Error(), "does not support image input") {
+		t.Fatalf("anthropic err = %v", err)
+	}
+}
+
+func TestOpenAICompatible_ReasoningModelParams(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+		switch {
+		case payload["temperature"] != nil:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Unsupported value: 'temperature' does not support 0.7 with this model.","code":"unsupported_value"}}`))
+		case payload["max_tokens"] != nil:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Unsupported parameter: 'max_tokens' is not supported with this model. Use 'max_completion_tokens' instead.","code":"unsupported_parameter"}}`))
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"ok","command":""}`}}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	temp := 0.7
+	ask := func(model string) {
+		t.Helper()
+		payloads = nil
+		if _, err := client.Ask(context.Background(), AskRequest{Model: model, Prompt: "system", Question: "hello", Temperature: &temp, MaxTokens: 100}); err != nil {
+			t.Fatalf("Ask(%s) error = %v", model, err)
+		}
+	}
+
+	ask("openai/o3-mini")
+	if len(payloads) != 2 || payloads[0]["temperature"] != nil || payloads[1]["max_completion_tokens"] != float64(100) {
+		t.Fatalf("o3-mini payloads = %v", payloads)
+	}
+
+	ask("custom-reasoner")
+	if len(payloads) != 3 || payloads[0]["temperature"] != 0.7 || payloads[2]["temperature"] != nil || payloads[2]["max_completion_tokens"] != float64(100) {
+		t.Fatalf("custom-reasoner payloads = %v", payloads)
+	}
+}
+
+func TestIsReasoningModel(t *testing.T) {
+	for model, want := range map[string]bool{
+		"o1": true, "o3-mini": true, "o4-mini-2025-04-16": true, "gpt-5": true, "openai/gpt-5-mini": true,
+		"gpt-4o": false, "o1x": false, "gpt-50": false, "llama-3": false,
+	} {
+		if got := isReasoningModel(model); got != want {
+			t.Errorf("isReasoningModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestOpenAICompatible_ExtraBody(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"answer":"ok","command":""}`}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{
+		BaseURL:   server.URL,
+		ExtraBody: map[string]any{"reasoning_effort": "low", "top_p": 0.5},
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	if _, err := client.Ask(context.Background(), AskRequest{Model: "m", Prompt: "sys", Question: "q", Extra: map[string]any{"top_p": 0.9}}); err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if payload["reasoning_effort"] != "low" || payload["top_p"] != 0.9 || payload["model"] != "m" {
+		t.Fatalf("payload = %v, want config extra_body with the request override", payload)
+	}
+
+	if _, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{
+		BaseURL:   server.URL,
+		ExtraBody: map[string]any{"model": "other"},
+	}); err == nil {
+		t.Fatal("expected extra_body to be rejected when it sets model")
+	}
+}
+
+func TestOpenAICompatible_AuthQueryParam(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("api-key"); got != "sk/secret+1" {
+			t.Errorf("%s api-key = %q", r.URL.Path, got)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("%s sent Authorization header alongside the query key", r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/models":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "m"}}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"choices": []map[string]any{{"message": map[string]any{"content": "ok"}}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	var trace strings.Builder
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy", AuthQueryParam: "api-key"}, ClientOptions{
+		APIKey:  "sk/secret+1",
+		BaseURL: server.URL,
+		Logger:  slog.New(slog.NewTextHandler(&trace, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels error = %v", err)
+	}
+	if _, err := client.Ask(context.Background(), AskRequest{Model: "m", Prompt: "p", Question: "q"}); err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if strings.Contains(trace.String(), "secret") {
+		t.Fatalf("trace leaked the query key:\n%s", trace.String())
+	}
+}
+
+func TestOpenAICompatible_CustomProviderSendsOptionalKey(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "m"}}})
+	}))
+	defer server.Close()
+
+	for _, key := range []string{"sk-proxy", ""} {
+		client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy"}, ClientOptions{APIKey: key, BaseURL: server.URL})
+		if err != nil {
+			t.Fatalf("NewOpenAICompatible error = %v", err)
+		}
+		if _, err := client.ListModels(context.Background()); err != nil {
+			t.Fatalf("ListModels error = %v", err)
+		}
+	}
+	if len(got) != 2 || got[0] != "Bearer sk-proxy" || got[1] != "" {
+		t.Fatalf("Authorization headers = %q, want the key only when one is configured", got)
+	}
+}
+
+func TestRequestErrorRedactsQueryKey(t *testing.T) {
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "proxy", AuthQueryParam: "code"}, ClientOptions{
+		APIKey:  "hunter2",
+		BaseURL: "http://127.0.0.1:1",
+		Retries: -1,
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	_, err = client.ListModels(context.Background())
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestOpenAICompatible_BasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "gateway" || pass != "p:ss" {
+			t.Errorf("basic auth = %q %q %v", user, pass, ok)
+		}
+		if got := r.Header.Get("X-Api-Key"); got != "k" {
+			t.Errorf("X-Api-Key = %q", got)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "m"}}})
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{
+		Name:          "gateway",
+		AuthHeader:    "X-Api-Key",
+		AuthPrefix:    " ",
+		BasicUser:     "gateway",
+		BasicPassword: "p:ss",
+	}, ClientOptions{APIKey: "k", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels error = %v", err)
+	}
+}
+
+func TestOpenAICompatible_ModelsResponseShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		settings OpenAICompatibleSettings
+		want     []string
+	}{
+		{name: "openai", body: `{"data":[{"id":"b"},{"id":"a"}]}`, want: []string{"a", "b"}},
+		{name: "models key", body: `{"models":[{"id":"m1"}]}`, want: []string{"m1"}},
+		{name: "bare array", body: `[{"id":"x"},"y"]`, want: []string{"x", "y"}},
+		{name: "name fallback", body: `{"models":[{"name":"llama3"}]}`, want: []string{"llama3"}},
+		{name: "strings", body: `{"data":["s1","s2"]}`, want: []string{"s1", "s2"}},
+		{
+			name:     "mapped",
+			body:     `{"result":{"items":[{"model":"m","id":"ignored"}]}}`,
+			settings: OpenAICompatibleSettings{ModelsResponseKey: "result.items", ModelIDField: "model"},
+			want:     []string{"m"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			tt.settings.Name = "gateway"
+			client, err := NewOpenAICompatible(tt.settings, ClientOptions{BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("NewOpenAICompatible error = %v", err)
+			}
+			models, err := client.ListModels(context.Background())
+			if err != nil {
+				t.Fatalf("ListModels error = %v", err)
+			}
+			var got []string
+			for _, m := range models {
+				got = append(got, m.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("models = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatible_ModelsResponseKeyMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatible(OpenAICompatibleSettings{Name: "gateway"}, ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatible error = %v", err)
+	}
+	if _, err := client.ListModels(context.Background()); err == nil || !strings.Contains(err.Error(), "models_response_key") {
+		t.Fatalf("err = %v, want a hint about models_response_key", err)
+	}
+}
+
+func TestExtractMessageContentRefusal(t *testing.T) {
+	tests := []struct {
+		name    string
+		content any
+		refusal string
+		want    string
+		wantErr bool
+	}{
+		{name: "string content", content: " hi ", want: "hi"},
+		{name: "null content with refusal", content: nil, refusal: "I can't help with that.", want: "I can't help with that."},
+		{name: "empty string with refusal", content: "", refusal: "I can't help with that.", want: "I can't help with that."},
+		{name: "refusal part", content: []any{map[string]any{"type": "refusal", "refusal": "No."}}, want: "No."},
+		{name: "text wins over refusal field", content: "answer", refusal: "No.", want: "answer"},
+		{name: "null content without refusal", content: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractMessageContent(tt.content, tt.refusal)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("extractMessageContent = %q, %v; want %q (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatible_RefusalBecomesText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":null,"refusal":"I can't assist with that request."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("openai", ClientOptions{BaseURL: server.URL, APIKey: "k"})
+	resp, err := client.Ask(context.Background(), AskRequest{Model: "gpt-4o", Prompt: "p", Question: "q"})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if resp.Text != "I can't assist with that request." {
+		t.Fatalf("Text = %q", resp.Text)
+	}
+}
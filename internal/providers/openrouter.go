@@ -1,11 +1,32 @@
 package providers
 
+import "strings"
+
+// openRouterHeaders are OpenRouter's app attribution headers. They are sent
+// only to OpenRouter, and entries in the provider's headers config replace
+// them.
+var openRouterHeaders = map[string]string{
+	"HTTP-Referer": "https://github.com/sasanktumpati/ask",
+	"X-Title":      "ask",
+}
+
 func newOpenRouterClient(opts ClientOptions) Client {
 	if opts.BaseURL == "" {
 		opts.BaseURL = "https://openrouter.ai/api/v1"
 	}
-	if opts.Headers == nil {
-		opts.Headers = map[string]string{}
+	headers := make(map[string]string, len(opts.Headers)+len(openRouterHeaders))
+	for k, v := range openRouterHeaders {
+		headers[k] = v
+	}
+	for k, v := range opts.Headers {
+		// Header names are case-insensitive; drop a default spelled differently.
+		for def := range openRouterHeaders {
+			if strings.EqualFold(def, k) {
+				delete(headers, def)
+			}
+		}
+		headers[k] = v
 	}
-	return newOpenAICompatibleClient(OpenAICompatibleSettings{Name: "openrouter", RequireAPIKey: true}, opts)
+	opts.Headers = headers
+	return newOpenAICompatibleClient(OpenAICompatibleSettings{Name: "openrouter"}, opts)
 }
@@ -2,29 +2,115 @@ package providers
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 )
 
-// Model describes a model option exposed by a provider.
+// ErrListUnsupported reports that a provider has no endpoint for listing
+// its models, so the model must be set by name.
+var ErrListUnsupported = errors.New("models cannot be listed")
+
+// Model describes a model option exposed by a provider. Metadata fields are
+// zero when the provider's model list does not report them; prices are USD
+// per token.
 type Model struct {
-	ID          string
-	DisplayName string
+	ID              string
+	DisplayName     string
+	ContextLength   int
+	MaxOutputTokens int
+	PromptPrice     float64
+	CompletionPrice float64
+}
+
+// Message is a single prior conversation turn.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
 }
 
+// Conversation roles accepted in AskRequest.History.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
 // AskRequest is the normalized prompt payload sent to a provider.
+// History holds earlier turns that are sent before Question. A nil
+// Temperature leaves sampling at the provider default, and a zero
+// MaxTokens uses the provider's built-in output limit. JSONSchema, when set
+// alongside ExpectJSON, asks providers that support it to enforce the schema.
+// Images are attached to Question; providers without vision support reject
+// requests that carry them. Extra holds additional payload fields merged into
+// the provider request; it cannot replace the model or conversation.
 type AskRequest struct {
-	Model      string
-	Prompt     string
-	Question   string
-	ExpectJSON bool
+	Model       string
+	Prompt      string
+	Question    string
+	History     []Message
+	Images      []ImageInput
+	ExpectJSON  bool
+	JSONSchema  *JSONSchema
+	Temperature *float64
+	MaxTokens   int
+	Extra       map[string]any
+}
+
+// ImageInput is an image attached to a question: either inline Data with its
+// MIMEType, or a remote URL.
+type ImageInput struct {
+	MIMEType string
+	Data     []byte
+	URL      string
+}
+
+// DataURL returns URL when set, otherwise the image as a base64 data URL.
+func (img ImageInput) DataURL() string {
+	if img.URL != "" {
+		return img.URL
+	}
+	return "data:" + img.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// JSONSchema is a named JSON Schema object used for structured output.
+type JSONSchema struct {
+	Name   string
+	Schema map[string]any
 }
 
 // AskResponse is the normalized text response returned by a provider.
+// FinishReason is the provider's raw stop reason, and Truncated reports that
+// it means the output hit the token limit.
 type AskResponse struct {
-	Text string
+	Text         string
+	Usage        Usage
+	FinishReason string
+	Truncated    bool
+}
+
+// Usage reports token counts for a single request. Counts are zero when the
+// provider does not report them.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// IsZero reports whether the provider returned no usage counts.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+func (u Usage) withTotal() Usage {
+	if u.TotalTokens == 0 {
+		u.TotalTokens = u.PromptTokens + u.CompletionTokens
+	}
+	return u
 }
 
 // Client is the provider client interface used by the CLI.
@@ -35,21 +121,69 @@ type Client interface {
 }
 
 // ClientOptions configures shared client settings for all providers.
+// Retries is the number of retries for transient failures (0 uses the
+// default of 2, negative disables retries). RetryBaseDelay seeds the
+// exponential backoff. APIVersion is used by providers with versioned
+// endpoints such as Azure OpenAI. Timeout bounds each HTTP request and
+// ProxyURL (http, https, socks5, or socks5h) routes traffic through a proxy
+// instead of the environment's; both apply only when HTTPClient is nil.
+// Logger, when non-nil, receives debug trace lines for every HTTP attempt with
+// credentials redacted. UserAgent replaces the default ask/<version>
+// User-Agent; a User-Agent in Headers wins over both.
 type ClientOptions struct {
-	APIKey     string
-	BaseURL    string
-	HTTPClient *http.Client
-	Headers    map[string]string
+	APIKey         string
+	BaseURL        string
+	HTTPClient     *http.Client
+	Timeout        time.Duration
+	ProxyURL       string
+	Logger         *slog.Logger
+	Headers        map[string]string
+	UserAgent      string
+	Retries        int
+	RetryBaseDelay time.Duration
+	APIVersion     string
+	// Organization and Project select the OpenAI-Organization and
+	// OpenAI-Project headers; only the openai client sends them.
+	Organization string
+	Project      string
+	// Region and CloudProject address providers hosted per cloud region
+	// (vertex, bedrock). TokenCommand prints a fresh bearer token when the
+	// configured one is missing or rejected.
+	Region       string
+	CloudProject string
+	TokenCommand string
+	// ExtraBody is merged into every request payload before
+	// AskRequest.Extra, which overrides it.
+	ExtraBody map[string]any
+	// CapabilityCache is the file where clients remember which structured
+	// output formats each model rejected; empty keeps that in memory only.
+	CapabilityCache string
+
+	// provider is set by New and NewOpenAICompatible so errors can name it.
+	provider string
 }
 
 // OpenAICompatibleSettings customizes behavior for OpenAI-compatible APIs.
+// MaxTokensField names the output-limit payload key (default: max_tokens).
+// AuthQueryParam, when set, sends the API key as that URL query parameter
+// instead of the auth header. BasicUser enables HTTP Basic auth with
+// BasicPassword; it replaces an API key sent in the Authorization header.
+// ModelsResponseKey is the dot-separated path to the list in the models
+// response and ModelIDField names the ID field of each entry; both default
+// to the OpenAI shape with fallbacks for common variants.
 type OpenAICompatibleSettings struct {
-	Name          string
-	ModelsPath    string
-	ChatPath      string
-	AuthHeader    string
-	AuthPrefix    string
-	RequireAPIKey bool
+	Name              string
+	ModelsPath        string
+	ChatPath          string
+	AuthHeader        string
+	AuthPrefix        string
+	AuthQueryParam    string
+	ModelsResponseKey string
+	ModelIDField      string
+	BasicUser         string
+	BasicPassword     string
+	MaxTokensField    string
+	RequireAPIKey     bool
 }
 
 // New returns a built-in provider client by name.
@@ -58,6 +192,10 @@ func New(name string, opts ClientOptions) (Client, error) {
 	if name == "" {
 		return nil, fmt.Errorf("provider name is required")
 	}
+	opts.provider = name
+	if err := validateExtraBody(opts.ExtraBody); err != nil {
+		return nil, err
+	}
 
 	switch name {
 	case "openai":
@@ -70,11 +208,45 @@ func New(name string, opts ClientOptions) (Client, error) {
 		return newOllamaClient(opts), nil
 	case "openrouter":
 		return newOpenRouterClient(opts), nil
+	case "azure":
+		return newAzureClient(opts), nil
+	case "groq":
+		return newGroqClient(opts), nil
+	case "deepseek":
+		return newDeepSeekClient(opts), nil
+	case "mistral":
+		return newMistralClient(opts), nil
+	case "vertex":
+		return newVertexClient(opts), nil
+	case "bedrock":
+		return newBedrockClient(opts), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider %q", name)
 	}
 }
 
+// apiKeyProviders lists the built-in providers whose clients refuse to send a
+// request without an API key. OpenAI-compatible clients read it when they are
+// built, so the clients and RequiresAPIKey cannot disagree. Ollama and custom
+// OpenAI-compatible providers may run without a key, Vertex AI can obtain its
+// token from a configured command, and Bedrock signs requests with AWS
+// credentials.
+var apiKeyProviders = map[string]bool{
+	"openai":     true,
+	"anthropic":  true,
+	"gemini":     true,
+	"openrouter": true,
+	"azure":      true,
+	"groq":       true,
+	"deepseek":   true,
+	"mistral":    true,
+}
+
+// RequiresAPIKey reports whether provider needs an API key to make requests.
+func RequiresAPIKey(provider string) bool {
+	return apiKeyProviders[normalize(provider)]
+}
+
 // NewOpenAICompatible returns a client for a custom OpenAI-compatible provider.
 func NewOpenAICompatible(settings OpenAICompatibleSettings, opts ClientOptions) (Client, error) {
 	settings.Name = normalize(settings.Name)
@@ -84,12 +256,16 @@ func NewOpenAICompatible(settings OpenAICompatibleSettings, opts ClientOptions)
 	if strings.TrimSpace(opts.BaseURL) == "" {
 		return nil, fmt.Errorf("base URL is required")
 	}
+	opts.provider = settings.Name
+	if err := validateExtraBody(opts.ExtraBody); err != nil {
+		return nil, err
+	}
 	return newOpenAICompatibleClient(settings, opts), nil
 }
 
 // SupportedProviders returns the built-in provider names.
 func SupportedProviders() []string {
-	providers := []string{"anthropic", "gemini", "ollama", "openai", "openrouter"}
+	providers := []string{"anthropic", "azure", "bedrock", "deepseek", "gemini", "groq", "mistral", "ollama", "openai", "openrouter", "vertex"}
 	sort.Strings(providers)
 	return providers
 }
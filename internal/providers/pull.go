@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrPullUnsupported reports that a provider cannot download models.
+var ErrPullUnsupported = errors.New("pulling models is not supported")
+
+// PullProgress is one status update while a model downloads. Completed and
+// Total are byte counts for the layer named by Digest and are zero for
+// status-only updates such as "pulling manifest".
+type PullProgress struct {
+	Status    string
+	Digest    string
+	Completed int64
+	Total     int64
+}
+
+// ModelPuller is implemented by clients that can download models to the
+// server they talk to.
+type ModelPuller interface {
+	PullModel(ctx context.Context, model string, progress func(PullProgress)) error
+}
+
+// PullModel downloads model through client, reporting each update to
+// progress. Clients that do not implement ModelPuller yield an error wrapping
+// ErrPullUnsupported.
+func PullModel(ctx context.Context, client Client, model string, progress func(PullProgress)) error {
+	puller, ok := client.(ModelPuller)
+	if !ok {
+		return fmt.Errorf("%s: %w", client.Name(), ErrPullUnsupported)
+	}
+	if strings.TrimSpace(model) == "" {
+		return fmt.Errorf("model is required")
+	}
+	if progress == nil {
+		progress = func(PullProgress) {}
+	}
+	return puller.PullModel(ctx, strings.TrimSpace(model), progress)
+}
+
+func (c *ollamaClient) PullModel(ctx context.Context, model string, progress func(PullProgress)) error {
+	req, err := http.NewRequest(http.MethodPost, joinURL(c.base, "/api/pull"), nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.http.stream(ctx, req, map[string]any{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Ollama streams one JSON object per line and ends with "success"; a
+	// failure mid-download arrives as an {"error": ...} line.
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var update struct {
+			Status    string `json:"status"`
+			Digest    string `json:"digest"`
+			Completed int64  `json:"completed"`
+			Total     int64  `json:"total"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(line), &update); err != nil {
+			return fmt.Errorf("decode pull progress: %w", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("pull %s: %s", model, update.Error)
+		}
+		progress(PullProgress{Status: update.Status, Digest: update.Digest, Completed: update.Completed, Total: update.Total})
+		if update.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read pull progress: %w", err)
+	}
+	return fmt.Errorf("pull %s: stream ended before success", model)
+}
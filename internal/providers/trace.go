@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxTraceBody bounds how much of each request/response body is traced.
+const maxTraceBody = 2000
+
+const redacted = "REDACTED"
+
+// traceRequest logs an outgoing request when tracing is enabled. Credentials
+// in headers and query parameters are redacted.
+func (r *requester) traceRequest(req *http.Request, payload []byte) {
+	if r.logger == nil {
+		return
+	}
+	r.logger.Debug("request",
+		"method", req.Method,
+		"url", redactURL(req.URL, r.secretParams...),
+		"headers", redactHeaders(req.Header),
+		"body", truncate(string(payload), maxTraceBody),
+	)
+}
+
+// traceResponse logs the outcome of a request when tracing is enabled.
+func (r *requester) traceResponse(resp *http.Response, body []byte, elapsed time.Duration, err error) {
+	if r.logger == nil {
+		return
+	}
+	if err != nil {
+		r.logger.Debug("response", "elapsed", elapsed.Round(time.Millisecond), "error", err)
+		return
+	}
+	r.logger.Debug("response",
+		"status", resp.StatusCode,
+		"elapsed", elapsed.Round(time.Millisecond),
+		"body", truncate(string(body), maxTraceBody),
+	)
+}
+
+// redactHeaders renders headers as name=value pairs, hiding anything that
+// may carry a credential.
+func redactHeaders(header http.Header) string {
+	parts := make([]string, 0, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ",")
+		if isSecretName(name) {
+			value = redacted
+		}
+		parts = append(parts, name+"="+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// redactURL renders u without user info, hiding query parameters that look
+// like credentials or are listed in secretParams.
+func redactURL(u *url.URL, secretParams ...string) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	for name := range query {
+		if isSecretName(name) || containsFold(secretParams, name) {
+			query.Set(name, redacted)
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretName reports whether a header or query parameter name looks like it
+// carries a credential.
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range []string{"auth", "key", "token", "secret", "signature", "cookie"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
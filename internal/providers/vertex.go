@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const defaultVertexRegion = "us-central1"
+
+// vertexClient talks to Gemini models on Vertex AI. Requests use the Gemini
+// payload shape but are addressed by project and region and authenticated
+// with an OAuth bearer token, which can be refreshed through TokenCommand
+// (for example `gcloud auth print-access-token`).
+type vertexClient struct {
+	gemini       *geminiClient
+	base         string
+	project      string
+	region       string
+	tokenCommand string
+
+	mu    sync.Mutex
+	token string
+}
+
+func newVertexClient(opts ClientOptions) Client {
+	region := strings.TrimSpace(opts.Region)
+	if region == "" {
+		region = defaultVertexRegion
+	}
+	base := strings.TrimSpace(opts.BaseURL)
+	if base == "" {
+		base = vertexBaseURL(region)
+	}
+	return &vertexClient{
+		gemini:       newGeminiClient(opts),
+		base:         strings.TrimRight(base, "/"),
+		project:      strings.TrimSpace(opts.CloudProject),
+		region:       region,
+		tokenCommand: strings.TrimSpace(opts.TokenCommand),
+		token:        strings.TrimSpace(opts.APIKey),
+	}
+}
+
+// vertexBaseURL returns the regional Vertex AI endpoint; the "global"
+// location has no regional host prefix.
+func vertexBaseURL(region string) string {
+	if region == "global" {
+		return "https://aiplatform.googleapis.com/v1"
+	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1", region)
+}
+
+func (c *vertexClient) Name() string { return "vertex" }
+
+func (c *vertexClient) ListModels(ctx context.Context) ([]Model, error) {
+	return nil, fmt.Errorf("vertex: %w; set one with `ask models set <model> --provider vertex` (for example gemini-2.0-flash)", ErrListUnsupported)
+}
+
+func (c *vertexClient) Ask(ctx context.Context, reqBody AskRequest) (AskResponse, error) {
+	if err := validateAskRequest(reqBody); err != nil {
+		return AskResponse{}, err
+	}
+	if c.project == "" {
+		return AskResponse{}, fmt.Errorf("project not configured for vertex; set providers.vertex.project or GOOGLE_CLOUD_PROJECT")
+	}
+	model := strings.TrimPrefix(strings.TrimSpace(reqBody.Model), "models/")
+	path := fmt.Sprintf("/projects/%s/locations/%s/publishers/google/models/%s:generateContent",
+		url.PathEscape(c.project), url.PathEscape(c.region), url.PathEscape(model))
+	endpoint := joinURL(c.base, path)
+
+	token, err := c.accessToken(ctx, false)
+	if err != nil {
+		return AskResponse{}, err
+	}
+	resp, err := c.gemini.generateContent(ctx, c.Name(), endpoint, reqBody, c.headers(token))
+	var httpErr *HTTPError
+	if err == nil || c.tokenCommand == "" || !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The token may have expired; fetch a fresh one and retry once.
+	if token, err = c.accessToken(ctx, true); err != nil {
+		return AskResponse{}, err
+	}
+	return c.gemini.generateContent(ctx, c.Name(), endpoint, reqBody, c.headers(token))
+}
+
+// accessToken returns the cached bearer token, running the token command
+// when there is none or refresh is set.
+func (c *vertexClient) accessToken(ctx context.Context, refresh bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && !refresh {
+		return c.token, nil
+	}
+	if c.tokenCommand == "" {
+		return "", fmt.Errorf("no access token for vertex; set VERTEX_ACCESS_TOKEN or providers.vertex.token_command (e.g. \"gcloud auth print-access-token\")")
+	}
+	token, err := runTokenCommand(ctx, c.tokenCommand)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
+
+func (c *vertexClient) headers(token string) func(*http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+		for k, v := range c.gemini.headers {
+			if strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+				continue
+			}
+			req.Header.Set(k, v)
+		}
+	}
+}
+
+// runTokenCommand runs command (split on whitespace, without a shell) and
+// returns its trimmed standard output.
+func runTokenCommand(ctx context.Context, command string) (string, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("token_command is empty")
+	}
+	out, err := exec.CommandContext(ctx, fields[0], fields[1:]...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("token_command %q: %w: %s", command, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("token_command %q: %w", command, err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("token_command %q printed no token", command)
+	}
+	return token, nil
+}
@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+)
+
+func TestVertexEndpointAndTokenRefresh(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/v1/projects/my-proj/locations/europe-west4/publishers/google/models/gemini-2.0-flash:generateContent"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		auths = append(auths, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if payload["contents"] == nil || payload["systemInstruction"] == nil {
+			t.Errorf("payload is not in Gemini shape: %v", payload)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{{"content": map[string]any{"parts": []map[string]any{{"text": `{"answer":"ok","command":""}`}}}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := New("vertex", ClientOptions{
+		APIKey:       "expired-token",
+		BaseURL:      server.URL + "/v1",
+		CloudProject: "my-proj",
+		Region:       "europe-west4",
+		TokenCommand: "echo fresh-token",
+		Retries:      -1,
+	})
+	if err != nil {
+		t.Fatalf("New(vertex) error = %v", err)
+	}
+	resp, err := client.Ask(context.Background(), AskRequest{Model: "gemini-2.0-flash", Prompt: "sys", Question: "q", ExpectJSON: true})
+	if err != nil {
+		t.Fatalf("Ask error = %v", err)
+	}
+	if resp.Text == "" || len(auths) != 2 || auths[0] != "Bearer expired-token" {
+		t.Fatalf("resp = %+v, auths = %v", resp, auths)
+	}
+}
+
+func TestVertexBaseURL(t *testing.T) {
+	if got := vertexBaseURL("us-central1"); got != "https://us-central1-aiplatform.googleapis.com/v1" {
+		t.Fatalf("regional base = %q", got)
+	}
+	if got := vertexBaseURL("global"); got != "https://aiplatform.googleapis.com/v1" {
+		t.Fatalf("global base = %q", got)
+	}
+}
@@ -0,0 +1,3 @@
+// Package redact masks likely secrets in text before it is sent to a
+// provider.
+package redact
@@ -0,0 +1,59 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces each redacted value.
+const Placeholder = "[REDACTED]"
+
+var (
+	pemBlock = regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]*PRIVATE KEY)-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+	// assignment matches name=value and "name": "value" pairs whose name
+	// suggests a credential; only the value group is masked.
+	assignment = regexp.MustCompile(`(?i)([A-Za-z0-9_.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)[A-Za-z0-9_.-]*["']?[ \t]*[:=][ \t]*)("[^"\n]+"|'[^'\n]+'|[^\s"',;]+)`)
+	tokens     = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                   // AWS access key IDs
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                         // OpenAI and Anthropic style keys
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),                                  // GitHub tokens
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),                                  // Slack tokens
+		regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),                                       // Google API keys
+		regexp.MustCompile(`(?i)\bbearer [A-Za-z0-9._~+/-]{20,}=*`),                           // Authorization header values
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), // JWTs
+	}
+)
+
+// Secrets masks values that look like credentials: private key PEM blocks,
+// credential-named assignments such as password=..., and well-known token
+// formats. It returns the masked text and the number of redactions. The
+// patterns are best-effort and favor false positives over leaks.
+func Secrets(s string) (string, int) {
+	count := 0
+	s = pemBlock.ReplaceAllStringFunc(s, func(match string) string {
+		count++
+		name := pemBlock.FindStringSubmatch(match)[1]
+		return "-----BEGIN " + name + "-----\n" + Placeholder + "\n-----END " + name + "-----"
+	})
+	s = assignment.ReplaceAllStringFunc(s, func(match string) string {
+		groups := assignment.FindStringSubmatch(match)
+		name, value := strings.ToLower(groups[1]), groups[2]
+		// Counts such as max_tokens and references such as $PASSWORD are not
+		// secrets themselves.
+		if strings.Contains(value, Placeholder) || strings.Contains(name, "tokens") || strings.HasPrefix(strings.Trim(value, `"'`), "$") {
+			return match
+		}
+		count++
+		if quote := value[0]; quote == '"' || quote == '\'' {
+			return groups[1] + string(quote) + Placeholder + string(quote)
+		}
+		return groups[1] + Placeholder
+	})
+	for _, re := range tokens {
+		s = re.ReplaceAllStringFunc(s, func(string) string {
+			count++
+			return Placeholder
+		})
+	}
+	return s, count
+}
@@ -10,9 +10,15 @@ import (
 
 var rendererCache sync.Map
 
-// Markdown renders text as terminal markdown when enabled.
-// It falls back to plain trimmed text if rendering fails.
-func Markdown(text string, width int, enabled bool) string {
+type rendererKey struct {
+	width int
+	theme string
+}
+
+// Markdown renders text as terminal markdown when enabled, using theme (see
+// ValidTheme; "" or an invalid name selects auto). It falls back to plain
+// trimmed text if rendering fails.
+func Markdown(text string, width int, enabled bool, theme string) string {
 	clean := strings.TrimSpace(text)
 	if clean == "" {
 		return ""
@@ -24,7 +30,7 @@ func Markdown(text string, width int, enabled bool) string {
 		width = 100
 	}
 
-	renderer, err := getRenderer(width)
+	renderer, err := getRenderer(width, theme)
 	if err != nil {
 		return clean
 	}
@@ -35,20 +41,30 @@ func Markdown(text string, width int, enabled bool) string {
 	return out
 }
 
-func getRenderer(width int) (*glamour.TermRenderer, error) {
-	if cached, ok := rendererCache.Load(width); ok {
+func getRenderer(width int, theme string) (*glamour.TermRenderer, error) {
+	theme = strings.TrimSpace(theme)
+	if !ValidTheme(theme) {
+		theme = ThemeAuto
+	}
+	key := rendererKey{width: width, theme: theme}
+	if cached, ok := rendererCache.Load(key); ok {
 		if r, ok := cached.(*glamour.TermRenderer); ok {
 			return r, nil
 		}
 	}
 
+	style := glamour.WithAutoStyle()
+	if theme != "" && theme != ThemeAuto {
+		// WithStylePath accepts both built-in style names and JSON style files.
+		style = glamour.WithStylePath(theme)
+	}
 	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		style,
 		glamour.WithWordWrap(width),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("create markdown renderer: %w", err)
 	}
-	rendererCache.Store(width, renderer)
+	rendererCache.Store(key, renderer)
 	return renderer, nil
 }
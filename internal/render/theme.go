@@ -0,0 +1,24 @@
+package render
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// ThemeAuto selects a dark or light style based on the terminal background.
+const ThemeAuto = "auto"
+
+// Themes lists the built-in glamour styles accepted as a markdown theme.
+var Themes = []string{ThemeAuto, "ascii", "dark", "dracula", "light", "notty", "pink", "tokyo-night"}
+
+// ValidTheme reports whether theme is empty, a built-in style name, or the
+// path to an existing glamour JSON style file.
+func ValidTheme(theme string) bool {
+	theme = strings.TrimSpace(theme)
+	if theme == "" || slices.Contains(Themes, theme) {
+		return true
+	}
+	info, err := os.Stat(theme)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,24 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidTheme(t *testing.T) {
+	style := filepath.Join(t.TempDir(), "style.json")
+	if err := os.WriteFile(style, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("write style: %v", err)
+	}
+	for _, theme := range []string{"", "auto", "dracula", "notty", style} {
+		if !ValidTheme(theme) {
+			t.Fatalf("ValidTheme(%q) = false", theme)
+		}
+	}
+	for _, theme := range []string{"solarized", filepath.Dir(style), "Dark"} {
+		if ValidTheme(theme) {
+			t.Fatalf("ValidTheme(%q) = true", theme)
+		}
+	}
+}
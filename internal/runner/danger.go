@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DangerPatterns match shell commands that can destroy data or change the
+// system and therefore need an extra confirmation before running.
+var DangerPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(^|[\s;&|(])rm\s+(\S+\s+)*?(-[a-zA-Z]*[rRf][a-zA-Z]*|--recursive|--force)(\s|$)`),
+	regexp.MustCompile(`(^|[\s;&|(])dd\s`),
+	regexp.MustCompile(`(^|[\s;&|(])mkfs(\.\w+)?(\s|$)`),
+	regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`),
+	regexp.MustCompile(`(^|[\s;&|(])(sudo|doas)(\s|$)`),
+	regexp.MustCompile(`>\s*/dev/(sd|hd|nvme|disk|mmcblk)`),
+	regexp.MustCompile(`(^|[\s;&|(])(shutdown|reboot|halt|poweroff)(\s|$)`),
+	regexp.MustCompile(`(^|[\s;&|(])(curl|wget)\s[^|]*\|\s*(sudo\s+)?(ba|z|da)?sh(\s|$)`),
+}
+
+var dangerReasons = map[string]string{
+	DangerPatterns[0].String(): "recursive or forced delete (rm -r/-f)",
+	DangerPatterns[1].String(): "raw disk copy (dd)",
+	DangerPatterns[2].String(): "filesystem format (mkfs)",
+	DangerPatterns[3].String(): "fork bomb",
+	DangerPatterns[4].String(): "runs with elevated privileges",
+	DangerPatterns[5].String(): "writes directly to a block device",
+	DangerPatterns[6].String(): "shuts down or reboots the machine",
+	DangerPatterns[7].String(): "pipes a download into a shell",
+}
+
+// classifyCommand reports whether cmd matches any of DangerPatterns and, if
+// so, a short human-readable reason.
+func classifyCommand(cmd string) (risky bool, reason string) {
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "" {
+		return false, ""
+	}
+	for _, pattern := range DangerPatterns {
+		if !pattern.MatchString(cmd) {
+			continue
+		}
+		if reason, ok := dangerReasons[pattern.String()]; ok {
+			return true, reason
+		}
+		return true, "matches " + pattern.String()
+	}
+	return false, ""
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// editCommand writes cmd to a temp file, opens it in the user's editor and
+// returns the trimmed contents once the editor exits.
+func editCommand(cmd string, opts RunOptions) (string, error) {
+	f, err := os.CreateTemp("", "ask-command-*.sh")
+	if err != nil {
+		return "", fmt.Errorf("create command file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	if _, err := f.WriteString(cmd + "\n"); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write command file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write command file: %w", err)
+	}
+
+	editor := editorCommand(runtime.GOOS, path)
+	editor.Stdin = commandStdin(opts)
+	editor.Stdout = opts.Stdout
+	editor.Stderr = opts.Stderr
+	if err := editor.Run(); err != nil {
+		return "", fmt.Errorf("run editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read command file: %w", err)
+	}
+	return strings.TrimSpace(string(edited)), nil
+}
+
+// editorCommand builds the command that opens path in $VISUAL or $EDITOR.
+// The editor string may carry arguments (e.g. "code --wait"), so on POSIX it
+// runs through the user's shell; Windows defaults to notepad.
+func editorCommand(goos, path string) *exec.Cmd {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if goos == "windows" {
+		if editor == "" {
+			editor = "notepad"
+		}
+		fields := strings.Fields(editor)
+		return exec.Command(fields[0], append(fields[1:], path)...)
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	return exec.Command(resolveShell(goos, "", os.Getenv), "-lc", editor+" "+shellQuote(path))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
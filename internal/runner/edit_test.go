@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEditCommandReturnsSavedText(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell as the editor")
+	}
+	replacement := filepath.Join(t.TempDir(), "edited.sh")
+	if err := os.WriteFile(replacement, []byte("  echo edited\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SHELL", "sh")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "cp "+shellQuote(replacement))
+
+	var out bytes.Buffer
+	got, err := editCommand("echo original", RunOptions{Stdout: &out, Stderr: &out})
+	if err != nil {
+		t.Fatalf("editCommand error = %v (%s)", err, out.String())
+	}
+	if got != "echo edited" {
+		t.Fatalf("editCommand = %q", got)
+	}
+}
+
+func TestEditorCommandWindowsDefaultsToNotepad(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	cmd := editorCommand("windows", `C:\tmp\cmd.sh`)
+	if filepath.Base(cmd.Args[0]) != "notepad" || len(cmd.Args) != 2 || cmd.Args[1] != `C:\tmp\cmd.sh` {
+		t.Fatalf("args = %q", cmd.Args)
+	}
+}
+
+func TestPromptAndRunReturnsExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("SHELL", "sh")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true")
+
+	var out bytes.Buffer
+	status, err := PromptAndRun(RunOptions{Command: "exit 3", Stdout: &out, Stderr: &out, Edit: true})
+	if err != nil {
+		t.Fatalf("PromptAndRun error = %v", err)
+	}
+	if status != 3 {
+		t.Fatalf("status = %d, want 3", status)
+	}
+}
@@ -0,0 +1,157 @@
+package runner
+
+import "strings"
+
+// ANSI styles for the parts of a highlighted command.
+const (
+	styleCommand  = "\033[1;36m"
+	styleKeyword  = "\033[1;35m"
+	styleFlag     = "\033[33m"
+	styleString   = "\033[32m"
+	styleVariable = "\033[34m"
+	styleOperator = "\033[35m"
+	styleComment  = "\033[90m"
+	styleReset    = "\033[0m"
+)
+
+// shellKeywords are reserved words after which a command name is expected.
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"for": true, "while": true, "until": true, "do": true, "done": true,
+	"case": true, "esac": true, "in": true, "function": true, "time": true,
+}
+
+// Highlight returns cmd with ANSI colors for command names, keywords, flags,
+// quoted strings, variables, operators and comments. It is a lightweight
+// lexer for display only: it never changes the text between the escapes.
+func Highlight(cmd string) string {
+	var b strings.Builder
+	expectCommand := true
+	for i := 0; i < len(cmd); {
+		c := cmd[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if c == '\n' {
+				expectCommand = true
+			}
+			b.WriteByte(c)
+			i++
+		case c == '#' && (i == 0 || isShellSpace(cmd[i-1])):
+			end := strings.IndexByte(cmd[i:], '\n')
+			if end == -1 {
+				end = len(cmd) - i
+			}
+			paint(&b, styleComment, cmd[i:i+end])
+			i += end
+		case c == '\'' || c == '"':
+			end := closingQuote(cmd, i)
+			paint(&b, styleString, cmd[i:end])
+			expectCommand = false
+			i = end
+		case c == '$' && i+1 < len(cmd) && cmd[i+1] == '(':
+			paint(&b, styleOperator, "$(")
+			expectCommand = true
+			i += 2
+		case c == '$':
+			end := variableEnd(cmd, i)
+			paint(&b, styleVariable, cmd[i:end])
+			expectCommand = false
+			i = end
+		case strings.IndexByte("|&;<>()", c) >= 0:
+			end := i
+			for end < len(cmd) && strings.IndexByte("|&;<>()", cmd[end]) >= 0 {
+				end++
+			}
+			op := cmd[i:end]
+			paint(&b, styleOperator, op)
+			// Redirections are followed by a file name, not a command.
+			expectCommand = strings.ContainsAny(op, "|&;(")
+			i = end
+		default:
+			end := i
+			for end < len(cmd) && !isShellSpace(cmd[end]) && strings.IndexByte("|&;<>()'\"$", cmd[end]) < 0 {
+				end++
+			}
+			word := cmd[i:end]
+			switch {
+			case expectCommand && shellKeywords[word]:
+				paint(&b, styleKeyword, word)
+			case expectCommand && isAssignment(word):
+				b.WriteString(word)
+			case expectCommand:
+				paint(&b, styleCommand, word)
+				expectCommand = false
+			case strings.HasPrefix(word, "-"):
+				paint(&b, styleFlag, word)
+			default:
+				b.WriteString(word)
+			}
+			i = end
+		}
+	}
+	return b.String()
+}
+
+func paint(b *strings.Builder, style, text string) {
+	b.WriteString(style)
+	b.WriteString(text)
+	b.WriteString(styleReset)
+}
+
+func isShellSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// closingQuote returns the index just past the quote closing the one at
+// start, honoring backslash escapes inside double quotes. An unterminated
+// string runs to the end of cmd.
+func closingQuote(cmd string, start int) int {
+	quote := cmd[start]
+	for i := start + 1; i < len(cmd); i++ {
+		if quote == '"' && cmd[i] == '\\' {
+			i++
+			continue
+		}
+		if cmd[i] == quote {
+			return i + 1
+		}
+	}
+	return len(cmd)
+}
+
+// variableEnd returns the index just past the $NAME, ${...} or special
+// parameter starting at start.
+func variableEnd(cmd string, start int) int {
+	i := start + 1
+	if i < len(cmd) && cmd[i] == '{' {
+		if end := strings.IndexByte(cmd[i:], '}'); end != -1 {
+			return i + end + 1
+		}
+		return len(cmd)
+	}
+	if i < len(cmd) && strings.IndexByte("?@#*!$-0123456789", cmd[i]) >= 0 {
+		return i + 1
+	}
+	for i < len(cmd) && (cmd[i] == '_' || isAlnum(cmd[i])) {
+		i++
+	}
+	return i
+}
+
+// isAssignment reports whether word is a NAME=value environment prefix.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] != '_' && !isAlnum(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiEscape = regexp.MustCompile(`\033\[[0-9;]*m`)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string // styled fragments that must appear
+	}{
+		{`ls -la`, []string{styleCommand + "ls" + styleReset, styleFlag + "-la" + styleReset}},
+		{`grep -rn "TODO: fix" . | wc -l`, []string{
+			styleCommand + "grep" + styleReset,
+			styleString + `"TODO: fix"` + styleReset,
+			styleOperator + "|" + styleReset,
+			styleCommand + "wc" + styleReset,
+		}},
+		{`FOO=1 make build && echo $HOME`, []string{
+			styleCommand + "make" + styleReset,
+			styleOperator + "&&" + styleReset,
+			styleVariable + "$HOME" + styleReset,
+		}},
+		{`for f in *.go; do gofmt -l "$f"; done`, []string{
+			styleKeyword + "for" + styleReset,
+			styleKeyword + "do" + styleReset,
+			styleCommand + "gofmt" + styleReset,
+		}},
+		{`echo hi > out.txt # save`, []string{
+			styleOperator + ">" + styleReset,
+			styleComment + "# save" + styleReset,
+		}},
+		{`echo $(date +%s)`, []string{styleOperator + "$(" + styleReset, styleCommand + "date" + styleReset}},
+	}
+	for _, tt := range tests {
+		got := Highlight(tt.cmd)
+		if plain := ansiEscape.ReplaceAllString(got, ""); plain != tt.cmd {
+			t.Errorf("Highlight(%q) changed the text: %q", tt.cmd, plain)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Highlight(%q) = %q, missing %q", tt.cmd, got, want)
+			}
+		}
+	}
+}
+
+func TestHighlightLeavesRedirectTargetsAndArgumentsPlain(t *testing.T) {
+	got := Highlight(`cat notes.txt > out.txt`)
+	if strings.Contains(got, styleCommand+"out.txt") || strings.Contains(got, styleCommand+"notes.txt") {
+		t.Fatalf("file names highlighted as commands: %q", got)
+	}
+	if got := Highlight(`echo 'unterminated`); !strings.HasSuffix(got, styleString+"'unterminated"+styleReset) {
+		t.Fatalf("unterminated string = %q", got)
+	}
+}
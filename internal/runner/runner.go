@@ -9,6 +9,8 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/sasanktumpati/ask/internal/clipboard"
+
 	"github.com/chzyer/readline"
 	"golang.org/x/term"
 )
@@ -19,15 +21,29 @@ type RunOptions struct {
 	Stdin   io.Reader
 	Stdout  io.Writer
 	Stderr  io.Writer
+	// AllowUnsafe skips the extra confirmation for commands matching
+	// DangerPatterns.
+	AllowUnsafe bool
+	// Edit opens the command in $EDITOR instead of the inline prompt.
+	Edit bool
+	// Shell overrides the shell picked by ResolveShell.
+	Shell string
+	// Highlight prints a syntax-highlighted preview of the command above the
+	// prompt when Stdout is a color terminal.
+	Highlight bool
 }
 
 // PromptAndRun presents an editable shell prompt prefilled with Command.
 // Enter executes the command, Ctrl+C copies it to clipboard and exits,
-// and Ctrl+D exits without execution.
-func PromptAndRun(opts RunOptions) error {
+// and Ctrl+D exits without execution. With Edit set, the command is opened in
+// the user's editor instead and whatever is saved runs; an empty file cancels.
+// Dangerous commands show a warning and need an extra "y" confirmation unless
+// AllowUnsafe is set. The returned status is the executed command's exit code,
+// or 0 when nothing ran.
+func PromptAndRun(opts RunOptions) (int, error) {
 	cmd := strings.TrimSpace(opts.Command)
 	if cmd == "" {
-		return nil
+		return 0, nil
 	}
 	if opts.Stdout == nil {
 		opts.Stdout = os.Stdout
@@ -37,7 +53,110 @@ func PromptAndRun(opts RunOptions) error {
 	}
 
 	fmt.Fprintln(opts.Stdout)
+	warned := false
+	if risky, reason := classifyCommand(cmd); risky && !opts.AllowUnsafe {
+		printDangerWarning(opts.Stdout, reason)
+		warned = true
+	}
+
+	// The editor needs the terminal to itself, so in edit mode the readline
+	// prompt is only created if a confirmation is needed afterwards.
+	var rl *readline.Instance
+	var input string
+	if opts.Edit {
+		edited, err := editCommand(cmd, opts)
+		if err != nil {
+			return 0, err
+		}
+		if edited == "" {
+			fmt.Fprintln(opts.Stdout, "Command cancelled.")
+			return 0, nil
+		}
+		input = edited
+	} else {
+		if opts.Highlight && colorEnabled(opts.Stdout) {
+			fmt.Fprintln(opts.Stdout, Highlight(cmd))
+		}
+		var err error
+		if rl, err = newPrompt(opts); err != nil {
+			return 0, err
+		}
+		defer rl.Close()
+
+		input, err = rl.ReadlineWithDefault(cmd)
+		if err == readline.ErrInterrupt {
+			clearPromptLine(opts.Stdout)
+			fmt.Fprintln(opts.Stdout)
+			if copyErr := clipboard.Copy(cmd); copyErr == nil {
+				fmt.Fprintln(opts.Stdout, "Command copied to clipboard.")
+			} else {
+				fmt.Fprintln(opts.Stdout, "Command cancelled.")
+			}
+			return 0, nil
+		}
+		if err == io.EOF {
+			clearPromptLine(opts.Stdout)
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read command input: %w", err)
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			input = cmd
+		}
+	}
+
+	if risky, reason := classifyCommand(input); risky && !opts.AllowUnsafe {
+		if !warned {
+			printDangerWarning(opts.Stdout, reason)
+		}
+		if rl == nil {
+			var err error
+			if rl, err = newPrompt(opts); err != nil {
+				return 0, err
+			}
+			defer rl.Close()
+		}
+		rl.SetPrompt("Run this command anyway? [y/N] ")
+		answer, err := rl.Readline()
+		if err != nil && err != readline.ErrInterrupt && err != io.EOF {
+			return 0, fmt.Errorf("read confirmation: %w", err)
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if err != nil || (answer != "y" && answer != "yes") {
+			fmt.Fprintln(opts.Stdout, "Command cancelled.")
+			return 0, nil
+		}
+	}
+
+	name, args := shellCommand(runtime.GOOS, ResolveShell(opts.Shell), input)
+	execCmd := exec.Command(name, args...)
+	execCmd.Stdout = opts.Stdout
+	execCmd.Stderr = opts.Stderr
+	execCmd.Stdin = commandStdin(opts)
+
+	if err := execCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitStatus(exitErr), nil
+		}
+		return 0, fmt.Errorf("run command: %w", err)
+	}
+	return 0, nil
+}
+
+// exitStatus returns the child's exit code, or 1 when it was killed by a
+// signal and reports no code of its own.
+func exitStatus(err *exec.ExitError) int {
+	if code := err.ExitCode(); code > 0 {
+		return code
+	}
+	return 1
+}
 
+func newPrompt(opts RunOptions) (*readline.Instance, error) {
 	cfg := &readline.Config{
 		Prompt:          "$ ",
 		InterruptPrompt: "\n",
@@ -53,49 +172,24 @@ func PromptAndRun(opts RunOptions) error {
 
 	rl, err := readline.NewEx(cfg)
 	if err != nil {
-		return fmt.Errorf("init command prompt: %w", err)
-	}
-	defer rl.Close()
-
-	input, err := rl.ReadlineWithDefault(cmd)
-	if err == readline.ErrInterrupt {
-		clearPromptLine(opts.Stdout)
-		fmt.Fprintln(opts.Stdout)
-		if copyErr := copyToClipboard(cmd, runtime.GOOS); copyErr == nil {
-			fmt.Fprintln(opts.Stdout, "Command copied to clipboard.")
-		} else {
-			fmt.Fprintln(opts.Stdout, "Command cancelled.")
-		}
-		return nil
-	}
-	if err == io.EOF {
-		clearPromptLine(opts.Stdout)
-		return nil
-	}
-	if err != nil {
-		return fmt.Errorf("read command input: %w", err)
-	}
-
-	input = strings.TrimSpace(input)
-	if input == "" {
-		input = cmd
-	}
-
-	shell := strings.TrimSpace(os.Getenv("SHELL"))
-	if shell == "" {
-		shell = "sh"
+		return nil, fmt.Errorf("init command prompt: %w", err)
 	}
+	return rl, nil
+}
 
-	execCmd := exec.Command(shell, "-lc", input)
-	execCmd.Stdout = opts.Stdout
-	execCmd.Stderr = opts.Stderr
+func commandStdin(opts RunOptions) *os.File {
 	if stdin, ok := opts.Stdin.(*os.File); ok {
-		execCmd.Stdin = stdin
-	} else {
-		execCmd.Stdin = os.Stdin
+		return stdin
 	}
+	return os.Stdin
+}
 
-	return execCmd.Run()
+func printDangerWarning(w io.Writer, reason string) {
+	msg := fmt.Sprintf("warning: this command looks dangerous: %s", reason)
+	if isTerminalWriter(w) {
+		msg = "\033[1m" + msg + "\033[0m"
+	}
+	fmt.Fprintln(w, msg)
 }
 
 func clearPromptLine(w io.Writer) {
@@ -105,6 +199,12 @@ func clearPromptLine(w io.Writer) {
 	fmt.Fprint(w, "\r\033[2K\r")
 }
 
+// colorEnabled reports whether ANSI colors may be written to w: it must be a
+// terminal and NO_COLOR must be unset.
+func colorEnabled(w io.Writer) bool {
+	return os.Getenv("NO_COLOR") == "" && isTerminalWriter(w)
+}
+
 func isTerminalWriter(w io.Writer) bool {
 	fdw, ok := w.(interface{ Fd() uintptr })
 	if !ok {
@@ -112,39 +212,3 @@ func isTerminalWriter(w io.Writer) bool {
 	}
 	return term.IsTerminal(int(fdw.Fd()))
 }
-
-type clipboardCmd struct {
-	name string
-	args []string
-}
-
-func copyToClipboard(text string, goos string) error {
-	text = strings.TrimSpace(text)
-	if text == "" {
-		return errors.New("clipboard text is empty")
-	}
-
-	for _, c := range clipboardCommands(goos) {
-		cmd := exec.Command(c.name, c.args...)
-		cmd.Stdin = strings.NewReader(text)
-		if err := cmd.Run(); err == nil {
-			return nil
-		}
-	}
-	return errors.New("no working clipboard command found")
-}
-
-func clipboardCommands(goos string) []clipboardCmd {
-	switch goos {
-	case "darwin":
-		return []clipboardCmd{{name: "pbcopy"}}
-	case "windows":
-		return []clipboardCmd{{name: "cmd", args: []string{"/c", "clip"}}}
-	default:
-		return []clipboardCmd{
-			{name: "wl-copy"},
-			{name: "xclip", args: []string{"-selection", "clipboard"}},
-			{name: "xsel", args: []string{"--clipboard", "--input"}},
-		}
-	}
-}
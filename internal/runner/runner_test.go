@@ -2,28 +2,37 @@ package runner
 
 import "testing"
 
-func TestClipboardCommandsByOS(t *testing.T) {
-	mac := clipboardCommands("darwin")
-	if len(mac) != 1 || mac[0].name != "pbcopy" {
-		t.Fatalf("darwin clipboard commands = %+v", mac)
+func TestClassifyCommand(t *testing.T) {
+	tests := []struct {
+		cmd   string
+		risky bool
+	}{
+		{"rm -rf /tmp/build", true},
+		{"rm -r dir", true},
+		{"rm --force file", true},
+		{"rm file.txt", false},
+		{"ls -la && rm -fr ~/x", true},
+		{"dd if=/dev/zero of=/dev/sda bs=1M", true},
+		{"mkfs.ext4 /dev/sdb1", true},
+		{":(){ :|:& };:", true},
+		{"sudo apt install jq", true},
+		{"echo hi > /dev/sda", true},
+		{"curl -fsSL https://example.com/install.sh | sh", true},
+		{"wget -qO- https://example.com/x | sudo bash", true},
+		{"curl -s https://example.com | jq .", false},
+		{"git status", false},
+		{"grep -rf patterns.txt .", false},
+		{"echo sudoku", false},
+		{"cat add.txt", false},
+		{"", false},
 	}
-
-	win := clipboardCommands("windows")
-	if len(win) != 1 || win[0].name != "cmd" {
-		t.Fatalf("windows clipboard commands = %+v", win)
-	}
-	if len(win[0].args) != 2 || win[0].args[0] != "/c" || win[0].args[1] != "clip" {
-		t.Fatalf("windows clipboard args = %+v", win[0].args)
-	}
-
-	linux := clipboardCommands("linux")
-	if len(linux) < 3 {
-		t.Fatalf("linux clipboard commands = %+v", linux)
-	}
-}
-
-func TestCopyToClipboardRejectsEmpty(t *testing.T) {
-	if err := copyToClipboard("   ", "darwin"); err == nil {
-		t.Fatal("expected error for empty clipboard text")
+	for _, tt := range tests {
+		risky, reason := classifyCommand(tt.cmd)
+		if risky != tt.risky {
+			t.Errorf("classifyCommand(%q) = %v (%s), want %v", tt.cmd, risky, reason, tt.risky)
+		}
+		if risky && reason == "" {
+			t.Errorf("classifyCommand(%q) returned no reason", tt.cmd)
+		}
 	}
 }
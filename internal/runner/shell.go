@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ResolveShell returns the shell commands run in: preferred when set,
+// otherwise $SHELL (sh when unset), or %COMSPEC% (cmd.exe when unset) on
+// Windows.
+func ResolveShell(preferred string) string {
+	return resolveShell(runtime.GOOS, preferred, os.Getenv)
+}
+
+func resolveShell(goos, preferred string, getenv func(string) string) string {
+	if shell := strings.TrimSpace(preferred); shell != "" {
+		return shell
+	}
+	if goos == "windows" {
+		if shell := strings.TrimSpace(getenv("COMSPEC")); shell != "" {
+			return shell
+		}
+		return "cmd.exe"
+	}
+	if shell := strings.TrimSpace(getenv("SHELL")); shell != "" {
+		return shell
+	}
+	return "sh"
+}
+
+// shellCommand returns the program and arguments that run input in shell.
+// On Windows cmd.exe gets /C and PowerShell gets -Command; everything else,
+// including a POSIX shell on Windows such as Git Bash, gets -lc.
+func shellCommand(goos, shell, input string) (name string, args []string) {
+	if goos == "windows" {
+		base := strings.ToLower(filepath.Base(strings.ReplaceAll(shell, `\`, "/")))
+		switch strings.TrimSuffix(base, ".exe") {
+		case "cmd":
+			return shell, []string{"/C", input}
+		case "powershell", "pwsh":
+			return shell, []string{"-NoProfile", "-Command", input}
+		}
+	}
+	return shell, []string{"-lc", input}
+}
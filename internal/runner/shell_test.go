@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		goos, shell string
+		wantArgs    []string
+	}{
+		{"linux", "/bin/bash", []string{"-lc", "ls"}},
+		{"darwin", "zsh", []string{"-lc", "ls"}},
+		{"windows", `C:\Windows\system32\cmd.exe`, []string{"/C", "ls"}},
+		{"windows", "powershell.exe", []string{"-NoProfile", "-Command", "ls"}},
+		{"windows", "pwsh", []string{"-NoProfile", "-Command", "ls"}},
+		{"windows", `C:\Program Files\Git\bin\bash.exe`, []string{"-lc", "ls"}},
+	}
+	for _, tt := range tests {
+		name, args := shellCommand(tt.goos, tt.shell, "ls")
+		if name != tt.shell || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("shellCommand(%q, %q) = %q %q, want %q", tt.goos, tt.shell, name, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestResolveShell(t *testing.T) {
+	env := map[string]string{"SHELL": "/bin/zsh", "COMSPEC": `C:\Windows\system32\cmd.exe`}
+	getenv := func(k string) string { return env[k] }
+	if got := resolveShell("linux", "", getenv); got != "/bin/zsh" {
+		t.Fatalf("linux shell = %q", got)
+	}
+	if got := resolveShell("windows", "", getenv); got != env["COMSPEC"] {
+		t.Fatalf("windows shell = %q", got)
+	}
+	if got := resolveShell("windows", "pwsh", getenv); got != "pwsh" {
+		t.Fatalf("preferred shell = %q", got)
+	}
+	none := func(string) string { return "" }
+	if resolveShell("linux", "", none) != "sh" || resolveShell("windows", "", none) != "cmd.exe" {
+		t.Fatal("expected sh and cmd.exe defaults")
+	}
+}
@@ -0,0 +1,42 @@
+//go:build darwin || linux
+
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runCommand runs name with args, feeding stdin, and returns trimmed stdout.
+// A missing binary is reported as ErrUnsupported.
+func runCommand(stdin string, name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s not found", ErrUnsupported, name)
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", &commandError{name: name, msg: msg, err: err}
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+type commandError struct {
+	name string
+	msg  string
+	err  error
+}
+
+func (e *commandError) Error() string { return fmt.Sprintf("%s: %s", e.name, e.msg) }
+
+func (e *commandError) Unwrap() error { return e.err }
@@ -0,0 +1,4 @@
+// Package secrets stores provider API keys in the operating system keychain:
+// macOS Keychain via security, the Linux Secret Service via secret-tool, and
+// Windows Credential Manager.
+package secrets
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// security exits with status 44 when the item does not exist.
+const securityItemNotFound = 44
+
+type macKeychain struct{}
+
+func platformBackend() Backend { return macKeychain{} }
+
+// Store passes the secret on stdin rather than as the -w value, which any
+// local user could read from the process table. With -w last and no value,
+// security prompts for the password and then asks to retype it, so the
+// secret is written twice.
+func (macKeychain) Store(account, secret string) error {
+	stdin := secret + "\n" + secret + "\n"
+	_, err := runCommand(stdin, "security", "add-generic-password", "-U", "-s", Service, "-a", account, "-w")
+	return err
+}
+
+func (macKeychain) Fetch(account string) (string, error) {
+	out, err := runCommand("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	if isNotFound(err) {
+		return "", ErrNotFound
+	}
+	return out, err
+}
+
+func (macKeychain) Delete(account string) error {
+	_, err := runCommand("", "security", "delete-generic-password", "-s", Service, "-a", account)
+	if isNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == securityItemNotFound
+}
@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"errors"
+	"os/exec"
+)
+
+type secretService struct{}
+
+func platformBackend() Backend { return secretService{} }
+
+func (secretService) Store(account, secret string) error {
+	_, err := runCommand(secret, "secret-tool", "store", "--label", Service+" "+account, "service", Service, "account", account)
+	return err
+}
+
+func (secretService) Fetch(account string) (string, error) {
+	out, err := runCommand("", "secret-tool", "lookup", "service", Service, "account", account)
+	if isNotFound(err) || (err == nil && out == "") {
+		return "", ErrNotFound
+	}
+	return out, err
+}
+
+func (secretService) Delete(account string) error {
+	_, err := runCommand("", "secret-tool", "clear", "service", Service, "account", account)
+	if isNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// secret-tool exits with status 1 and no output when nothing matches.
+func isNotFound(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 1
+}
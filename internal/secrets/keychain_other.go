@@ -0,0 +1,13 @@
+//go:build !darwin && !linux && !windows
+
+package secrets
+
+type unsupported struct{}
+
+func platformBackend() Backend { return unsupported{} }
+
+func (unsupported) Store(string, string) error { return ErrUnsupported }
+
+func (unsupported) Fetch(string) (string, error) { return "", ErrUnsupported }
+
+func (unsupported) Delete(string) error { return ErrUnsupported }
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Service is the keychain service name under which ask stores secrets.
+const Service = "ask"
+
+const refPrefix = "keychain:"
+
+var (
+	// ErrNotFound indicates no secret is stored for the account.
+	ErrNotFound = errors.New("secret not found in keychain")
+	// ErrUnsupported indicates no keychain backend exists for this platform.
+	ErrUnsupported = errors.New("keychain is not supported on this platform")
+)
+
+// Backend stores, fetches, and deletes secrets keyed by account name.
+type Backend interface {
+	Store(account, secret string) error
+	Fetch(account string) (string, error)
+	Delete(account string) error
+}
+
+// Default returns the keychain backend for the current platform.
+func Default() Backend {
+	return platformBackend()
+}
+
+// Ref returns the config reference string for a keychain account.
+func Ref(account string) string {
+	return refPrefix + strings.ToLower(strings.TrimSpace(account))
+}
+
+// ParseRef extracts the account from a reference created by Ref.
+func ParseRef(ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if !strings.HasPrefix(ref, refPrefix) {
+		return "", false
+	}
+	account := strings.TrimSpace(strings.TrimPrefix(ref, refPrefix))
+	return account, account != ""
+}
+
+// Memory is an in-process Backend, useful for tests.
+type Memory struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMemory returns an empty in-memory backend.
+func NewMemory() *Memory {
+	return &Memory{secrets: map[string]string{}}
+}
+
+// Store saves secret for account.
+func (m *Memory) Store(account, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[account] = secret
+	return nil
+}
+
+// Fetch returns the secret for account or ErrNotFound.
+func (m *Memory) Fetch(account string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	secret, ok := m.secrets[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Delete removes the secret for account or returns ErrNotFound.
+func (m *Memory) Delete(account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.secrets[account]; !ok {
+		return ErrNotFound
+	}
+	delete(m.secrets, account)
+	return nil
+}
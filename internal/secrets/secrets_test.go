@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRefRoundTrip(t *testing.T) {
+	ref := Ref(" OpenAI ")
+	if ref != "keychain:openai" {
+		t.Fatalf("Ref = %q", ref)
+	}
+	account, ok := ParseRef(ref)
+	if !ok || account != "openai" {
+		t.Fatalf("ParseRef(%q) = %q, %v", ref, account, ok)
+	}
+	for _, bad := range []string{"", "openai", "keychain:", "env:OPENAI_API_KEY"} {
+		if _, ok := ParseRef(bad); ok {
+			t.Fatalf("ParseRef(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMemoryBackend(t *testing.T) {
+	var b Backend = NewMemory()
+	if _, err := b.Fetch("openai"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Fetch missing error = %v", err)
+	}
+	if err := b.Store("openai", "sk-test"); err != nil {
+		t.Fatalf("Store error = %v", err)
+	}
+	got, err := b.Fetch("openai")
+	if err != nil || got != "sk-test" {
+		t.Fatalf("Fetch = %q, %v", got, err)
+	}
+	if err := b.Delete("openai"); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+	if err := b.Delete("openai"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete error = %v", err)
+	}
+}